@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"os"
+	"strconv"
 	"time"
 
 	"mymodule/config"
 	"mymodule/pkg/auth"
+	"mymodule/pkg/cursor"
 	loger "mymodule/pkg/logger"
+	"mymodule/pkg/notifier"
+	"mymodule/pkg/storage"
 	"mymodule/pkg/validator"
 
 	// User module
@@ -15,6 +20,41 @@ import (
 	userRepo "mymodule/internal/user/repository"
 	userUsecase "mymodule/internal/user/usecase"
 
+	// Label module
+	labelHandler "mymodule/internal/label/handler"
+	labelRepo "mymodule/internal/label/repository"
+	labelUsecase "mymodule/internal/label/usecase"
+
+	// Project module
+	projectHandler "mymodule/internal/project/handler"
+	projectRepo "mymodule/internal/project/repository"
+	projectUsecase "mymodule/internal/project/usecase"
+
+	// Attachment module
+	attachmentHandler "mymodule/internal/attachment/handler"
+	attachmentRepo "mymodule/internal/attachment/repository"
+	attachmentUsecase "mymodule/internal/attachment/usecase"
+
+	// Comment module
+	commentHandler "mymodule/internal/comment/handler"
+	commentRepo "mymodule/internal/comment/repository"
+	commentUsecase "mymodule/internal/comment/usecase"
+
+	// Time entry module
+	timeEntryHandler "mymodule/internal/timeentry/handler"
+	timeEntryRepo "mymodule/internal/timeentry/repository"
+	timeEntryUsecase "mymodule/internal/timeentry/usecase"
+
+	// Reminder module
+	reminderHandler "mymodule/internal/reminder/handler"
+	reminderRepo "mymodule/internal/reminder/repository"
+	reminderUsecase "mymodule/internal/reminder/usecase"
+
+	// Workspace module
+	workspaceHandler "mymodule/internal/workspace/handler"
+	workspaceRepo "mymodule/internal/workspace/repository"
+	workspaceUsecase "mymodule/internal/workspace/usecase"
+
 	// Task module
 	taskHandler "mymodule/internal/task/handler"
 	// taskModel "mymodule/internal/task/model"
@@ -37,11 +77,19 @@ func main() {
 		loger.Log.Fatal("Error loading .env file")
 	}
 
-	app := fiber.New()
+	attachmentLimits := attachmentUsecase.Limits{
+		MaxFileSize: envInt64("ATTACHMENT_MAX_FILE_SIZE", attachmentUsecase.DefaultMaxFileSize),
+		UserQuota:   envInt64("ATTACHMENT_USER_QUOTA", attachmentUsecase.DefaultUserQuota),
+	}
+
+	app := fiber.New(fiber.Config{
+		// Leave room for the multipart framing around an attachment
+		BodyLimit: int(attachmentLimits.MaxFileSize) + 1<<20,
+	})
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:5500, http://127.0.0.1:5500",
 		AllowCredentials: true,
-		AllowHeaders: "Content-Type, Authorization",
+		AllowHeaders: "Content-Type, Authorization, X-Workspace-ID",
 	}))
 
 	// Postgres
@@ -61,6 +109,11 @@ func main() {
 	jwtKey := os.Getenv("JWT_SECRET")
 	// === Initialize Core Services ===
 	jwtManager := auth.NewJwtManager(jwtKey, time.Hour*2)
+	cursorKey := os.Getenv("CURSOR_SECRET")
+	if cursorKey == "" {
+		cursorKey = jwtKey
+	}
+	cursorSigner := cursor.NewHmacSigner(cursorKey)
 	cyptoService := &userUsecase.DefaultCryptoService{}
 	validator := validator.InitValidator()
 	
@@ -70,10 +123,91 @@ func main() {
 	useUsecase := userUsecase.NewUserUsecase(userRepo, cyptoService, jwtManager)
 	userHandler.NewUserHandler(app, useUsecase, jwtManager, validator)
 	
+	// === Setup Label Module ===
+	labelRepo := labelRepo.NewGormLabelRepository(db)
+	labelUsecase := labelUsecase.NewLabelUsecase(labelRepo)
+	labelHandler.NewLabelHandler(app, labelUsecase, jwtManager, validator)
+
+	// === Setup Project Module ===
+	projectRepo := projectRepo.NewGormProjectRepository(db)
+	projectUsecase := projectUsecase.NewProjectUsecase(projectRepo)
+	projectHandler.NewProjectHandler(app, projectUsecase, jwtManager, validator)
+
+	// === Setup Workspace Module ===
+	workspaceRepo := workspaceRepo.NewGormWorkspaceRepository(db)
+	workspaceUsecase := workspaceUsecase.NewWorkspaceUsecase(workspaceRepo, jwtManager)
+	workspaceHandler.NewWorkspaceHandler(app, workspaceUsecase, jwtManager, validator)
+
 	// === Setup Task Module ===
 	taskRepo := taskRepo.NewGormTaskRepository(db)
+	trashRetention := time.Duration(envInt64("TRASH_RETENTION_DAYS", taskUsecase.DefaultTrashRetentionDays)) * 24 * time.Hour
+	sweepInterval, err := time.ParseDuration(os.Getenv("TRASH_SWEEP_INTERVAL"))
+	if err != nil || sweepInterval <= 0 {
+		sweepInterval = time.Hour
+	}
+	sweeper := taskUsecase.NewSweeper(taskRepo, trashRetention, sweepInterval)
+	go sweeper.Start(context.Background())
+	rebalanceInterval, err := time.ParseDuration(os.Getenv("RANK_REBALANCE_INTERVAL"))
+	if err != nil || rebalanceInterval <= 0 {
+		rebalanceInterval = time.Hour
+	}
+	rebalancer := taskUsecase.NewRebalancer(taskRepo, rebalanceInterval)
+	go rebalancer.Start(context.Background())
+
 	taskUsecase := taskUsecase.NewTaskUsecase(taskRepo)
-	taskHandler.NewTaskHandler(app,taskUsecase,jwtManager,validator,)
+	taskHandler.NewTaskHandler(app, taskUsecase, jwtManager, validator, cursorSigner, workspaceHandler.ActiveWorkspace(workspaceUsecase), userHandler.CurrentRole(useUsecase))
+
+	// === Setup Reminder Module ===
+	reminderRepo := reminderRepo.NewGormReminderRepository(db)
+	reminderInterval, err := time.ParseDuration(os.Getenv("REMINDER_INTERVAL"))
+	if err != nil || reminderInterval <= 0 {
+		reminderInterval = 30 * time.Second
+	}
+	scheduler := reminderUsecase.NewScheduler(reminderRepo, notifier.NewLogNotifier(), reminderInterval)
+	go scheduler.Start(context.Background())
+
+	reminderUsecase := reminderUsecase.NewReminderUsecase(reminderRepo)
+	reminderHandler.NewReminderHandler(app, reminderUsecase, jwtManager, validator)
+
+	// === Setup Comment Module ===
+	commentRepo := commentRepo.NewGormCommentRepository(db)
+	commentUsecase := commentUsecase.NewCommentUsecase(commentRepo)
+	commentHandler.NewCommentHandler(app, commentUsecase, jwtManager, validator)
+
+	// === Setup Time Entry Module ===
+	timeEntryRepo := timeEntryRepo.NewGormTimeEntryRepository(db)
+	timeEntryUsecase := timeEntryUsecase.NewTimeEntryUsecase(timeEntryRepo)
+	timeEntryHandler.NewTimeEntryHandler(app, timeEntryUsecase, jwtManager, validator)
+
+	// === Setup Attachment Module ===
+	attachmentDir := os.Getenv("ATTACHMENT_DIR")
+	if attachmentDir == "" {
+		attachmentDir = "uploads"
+	}
+	attachmentStore, err := storage.NewLocalStorage(attachmentDir)
+	if err != nil {
+		loger.Log.Fatal("Failed to prepare attachment storage: ", err)
+	}
+	attachmentRepo := attachmentRepo.NewGormAttachmentRepository(db)
+	collectInterval, err := time.ParseDuration(os.Getenv("ATTACHMENT_GC_INTERVAL"))
+	if err != nil || collectInterval <= 0 {
+		collectInterval = time.Hour
+	}
+	collector := attachmentUsecase.NewCollector(attachmentRepo, attachmentStore, collectInterval)
+	go collector.Start(context.Background())
+
+	attachmentUsecase := attachmentUsecase.NewAttachmentUsecase(attachmentRepo, attachmentStore, attachmentLimits)
+	attachmentHandler.NewAttachmentHandler(app, attachmentUsecase, jwtManager)
+
 	app.Listen(":8080")
 
 }
+
+// envInt64 reads a positive integer from the environment, falling back to def
+func envInt64(key string, def int64) int64 {
+	v, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
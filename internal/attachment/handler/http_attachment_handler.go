@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"mymodule/internal/attachment/model"
+	"mymodule/internal/attachment/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpAttachmenthandler struct {
+	usecase usecase.AttachmentUsecase
+	token   auth.TokenService
+}
+
+func NewAttachmentHandler(app *fiber.App, usecase usecase.AttachmentUsecase, token auth.TokenService) {
+	handler := &HttpAttachmenthandler{
+		usecase: usecase,
+		token:   token,
+	}
+	attachment := app.Group("/task/:id/attachments", middleware.Middleware(token))
+	attachment.Post("/", handler.Upload)
+	attachment.Get("/", handler.GetAttachmentsByTask)
+	attachment.Get("/:attachmentId", handler.Download)
+	attachment.Delete("/:attachmentId", handler.DeleteAttachment)
+}
+
+// Upload expects a multipart form with the file in the "file" field
+func (h *HttpAttachmenthandler) Upload(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		logger.Log.Error("Invalid attachment request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Log.Error("Failed to open uploaded file: ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	defer file.Close()
+
+	attachment, err := h.usecase.Upload(uint(taskID), userID, header.Filename, header.Size, file)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.ToAttachmentResponse(*attachment))
+}
+
+func (h *HttpAttachmenthandler) GetAttachmentsByTask(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	attachments, err := h.usecase.GetByTask(uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToAttachmentResponseList(*attachments))
+}
+
+// Download always serves the file as a download with the sniffed content type,
+// browsers are told not to second-guess it
+func (h *HttpAttachmenthandler) Download(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	attachmentID, err := strconv.Atoi(c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid attachment ID"})
+	}
+
+	attachment, content, err := h.usecase.Open(uint(attachmentID), uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(content, int(attachment.Size))
+}
+
+func (h *HttpAttachmenthandler) DeleteAttachment(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	attachmentID, err := strconv.Atoi(c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid attachment ID"})
+	}
+
+	if err := h.usecase.DeleteAttachment(uint(attachmentID), uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "attachment deleted"})
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound), errors.Is(err, usecase.ErrAttachmentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrEmptyFile):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrFileTooLarge), errors.Is(err, usecase.ErrQuotaExceeded):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, usecase.ErrNotUploader), errors.Is(err, usecase.ErrReadOnly):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
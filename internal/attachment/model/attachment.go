@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Attachment is the DB model for a file attached to a task. The content lives
+// in blob storage under SHA256, shared by every attachment with the same bytes.
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id" example:"1"`
+	TaskID      uint      `gorm:"not null;index" json:"task_id" example:"1"`
+	UserID      uint      `gorm:"not null;index" json:"user_id" example:"1"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name" example:"report.pdf"`
+	ContentType string    `gorm:"size:255;not null" json:"content_type" example:"application/pdf"`
+	Size        int64     `gorm:"not null" json:"size" example:"48213"`
+	SHA256      string    `gorm:"column:sha256;size:64;not null;index" json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Blob tracks one stored file. UpdatedAt is touched whenever an upload lands on
+// the blob, the garbage collector leaves recently touched blobs alone.
+type Blob struct {
+	SHA256    string `gorm:"column:sha256;primaryKey;size:64"`
+	Size      int64  `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time `gorm:"index"`
+}
+
+// AttachmentResponse is the response model for an attachment
+type AttachmentResponse struct {
+	ID          uint      `json:"id" example:"1"`
+	TaskID      uint      `json:"task_id" example:"1"`
+	FileName    string    `json:"file_name" example:"report.pdf"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size" example:"48213"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+func ToAttachmentResponse(a Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		TaskID:      a.TaskID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		SHA256:      a.SHA256,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+func ToAttachmentResponseList(attachments []Attachment) []AttachmentResponse {
+	res := make([]AttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		res = append(res, ToAttachmentResponse(a))
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"mymodule/internal/attachment/model"
+	"mymodule/internal/attachment/usecase"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormAttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAttachmentRepository(db *gorm.DB) usecase.AttachmentRepository {
+	return &GormAttachmentRepository{db: db}
+}
+
+// Create saves the attachment and records or touches its blob
+func (r *GormAttachmentRepository) Create(attachment *model.Attachment) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchBlob(tx, model.Blob{SHA256: attachment.SHA256, Size: attachment.Size}); err != nil {
+			return err
+		}
+		return tx.Create(attachment).Error
+	})
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(attachment.TaskID, attachment.UserID)).Error("Failed to save attachment")
+		return err
+	}
+	logger.Log.WithField("attachmentID", attachment.ID).Info("Attachment saved successfully")
+	return nil
+}
+
+func (r *GormAttachmentRepository) FindByTask(taskID uint) (*[]model.Attachment, error) {
+	var attachments []model.Attachment
+	if err := r.db.Where("task_id = ?", taskID).Order("created_at, id").Find(&attachments).Error; err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to find attachments by task")
+		return nil, err
+	}
+	return &attachments, nil
+}
+
+func (r *GormAttachmentRepository) FindByIDAndTask(attachmentID, taskID uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	if err := r.db.Where("id = ? AND task_id = ?", attachmentID, taskID).First(&attachment).Error; err != nil {
+		logger.Log.WithField("attachmentID", attachmentID).Error("Failed to find attachment")
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *GormAttachmentRepository) Delete(attachmentID uint) error {
+	if err := r.db.Delete(&model.Attachment{}, attachmentID).Error; err != nil {
+		logger.Log.WithField("attachmentID", attachmentID).Error("Failed to delete attachment")
+		return err
+	}
+	return nil
+}
+
+// FindTask returns the task with taskID if userID can see it
+func (r *GormAttachmentRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	var task taskModel.Task
+	if err := r.db.Scopes(taskModel.VisibleTo(userID)).Where("id = ?", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task for attachment")
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindRole returns the role of userID in a workspace, empty when not a member
+func (r *GormAttachmentRepository) FindRole(workspaceID, userID uint) (string, error) {
+	var member workspaceModel.Member
+	result := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Limit(1).Find(&member)
+	if result.Error != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace role")
+		return "", result.Error
+	}
+	return member.Role, nil
+}
+
+// UsageByUser sums the size of the user's attachments on tasks that still exist,
+// a file uploaded twice counts twice
+func (r *GormAttachmentRepository) UsageByUser(userID uint) (int64, error) {
+	var used int64
+	err := r.db.Model(&model.Attachment{}).
+		Joins("JOIN tasks ON tasks.id = attachments.task_id AND tasks.deleted_at IS NULL").
+		Where("attachments.user_id = ?", userID).
+		Select("COALESCE(SUM(attachments.size), 0)").
+		Scan(&used).Error
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to sum attachment usage")
+		return 0, err
+	}
+	return used, nil
+}
+
+// DeleteDetached removes attachments whose task was purged. Tasks in the trash
+// keep theirs, they may still be restored.
+func (r *GormAttachmentRepository) DeleteDetached() (int64, error) {
+	live := r.db.Unscoped().Model(&taskModel.Task{}).Select("id")
+	res := r.db.Where("task_id NOT IN (?)", live).Delete(&model.Attachment{})
+	if res.Error != nil {
+		logger.Log.Error("Failed to delete detached attachments: ", res.Error)
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+// FindOrphanBlobs lists blobs no attachment points to that were last touched before before
+func (r *GormAttachmentRepository) FindOrphanBlobs(before time.Time, limit int) (*[]model.Blob, error) {
+	var blobs []model.Blob
+	if err := r.orphans(r.db, before).Order("updated_at").Limit(limit).Find(&blobs).Error; err != nil {
+		logger.Log.Error("Failed to find orphaned blobs: ", err)
+		return nil, err
+	}
+	return &blobs, nil
+}
+
+// ClaimBlob records or touches a blob before its content is kept, so the
+// collector leaves it alone for the grace period
+func (r *GormAttachmentRepository) ClaimBlob(blob model.Blob) error {
+	if err := touchBlob(r.db, blob); err != nil {
+		logger.Log.WithField("sha256", blob.SHA256).Error("Failed to claim blob")
+		return err
+	}
+	return nil
+}
+
+// DeleteBlob removes the blob row if it is still an orphan and then its content
+// with remove, holding the row until both are gone, and reports whether it did.
+// An error from remove keeps the row.
+func (r *GormAttachmentRepository) DeleteBlob(sha256 string, before time.Time, remove func() error) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := r.orphans(tx, before).Where("sha256 = ?", sha256).Delete(&model.Blob{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		deleted = true
+		return remove()
+	})
+	if err != nil {
+		logger.Log.WithField("sha256", sha256).Error("Failed to delete blob")
+		return false, err
+	}
+	return deleted, nil
+}
+
+// touchBlob inserts blob or moves its updated_at to now
+func touchBlob(db *gorm.DB, blob model.Blob) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sha256"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+	}).Create(&blob).Error
+}
+
+func (r *GormAttachmentRepository) orphans(db *gorm.DB, before time.Time) *gorm.DB {
+	return db.Where("updated_at < ?", before.UTC()).
+		Where("NOT EXISTS (SELECT 1 FROM attachments WHERE attachments.sha256 = blobs.sha256)")
+}
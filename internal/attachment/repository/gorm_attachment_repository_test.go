@@ -0,0 +1,123 @@
+package repository_test
+
+import (
+	"errors"
+	"log"
+	"mymodule/internal/attachment/model"
+	"mymodule/internal/attachment/repository"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&taskModel.Task{}, &model.Blob{}, &model.Attachment{}); err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestUsageAndGarbageCollection(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormAttachmentRepository(db)
+	shaA, shaB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	kept := taskModel.Task{Title: "Kept", UserID: 1}
+	doomed := taskModel.Task{Title: "Doomed", UserID: 1}
+	db.Create(&kept)
+	db.Create(&doomed)
+
+	for _, a := range []model.Attachment{
+		{TaskID: kept.ID, UserID: 1, FileName: "a.txt", ContentType: "text/plain", Size: 10, SHA256: shaA},
+		{TaskID: doomed.ID, UserID: 1, FileName: "a-copy.txt", ContentType: "text/plain", Size: 10, SHA256: shaA},
+		{TaskID: doomed.ID, UserID: 1, FileName: "b.txt", ContentType: "text/plain", Size: 5, SHA256: shaB},
+	} {
+		if err := repo.Create(&a); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	var blobs int64
+	db.Model(&model.Blob{}).Count(&blobs)
+	if blobs != 2 {
+		t.Fatalf("expected identical content to share a blob, got %d blobs", blobs)
+	}
+
+	used, err := repo.UsageByUser(1)
+	if err != nil || used != 25 {
+		t.Errorf("expected usage 25, got: %d, %v", used, err)
+	}
+
+	// Soft delete, as GormTaskRepository.Delete does
+	db.Delete(&doomed)
+	used, _ = repo.UsageByUser(1)
+	if used != 10 {
+		t.Errorf("expected deleted task's files not to count, got: %d", used)
+	}
+
+	// A trashed task may come back, its files stay
+	removed, err := repo.DeleteDetached()
+	if err != nil || removed != 0 {
+		t.Fatalf("expected the trashed task to keep its attachments, got: %d, %v", removed, err)
+	}
+
+	// Purged, as GormTaskRepository.Purge does
+	db.Unscoped().Delete(&doomed)
+	removed, err = repo.DeleteDetached()
+	if err != nil || removed != 2 {
+		t.Fatalf("expected two detached attachments removed, got: %d, %v", removed, err)
+	}
+
+	// Within the grace period nothing is an orphan yet
+	orphans, err := repo.FindOrphanBlobs(time.Now().Add(-time.Hour), 10)
+	if err != nil || len(*orphans) != 0 {
+		t.Errorf("expected no orphans inside the grace period, got: %v, %v", orphans, err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	orphans, err = repo.FindOrphanBlobs(later, 10)
+	if err != nil || len(*orphans) != 1 || (*orphans)[0].SHA256 != shaB {
+		t.Fatalf("expected only blob b to be orphaned, got: %v, %v", orphans, err)
+	}
+
+	removals := 0
+	remove := func() error {
+		removals++
+		return nil
+	}
+	if ok, err := repo.DeleteBlob(shaA, later, remove); err != nil || ok || removals != 0 {
+		t.Errorf("expected a referenced blob to be kept, got: %v, %v", ok, err)
+	}
+	if ok, err := repo.DeleteBlob(shaB, later, func() error { return errors.New("disk gone") }); err == nil || ok {
+		t.Errorf("expected a failed removal to fail the delete, got: %v, %v", ok, err)
+	}
+	if orphans, _ := repo.FindOrphanBlobs(later, 10); len(*orphans) != 1 {
+		t.Errorf("expected the blob row kept when its content could not be removed, got: %v", orphans)
+	}
+
+	// A claim by an upload takes the blob out of the orphans
+	if err := repo.ClaimBlob(model.Blob{SHA256: shaB, Size: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if orphans, _ := repo.FindOrphanBlobs(time.Now().Add(-time.Minute), 10); len(*orphans) != 0 {
+		t.Errorf("expected a claimed blob not to be an orphan, got: %v", orphans)
+	}
+
+	if ok, err := repo.DeleteBlob(shaB, later, remove); err != nil || !ok || removals != 1 {
+		t.Errorf("expected the orphaned blob row and its content to be deleted, got: %v, %v", ok, err)
+	}
+}
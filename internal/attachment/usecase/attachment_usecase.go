@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mymodule/internal/attachment/model"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/storage"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultMaxFileSize = 10 << 20  // 10 MiB
+	DefaultUserQuota   = 100 << 20 // 100 MiB
+	// sniffLen is how much of a file http.DetectContentType looks at
+	sniffLen = 512
+)
+
+// Limits caps the size of a single upload and the total a user may store
+type Limits struct {
+	MaxFileSize int64
+	UserQuota   int64
+}
+
+type AttachmentRepository interface {
+	Create(attachment *model.Attachment) error
+	FindByTask(taskID uint) (*[]model.Attachment, error)
+	FindByIDAndTask(attachmentID, taskID uint) (*model.Attachment, error)
+	Delete(attachmentID uint) error
+	FindTask(taskID, userID uint) (*taskModel.Task, error)
+	FindRole(workspaceID, userID uint) (string, error)
+	UsageByUser(userID uint) (int64, error)
+	DeleteDetached() (int64, error)
+	FindOrphanBlobs(before time.Time, limit int) (*[]model.Blob, error)
+	ClaimBlob(blob model.Blob) error
+	DeleteBlob(sha256 string, before time.Time, remove func() error) (bool, error)
+}
+
+type AttachmentUsecase interface {
+	Upload(taskID, userID uint, fileName string, size int64, content io.Reader) (*model.Attachment, error)
+	GetByTask(taskID, userID uint) (*[]model.Attachment, error)
+	Open(attachmentID, taskID, userID uint) (*model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(attachmentID, taskID, userID uint) error
+}
+
+type AttachmentusecaseImpl struct {
+	repo   AttachmentRepository
+	store  storage.Storage
+	limits Limits
+}
+
+func NewAttachmentUsecase(repo AttachmentRepository, store storage.Storage, limits Limits) AttachmentUsecase {
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = DefaultMaxFileSize
+	}
+	if limits.UserQuota <= 0 {
+		limits.UserQuota = DefaultUserQuota
+	}
+	return &AttachmentusecaseImpl{
+		repo:   repo,
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Upload stores content as an attachment of the task. size is what the client
+// announced, it is checked up front and enforced again while the bytes stream in.
+// The content type is sniffed from the data rather than taken from the client.
+func (uc *AttachmentusecaseImpl) Upload(taskID, userID uint, fileName string, size int64, content io.Reader) (*model.Attachment, error) {
+	task, err := uc.checkTask(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkWritable(task, userID); err != nil {
+		return nil, err
+	}
+	if size > uc.limits.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	used, err := uc.repo.UsageByUser(userID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get attachment usage")
+		return nil, err
+	}
+	remaining := uc.limits.UserQuota - used
+	if size > remaining {
+		logger.Log.WithField("userID", userID).Warn("Attachment quota exceeded")
+		return nil, ErrQuotaExceeded
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrEmptyFile
+	}
+	head = head[:n]
+
+	limit, limitErr := uc.limits.MaxFileSize, ErrFileTooLarge
+	if remaining < limit {
+		limit, limitErr = remaining, ErrQuotaExceeded
+	}
+	// The claim keeps the collector off the blob, the attachment saved below keeps
+	// it for good
+	obj, err := uc.store.Put(context.Background(), &maxReader{
+		r:   io.MultiReader(bytes.NewReader(head), content),
+		n:   limit,
+		err: limitErr,
+	}, func(obj storage.Object) error {
+		return uc.repo.ClaimBlob(model.Blob{SHA256: obj.Key, Size: obj.Size})
+	})
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to store attachment: ", err)
+		return nil, err
+	}
+
+	attachment := model.Attachment{
+		TaskID:      taskID,
+		UserID:      userID,
+		FileName:    cleanFileName(fileName),
+		ContentType: http.DetectContentType(head),
+		Size:        obj.Size,
+		SHA256:      obj.Key,
+	}
+	if err := uc.repo.Create(&attachment); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to save attachment")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Attachment uploaded successfully")
+	return &attachment, nil
+}
+
+func (uc *AttachmentusecaseImpl) GetByTask(taskID, userID uint) (*[]model.Attachment, error) {
+	if _, err := uc.checkTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := uc.repo.FindByTask(taskID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get attachments")
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Open returns an attachment together with its content, the caller closes the reader
+func (uc *AttachmentusecaseImpl) Open(attachmentID, taskID, userID uint) (*model.Attachment, io.ReadCloser, error) {
+	attachment, _, err := uc.findAttachment(attachmentID, taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := uc.store.Open(context.Background(), attachment.SHA256)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logger.Log.WithField("attachmentID", attachmentID).Error("Attachment blob is missing")
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes the attachment, its blob is left to the collector as
+// other attachments may share it. Only the uploader or the task's creator may do so.
+func (uc *AttachmentusecaseImpl) DeleteAttachment(attachmentID, taskID, userID uint) error {
+	attachment, task, err := uc.findAttachment(attachmentID, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkWritable(task, userID); err != nil {
+		return err
+	}
+	if attachment.UserID != userID && task.UserID != userID {
+		logger.Log.WithField("attachmentID", attachmentID).Warn("Attachment delete by someone other than its uploader")
+		return ErrNotUploader
+	}
+
+	if err := uc.repo.Delete(attachment.ID); err != nil {
+		logger.Log.WithField("attachmentID", attachmentID).Error("Failed to delete attachment")
+		return err
+	}
+
+	logger.Log.WithField("attachmentID", attachmentID).Info("Attachment deleted")
+	return nil
+}
+
+// checkTask applies the same visibility rule as the task module's FindByIDAndUser
+func (uc *AttachmentusecaseImpl) checkTask(taskID, userID uint) (*taskModel.Task, error) {
+	task, err := uc.repo.FindTask(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for this user")
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// checkWritable refuses changes by userID on a task of a workspace where they
+// may only view, as the task module does
+func (uc *AttachmentusecaseImpl) checkWritable(task *taskModel.Task, userID uint) error {
+	if task.WorkspaceID == nil {
+		return nil
+	}
+	role, err := uc.repo.FindRole(*task.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+		logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Viewer tried to change a task")
+		return ErrReadOnly
+	}
+	return nil
+}
+
+func (uc *AttachmentusecaseImpl) findAttachment(attachmentID, taskID, userID uint) (*model.Attachment, *taskModel.Task, error) {
+	task, err := uc.checkTask(taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := uc.repo.FindByIDAndTask(attachmentID, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("attachmentID", attachmentID).Warn("Attachment not found")
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, task, nil
+}
+
+// cleanFileName keeps only the base name a client sent, never a path
+func cleanFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// maxReader reads at most n bytes from r and fails with err once r has more
+type maxReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (m *maxReader) Read(p []byte) (int, error) {
+	if m.n < 0 {
+		return 0, m.err
+	}
+	if int64(len(p)) > m.n+1 {
+		p = p[:m.n+1]
+	}
+	n, err := m.r.Read(p)
+	m.n -= int64(n)
+	if m.n < 0 {
+		return 0, m.err
+	}
+	return n, err
+}
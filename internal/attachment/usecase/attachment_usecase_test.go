@@ -0,0 +1,310 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mymodule/internal/attachment/model"
+	"mymodule/internal/attachment/usecase"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/storage"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(attachment *model.Attachment) error {
+	args := m.Called(attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) FindByTask(taskID uint) (*[]model.Attachment, error) {
+	args := m.Called(taskID)
+	return args.Get(0).(*[]model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) FindByIDAndTask(attachmentID, taskID uint) (*model.Attachment, error) {
+	args := m.Called(attachmentID, taskID)
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(attachmentID uint) error {
+	args := m.Called(attachmentID)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*taskModel.Task), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) FindRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) UsageByUser(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) DeleteDetached() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) FindOrphanBlobs(before time.Time, limit int) (*[]model.Blob, error) {
+	args := m.Called(before, limit)
+	return args.Get(0).(*[]model.Blob), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ClaimBlob(blob model.Blob) error {
+	args := m.Called(blob)
+	return args.Error(0)
+}
+
+// DeleteBlob removes the content of the blobs the test says were still orphans
+func (m *MockAttachmentRepository) DeleteBlob(sha256 string, before time.Time, remove func() error) (bool, error) {
+	args := m.Called(sha256, before)
+	if !args.Bool(0) || args.Error(1) != nil {
+		return false, args.Error(1)
+	}
+	if err := remove(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newStore(t *testing.T) *storage.LocalStorage {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	return store
+}
+
+func TestUpload(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	limits := usecase.Limits{MaxFileSize: 64, UserQuota: 100}
+
+	t.Run("SniffsTypeAndDedups", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		store := newStore(t)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, store, limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("UsageByUser", userID).Return(int64(0), nil)
+		mockRepo.On("ClaimBlob", mock.AnythingOfType("model.Blob")).Return(nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Attachment")).Return(nil)
+
+		first, err := attachmentUC.Upload(taskID, userID, "../../etc/photo.txt", int64(len(pngHeader)), bytes.NewReader(pngHeader))
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", first.ContentType)
+		assert.Equal(t, "photo.txt", first.FileName)
+		assert.Equal(t, int64(len(pngHeader)), first.Size)
+
+		second, err := attachmentUC.Upload(taskID, userID, "copy.png", int64(len(pngHeader)), bytes.NewReader(pngHeader))
+		assert.NoError(t, err)
+		assert.Equal(t, first.SHA256, second.SHA256)
+
+		content, err := store.Open(context.Background(), first.SHA256)
+		assert.NoError(t, err)
+		stored, _ := io.ReadAll(content)
+		content.Close()
+		assert.Equal(t, pngHeader, stored)
+	})
+
+	t.Run("CollectedBeforeClaimIsPutBack", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		store := newStore(t)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, store, limits)
+		existing, err := store.Put(context.Background(), bytes.NewReader(pngHeader), nil)
+		assert.NoError(t, err)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("UsageByUser", userID).Return(int64(0), nil)
+		// The collector takes the orphaned blob while the upload is being hashed
+		mockRepo.On("ClaimBlob", model.Blob{SHA256: existing.Key, Size: existing.Size}).
+			Run(func(mock.Arguments) { store.Delete(context.Background(), existing.Key) }).
+			Return(nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Attachment")).Return(nil)
+
+		attachment, err := attachmentUC.Upload(taskID, userID, "photo.png", int64(len(pngHeader)), bytes.NewReader(pngHeader))
+		assert.NoError(t, err)
+		assert.Equal(t, existing.Key, attachment.SHA256)
+
+		content, err := store.Open(context.Background(), existing.Key)
+		if assert.NoError(t, err) {
+			stored, _ := io.ReadAll(content)
+			content.Close()
+			assert.Equal(t, pngHeader, stored)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AnnouncedTooLarge", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+
+		_, err := attachmentUC.Upload(taskID, userID, "big.bin", 65, strings.NewReader(""))
+		assert.ErrorIs(t, err, usecase.ErrFileTooLarge)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("StreamTooLarge", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("UsageByUser", userID).Return(int64(0), nil)
+
+		// The client claims 10 bytes but sends more than the limit
+		_, err := attachmentUC.Upload(taskID, userID, "liar.bin", 10, strings.NewReader(strings.Repeat("x", 65)))
+		assert.ErrorIs(t, err, usecase.ErrFileTooLarge)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("QuotaExceeded", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("UsageByUser", userID).Return(int64(90), nil)
+
+		_, err := attachmentUC.Upload(taskID, userID, "a.txt", 20, strings.NewReader(strings.Repeat("x", 20)))
+		assert.ErrorIs(t, err, usecase.ErrQuotaExceeded)
+
+		_, err = attachmentUC.Upload(taskID, userID, "b.txt", 5, strings.NewReader(strings.Repeat("x", 20)))
+		assert.ErrorIs(t, err, usecase.ErrQuotaExceeded)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("UsageByUser", userID).Return(int64(0), nil)
+
+		_, err := attachmentUC.Upload(taskID, userID, "empty.txt", 0, strings.NewReader(""))
+		assert.ErrorIs(t, err, usecase.ErrEmptyFile)
+	})
+
+	t.Run("ViewerRefused", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+		workspaceID := uint(5)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: 200, WorkspaceID: &workspaceID}, nil)
+		mockRepo.On("FindRole", workspaceID, userID).Return(workspaceModel.RoleViewer, nil)
+		mockRepo.On("FindByIDAndTask", uint(3), taskID).Return(&model.Attachment{ID: 3, TaskID: taskID, UserID: userID}, nil)
+
+		_, err := attachmentUC.Upload(taskID, userID, "a.txt", 1, strings.NewReader("x"))
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		assert.ErrorIs(t, attachmentUC.DeleteAttachment(3, taskID, userID), usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("TaskNotFound", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), limits)
+
+		mockRepo.On("FindTask", taskID, userID).Return((*taskModel.Task)(nil), gorm.ErrRecordNotFound)
+
+		_, err := attachmentUC.Upload(taskID, userID, "a.txt", 1, strings.NewReader("x"))
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+	})
+}
+
+func TestOpenAttachment(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+
+	t.Run("MissingBlob", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), usecase.Limits{})
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", uint(3), taskID).Return(&model.Attachment{ID: 3, TaskID: taskID, SHA256: strings.Repeat("ab", 32)}, nil)
+
+		_, _, err := attachmentUC.Open(3, taskID, userID)
+		assert.ErrorIs(t, err, usecase.ErrAttachmentNotFound)
+	})
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	taskID := uint(1)
+	creatorID := uint(100)
+	assigneeID := uint(200)
+	task := &taskModel.Task{ID: taskID, UserID: creatorID, AssigneeID: &assigneeID}
+
+	t.Run("NotUploader", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), usecase.Limits{})
+
+		mockRepo.On("FindTask", taskID, assigneeID).Return(task, nil)
+		mockRepo.On("FindByIDAndTask", uint(3), taskID).Return(&model.Attachment{ID: 3, TaskID: taskID, UserID: creatorID}, nil)
+
+		err := attachmentUC.DeleteAttachment(3, taskID, assigneeID)
+		assert.ErrorIs(t, err, usecase.ErrNotUploader)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("CreatorDeletesAssigneeUpload", func(t *testing.T) {
+		mockRepo := new(MockAttachmentRepository)
+		attachmentUC := usecase.NewAttachmentUsecase(mockRepo, newStore(t), usecase.Limits{})
+
+		mockRepo.On("FindTask", taskID, creatorID).Return(task, nil)
+		mockRepo.On("FindByIDAndTask", uint(3), taskID).Return(&model.Attachment{ID: 3, TaskID: taskID, UserID: assigneeID}, nil)
+		mockRepo.On("Delete", uint(3)).Return(nil)
+
+		assert.NoError(t, attachmentUC.DeleteAttachment(3, taskID, creatorID))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCollector(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	store := newStore(t)
+	orphan, _ := store.Put(context.Background(), strings.NewReader("orphan"), nil)
+	reused, _ := store.Put(context.Background(), strings.NewReader("reused"), nil)
+
+	mockRepo.On("DeleteDetached").Return(int64(2), nil)
+	mockRepo.On("FindOrphanBlobs", mock.AnythingOfType("time.Time"), 100).
+		Return(&[]model.Blob{{SHA256: orphan.Key}, {SHA256: reused.Key}}, nil)
+	mockRepo.On("DeleteBlob", orphan.Key, mock.AnythingOfType("time.Time")).Return(true, nil)
+	// Picked up by an upload between the query and the delete
+	mockRepo.On("DeleteBlob", reused.Key, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	deleted, err := usecase.NewCollector(mockRepo, store, time.Hour).RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = store.Open(context.Background(), orphan.Key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	content, err := store.Open(context.Background(), reused.Key)
+	assert.NoError(t, err)
+	content.Close()
+}
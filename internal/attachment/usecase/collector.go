@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/storage"
+	"time"
+)
+
+const (
+	// BlobGracePeriod is how long an unreferenced blob is kept, it covers an
+	// upload that stored its blob but has not saved the attachment yet
+	BlobGracePeriod = time.Hour
+	// collectorBatch caps the blobs looked at per query
+	collectorBatch = 100
+)
+
+// Collector is the garbage-collection pass for attachment blobs. Purging a task
+// from the trash leaves its attachments behind where the database does not
+// cascade, the collector drops them and then every blob no attachment points to.
+// Tasks in the trash keep their attachments until they are purged.
+type Collector struct {
+	repo     AttachmentRepository
+	store    storage.Storage
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewCollector(repo AttachmentRepository, store storage.Storage, interval time.Duration) *Collector {
+	return &Collector{
+		repo:     repo,
+		store:    store,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the collector until ctx is cancelled, collecting once right away
+func (c *Collector) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.RunOnce(ctx); err != nil {
+			logger.Log.Error("Attachment collector run failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce removes the attachments of purged tasks and then the orphaned blobs,
+// returning how many blobs were deleted
+func (c *Collector) RunOnce(ctx context.Context) (int, error) {
+	detached, err := c.repo.DeleteDetached()
+	if err != nil {
+		return 0, err
+	}
+	if detached > 0 {
+		logger.Log.WithField("attachments", detached).Info("Removed attachments of purged tasks")
+	}
+
+	deleted := 0
+	before := c.now().Add(-BlobGracePeriod)
+	for {
+		blobs, err := c.repo.FindOrphanBlobs(before, collectorBatch)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, b := range *blobs {
+			if ctx.Err() != nil {
+				return deleted, ctx.Err()
+			}
+			// The file goes while the row is held, a blob picked up again by an
+			// upload in the meantime keeps its row and is skipped, and an upload
+			// claiming it waits for the delete and then puts the file back
+			ok, err := c.repo.DeleteBlob(b.SHA256, before, func() error {
+				return c.store.Delete(ctx, b.SHA256)
+			})
+			if err != nil {
+				logger.Log.WithField("sha256", b.SHA256).Error("Failed to delete blob: ", err)
+				return deleted, err
+			}
+			if !ok {
+				continue
+			}
+			deleted++
+		}
+
+		if len(*blobs) < collectorBatch {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrEmptyFile          = errors.New("file is empty")
+	ErrFileTooLarge       = errors.New("file exceeds the maximum size")
+	ErrQuotaExceeded      = errors.New("attachment storage quota exceeded")
+	ErrNotUploader        = errors.New("only the uploader or the task's creator can delete an attachment")
+	ErrReadOnly           = errors.New("your role in this workspace only allows viewing tasks")
+)
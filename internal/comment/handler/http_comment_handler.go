@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/comment/model"
+	"mymodule/internal/comment/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpCommenthandler struct {
+	usecase usecase.CommentUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewCommentHandler(app *fiber.App, usecase usecase.CommentUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpCommenthandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	comment := app.Group("/task/:id/comments", middleware.Middleware(token))
+	comment.Post("/", handler.Create)
+	comment.Get("/", handler.GetCommentsByTask)
+	comment.Put("/:commentId", handler.UpdateComment)
+	comment.Delete("/:commentId", handler.DeleteComment)
+	comment.Get("/:commentId/history", handler.GetHistory)
+}
+
+func (h *HttpCommenthandler) Create(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.CommentRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid comment request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	comment, err := h.usecase.Create(model.ToComment(input, uint(taskID), userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.ToCommentResponse(*comment))
+}
+
+func (h *HttpCommenthandler) GetCommentsByTask(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	comments, err := h.usecase.GetByTask(uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToCommentResponseList(*comments))
+}
+
+func (h *HttpCommenthandler) UpdateComment(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	commentID, err := strconv.Atoi(c.Params("commentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid comment ID"})
+	}
+
+	var input model.CommentRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid comment request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	comment, err := h.usecase.UpdateComment(uint(commentID), uint(taskID), userID, input.Body)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToCommentResponse(*comment))
+}
+
+func (h *HttpCommenthandler) DeleteComment(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	commentID, err := strconv.Atoi(c.Params("commentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid comment ID"})
+	}
+
+	if err := h.usecase.DeleteComment(uint(commentID), uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "comment deleted"})
+}
+
+func (h *HttpCommenthandler) GetHistory(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	commentID, err := strconv.Atoi(c.Params("commentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid comment ID"})
+	}
+
+	revisions, err := h.usecase.GetHistory(uint(commentID), uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToCommentRevisionResponseList(*revisions))
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound), errors.Is(err, usecase.ErrCommentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAuthor), errors.Is(err, usecase.ErrReadOnly):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is the DB model for a Markdown comment on a task
+type Comment struct {
+	ID        uint              `gorm:"primaryKey" json:"id" example:"1"`
+	TaskID    uint              `gorm:"not null;index" json:"task_id" example:"1"`
+	UserID    uint              `gorm:"not null;index" json:"user_id" example:"1"`
+	Body      string            `gorm:"type:text;not null" json:"body" example:"Looks good @alice"`
+	Mentions  []CommentMention  `gorm:"foreignKey:CommentID" json:"mentions,omitempty"`
+	Revisions []CommentRevision `gorm:"foreignKey:CommentID" json:"-"`
+	EditedAt  *time.Time        `gorm:"default:null" json:"edited_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// CommentMention records a user @mentioned in a comment, NotifiedAt stays empty
+// until a notification about it went out
+type CommentMention struct {
+	ID         uint       `gorm:"primaryKey" json:"id" example:"1"`
+	CommentID  uint       `gorm:"not null;uniqueIndex:idx_comment_mentions_comment_user" json:"comment_id" example:"1"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_comment_mentions_comment_user;index" json:"user_id" example:"2"`
+	NotifiedAt *time.Time `gorm:"default:null" json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CommentRevision keeps the text a comment had before an edit
+type CommentRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id" example:"1"`
+	CommentID uint      `gorm:"not null;index" json:"comment_id" example:"1"`
+	Body      string    `gorm:"type:text;not null" json:"body" example:"Looks god"`
+	CreatedAt time.Time `json:"created_at"` // when the text was replaced
+}
+
+// CommentRequest is the request model for writing or editing a comment
+type CommentRequest struct {
+	Body string `json:"body" example:"Looks good @alice" validate:"required,max=10000"`
+}
+
+// CommentResponse is the response model for a comment
+type CommentResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	TaskID     uint       `json:"task_id" example:"1"`
+	AuthorID   uint       `json:"author_id" example:"1"`
+	Body       string     `json:"body" example:"Looks **good** @alice"`
+	HTML       string     `json:"html" example:"<p>Looks <strong>good</strong> @alice</p>"`
+	MentionIDs []uint     `json:"mention_ids"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+}
+
+// CommentRevisionResponse is the response model for an earlier version of a comment
+type CommentRevisionResponse struct {
+	Body       string    `json:"body" example:"Looks god"`
+	HTML       string    `json:"html" example:"<p>Looks god</p>"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
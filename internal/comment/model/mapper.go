@@ -0,0 +1,49 @@
+package model
+
+import "mymodule/pkg/markdown"
+
+func ToComment(req CommentRequest, taskID, userID uint) Comment {
+	return Comment{
+		TaskID: taskID,
+		UserID: userID,
+		Body:   req.Body,
+	}
+}
+
+// ToCommentResponse renders the Markdown body to sanitised HTML
+func ToCommentResponse(c Comment) CommentResponse {
+	mentionIDs := make([]uint, 0, len(c.Mentions))
+	for _, m := range c.Mentions {
+		mentionIDs = append(mentionIDs, m.UserID)
+	}
+	return CommentResponse{
+		ID:         c.ID,
+		TaskID:     c.TaskID,
+		AuthorID:   c.UserID,
+		Body:       c.Body,
+		HTML:       markdown.ToSafeHTML(c.Body),
+		MentionIDs: mentionIDs,
+		CreatedAt:  c.CreatedAt,
+		EditedAt:   c.EditedAt,
+	}
+}
+
+func ToCommentResponseList(comments []Comment) []CommentResponse {
+	res := make([]CommentResponse, 0, len(comments))
+	for _, c := range comments {
+		res = append(res, ToCommentResponse(c))
+	}
+	return res
+}
+
+func ToCommentRevisionResponseList(revisions []CommentRevision) []CommentRevisionResponse {
+	res := make([]CommentRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		res = append(res, CommentRevisionResponse{
+			Body:       r.Body,
+			HTML:       markdown.ToSafeHTML(r.Body),
+			ReplacedAt: r.CreatedAt,
+		})
+	}
+	return res
+}
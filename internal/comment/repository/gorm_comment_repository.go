@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"mymodule/internal/comment/model"
+	"mymodule/internal/comment/usecase"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCommentRepository(db *gorm.DB) usecase.CommentRepository {
+	return &GormCommentRepository{db: db}
+}
+
+// Create stores a comment and the users it mentions
+func (r *GormCommentRepository) Create(comment *model.Comment, mentionIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(comment).Error; err != nil {
+			return err
+		}
+		mentions, err := syncMentions(tx, comment.ID, mentionIDs)
+		comment.Mentions = mentions
+		return err
+	})
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(comment.TaskID, comment.UserID)).Error("Failed to save comment")
+		return err
+	}
+	logger.Log.WithField("commentID", comment.ID).Info("Comment saved successfully")
+	return nil
+}
+
+func (r *GormCommentRepository) FindByTask(taskID uint) (*[]model.Comment, error) {
+	var comments []model.Comment
+	if err := r.db.Preload("Mentions").Where("task_id = ?", taskID).Order("created_at, id").Find(&comments).Error; err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to find comments by task")
+		return nil, err
+	}
+	return &comments, nil
+}
+
+func (r *GormCommentRepository) FindByIDAndTask(commentID, taskID uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.Preload("Mentions").Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error; err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to find comment")
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Update saves the new body, files the previous one as a revision and brings the
+// recorded mentions in line with the new text
+func (r *GormCommentRepository) Update(comment *model.Comment, previousBody string, mentionIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		revision := model.CommentRevision{CommentID: comment.ID, Body: previousBody}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(comment).Error; err != nil {
+			return err
+		}
+		mentions, err := syncMentions(tx, comment.ID, mentionIDs)
+		comment.Mentions = mentions
+		return err
+	})
+	if err != nil {
+		logger.Log.WithField("commentID", comment.ID).Error("Failed to update comment")
+		return err
+	}
+	logger.Log.WithField("commentID", comment.ID).Info("Comment updated successfully")
+	return nil
+}
+
+func (r *GormCommentRepository) Delete(commentID uint) error {
+	if err := r.db.Delete(&model.Comment{}, commentID).Error; err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to delete comment")
+		return err
+	}
+	return nil
+}
+
+func (r *GormCommentRepository) FindRevisions(commentID uint) (*[]model.CommentRevision, error) {
+	var revisions []model.CommentRevision
+	if err := r.db.Where("comment_id = ?", commentID).Order("created_at, id").Find(&revisions).Error; err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to find comment revisions")
+		return nil, err
+	}
+	return &revisions, nil
+}
+
+// FindTask returns the task with taskID if userID can see it
+func (r *GormCommentRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	var task taskModel.Task
+	if err := r.db.Scopes(taskModel.VisibleTo(userID)).Where("id = ?", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task for comment")
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindRole returns the role of userID in a workspace, empty when not a member
+func (r *GormCommentRepository) FindRole(workspaceID, userID uint) (string, error) {
+	var member workspaceModel.Member
+	result := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Limit(1).Find(&member)
+	if result.Error != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace role")
+		return "", result.Error
+	}
+	return member.Role, nil
+}
+
+// FindUsersByEmailOrHandle looks users up by lower-cased email or handle
+func (r *GormCommentRepository) FindUsersByEmailOrHandle(emails, handles []string) (*[]userModel.User, error) {
+	var users []userModel.User
+	query := r.db.Select("id", "email", "handle")
+	switch {
+	case len(emails) > 0 && len(handles) > 0:
+		query = query.Where("LOWER(email) IN ? OR handle IN ?", emails, handles)
+	case len(emails) > 0:
+		query = query.Where("LOWER(email) IN ?", emails)
+	default:
+		query = query.Where("handle IN ?", handles)
+	}
+	if err := query.Order("id").Find(&users).Error; err != nil {
+		logger.Log.Error("Failed to find mentioned users: ", err)
+		return nil, err
+	}
+	return &users, nil
+}
+
+// syncMentions makes userIDs the exact set of users mentioned by a comment. Mentions
+// that stay keep their row, so a notification already sent is not sent again.
+func syncMentions(tx *gorm.DB, commentID uint, userIDs []uint) ([]model.CommentMention, error) {
+	remove := tx.Where("comment_id = ?", commentID)
+	if len(userIDs) > 0 {
+		remove = remove.Where("user_id NOT IN ?", userIDs)
+	}
+	if err := remove.Delete(&model.CommentMention{}).Error; err != nil {
+		return nil, err
+	}
+
+	if len(userIDs) > 0 {
+		rows := make([]model.CommentMention, 0, len(userIDs))
+		for _, id := range userIDs {
+			rows = append(rows, model.CommentMention{CommentID: commentID, UserID: id})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var mentions []model.CommentMention
+	if err := tx.Where("comment_id = ?", commentID).Order("user_id").Find(&mentions).Error; err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
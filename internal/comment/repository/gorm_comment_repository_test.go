@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"log"
+	"mymodule/internal/comment/model"
+	"mymodule/internal/comment/repository"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	err = db.AutoMigrate(&userModel.User{}, &taskModel.Task{}, &model.Comment{}, &model.CommentMention{}, &model.CommentRevision{})
+	if err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func mentionedIDs(t *testing.T, db *gorm.DB, commentID uint) []uint {
+	var ids []uint
+	if err := db.Model(&model.CommentMention{}).Where("comment_id = ?", commentID).Order("user_id").Pluck("user_id", &ids).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ids
+}
+
+func TestCommentMentionsAndRevisions(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormCommentRepository(db)
+
+	comment := model.Comment{TaskID: 1, UserID: 1, Body: "hi @a @b"}
+	if err := repo.Create(&comment, []uint{2, 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mentionedIDs(t, db, comment.ID); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("expected mentions [2 3], got: %v", got)
+	}
+
+	// Mark one mention as notified, it must survive an edit that keeps it
+	db.Model(&model.CommentMention{}).Where("comment_id = ? AND user_id = ?", comment.ID, 3).Update("notified_at", comment.CreatedAt)
+
+	comment.Body = "hi @b @c"
+	if err := repo.Update(&comment, "hi @a @b", []uint{3, 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mentionedIDs(t, db, comment.ID); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("expected mentions [3 4], got: %v", got)
+	}
+	var kept model.CommentMention
+	db.Where("comment_id = ? AND user_id = ?", comment.ID, 3).First(&kept)
+	if kept.NotifiedAt == nil {
+		t.Errorf("expected the kept mention to stay notified")
+	}
+
+	revisions, err := repo.FindRevisions(comment.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*revisions) != 1 || (*revisions)[0].Body != "hi @a @b" {
+		t.Errorf("expected the previous body as revision, got: %v", *revisions)
+	}
+
+	found, err := repo.FindByIDAndTask(comment.ID, 1)
+	if err != nil || found.Body != "hi @b @c" || len(found.Mentions) != 2 {
+		t.Errorf("expected updated comment with mentions, got: %v, %v", found, err)
+	}
+
+	if err := repo.Update(&comment, "hi @b @c", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mentionedIDs(t, db, comment.ID); len(got) != 0 {
+		t.Errorf("expected all mentions removed, got: %v", got)
+	}
+}
+
+func TestFindUsersByEmailOrHandle(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormCommentRepository(db)
+
+	db.Create(&userModel.User{Name: "Alice", Email: "Alice@Example.com", Password: "x"})
+	db.Create(&userModel.User{Name: "Bob", Email: "bob@example.com", Password: "x", Handle: userModel.ToHandle("bob")})
+	db.Create(&userModel.User{Name: "Carol", Email: "carol@example.com", Password: "x"})
+
+	users, err := repo.FindUsersByEmailOrHandle([]string{"alice@example.com"}, []string{"bob", "nobody"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*users) != 2 {
+		t.Errorf("expected alice and bob, got: %v", *users)
+	}
+
+	users, err = repo.FindUsersByEmailOrHandle(nil, []string{"bob"})
+	if err != nil || len(*users) != 1 {
+		t.Errorf("expected only bob, got: %v, %v", users, err)
+	}
+}
+
+func TestFindCommentsByTask(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormCommentRepository(db)
+
+	first := model.Comment{TaskID: 5, UserID: 1, Body: "first"}
+	second := model.Comment{TaskID: 5, UserID: 1, Body: "second"}
+	repo.Create(&first, nil)
+	repo.Create(&second, []uint{2})
+	repo.Create(&model.Comment{TaskID: 6, UserID: 1, Body: "elsewhere"}, nil)
+	if err := repo.Delete(first.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comments, err := repo.FindByTask(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*comments) != 1 || (*comments)[0].Body != "second" || len((*comments)[0].Mentions) != 1 {
+		t.Errorf("expected only the remaining comment with its mention, got: %v", *comments)
+	}
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/internal/comment/model"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	Create(comment *model.Comment, mentionIDs []uint) error
+	FindByTask(taskID uint) (*[]model.Comment, error)
+	FindByIDAndTask(commentID, taskID uint) (*model.Comment, error)
+	Update(comment *model.Comment, previousBody string, mentionIDs []uint) error
+	Delete(commentID uint) error
+	FindRevisions(commentID uint) (*[]model.CommentRevision, error)
+	FindTask(taskID, userID uint) (*taskModel.Task, error)
+	FindRole(workspaceID, userID uint) (string, error)
+	FindUsersByEmailOrHandle(emails, handles []string) (*[]userModel.User, error)
+}
+
+type CommentUsecase interface {
+	Create(comment model.Comment) (*model.Comment, error)
+	GetByTask(taskID, userID uint) (*[]model.Comment, error)
+	UpdateComment(commentID, taskID, userID uint, body string) (*model.Comment, error)
+	DeleteComment(commentID, taskID, userID uint) error
+	GetHistory(commentID, taskID, userID uint) (*[]model.CommentRevision, error)
+}
+
+type CommentusecaseImpl struct {
+	repo CommentRepository
+}
+
+func NewCommentUsecase(repo CommentRepository) CommentUsecase {
+	return &CommentusecaseImpl{
+		repo: repo,
+	}
+}
+
+func (uc *CommentusecaseImpl) Create(comment model.Comment) (*model.Comment, error) {
+	task, err := uc.checkTask(comment.TaskID, comment.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkWritable(task, comment.UserID); err != nil {
+		return nil, err
+	}
+
+	mentionIDs, err := uc.resolveMentions(comment.Body, comment.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(&comment, mentionIDs); err != nil {
+		logger.Log.WithFields(logger.LogFields(comment.TaskID, comment.UserID)).Error("Failed to create comment")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(comment.TaskID, comment.UserID)).Info("Comment created successfully")
+	return &comment, nil
+}
+
+func (uc *CommentusecaseImpl) GetByTask(taskID, userID uint) (*[]model.Comment, error) {
+	if _, err := uc.checkTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	comments, err := uc.repo.FindByTask(taskID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get comments")
+		return nil, err
+	}
+	return comments, nil
+}
+
+// UpdateComment replaces the body of a comment, keeping the old text as a revision
+func (uc *CommentusecaseImpl) UpdateComment(commentID, taskID, userID uint, body string) (*model.Comment, error) {
+	comment, err := uc.findOwnComment(commentID, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	mentionIDs, err := uc.resolveMentions(body, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := comment.Body
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	if err := uc.repo.Update(comment, previous, mentionIDs); err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to update comment")
+		return nil, err
+	}
+
+	logger.Log.WithField("commentID", commentID).Info("Comment updated successfully")
+	return comment, nil
+}
+
+func (uc *CommentusecaseImpl) DeleteComment(commentID, taskID, userID uint) error {
+	comment, err := uc.findOwnComment(commentID, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(comment.ID); err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to delete comment")
+		return err
+	}
+
+	logger.Log.WithField("commentID", commentID).Info("Comment deleted")
+	return nil
+}
+
+// GetHistory lists the earlier versions of a comment, oldest first
+func (uc *CommentusecaseImpl) GetHistory(commentID, taskID, userID uint) (*[]model.CommentRevision, error) {
+	if _, err := uc.checkTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := uc.findComment(commentID, taskID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := uc.repo.FindRevisions(commentID)
+	if err != nil {
+		logger.Log.WithField("commentID", commentID).Error("Failed to get comment history")
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// checkTask applies the same visibility rule as the task module's FindByIDAndUser
+func (uc *CommentusecaseImpl) checkTask(taskID, userID uint) (*taskModel.Task, error) {
+	task, err := uc.repo.FindTask(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for this user")
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// checkWritable refuses changes by userID on a task of a workspace where they
+// may only view, as the task module does
+func (uc *CommentusecaseImpl) checkWritable(task *taskModel.Task, userID uint) error {
+	if task.WorkspaceID == nil {
+		return nil
+	}
+	role, err := uc.repo.FindRole(*task.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+		logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Viewer tried to change a task")
+		return ErrReadOnly
+	}
+	return nil
+}
+
+func (uc *CommentusecaseImpl) findComment(commentID, taskID uint) (*model.Comment, error) {
+	comment, err := uc.repo.FindByIDAndTask(commentID, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("commentID", commentID).Warn("Comment not found")
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// findOwnComment loads a comment of userID they may still change
+func (uc *CommentusecaseImpl) findOwnComment(commentID, taskID, userID uint) (*model.Comment, error) {
+	task, err := uc.checkTask(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkWritable(task, userID); err != nil {
+		return nil, err
+	}
+	comment, err := uc.findComment(commentID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		logger.Log.WithField("commentID", commentID).Warn("Comment change by someone other than its author")
+		return nil, ErrNotAuthor
+	}
+	return comment, nil
+}
+
+// resolveMentions turns the @mentions of body into user IDs, skipping unknown
+// names and the author
+func (uc *CommentusecaseImpl) resolveMentions(body string, authorID uint) ([]uint, error) {
+	emails, handles := parseMentions(body)
+	if len(emails) == 0 && len(handles) == 0 {
+		return nil, nil
+	}
+
+	users, err := uc.repo.FindUsersByEmailOrHandle(emails, handles)
+	if err != nil {
+		logger.Log.WithField("userID", authorID).Error("Failed to resolve mentions")
+		return nil, err
+	}
+	ids := make([]uint, 0, len(*users))
+	for _, u := range *users {
+		if u.ID != authorID {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
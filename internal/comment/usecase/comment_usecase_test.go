@@ -0,0 +1,220 @@
+package usecase_test
+
+import (
+	"mymodule/internal/comment/model"
+	"mymodule/internal/comment/usecase"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) Create(comment *model.Comment, mentionIDs []uint) error {
+	args := m.Called(comment, mentionIDs)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) FindByTask(taskID uint) (*[]model.Comment, error) {
+	args := m.Called(taskID)
+	return args.Get(0).(*[]model.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindByIDAndTask(commentID, taskID uint) (*model.Comment, error) {
+	args := m.Called(commentID, taskID)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Update(comment *model.Comment, previousBody string, mentionIDs []uint) error {
+	args := m.Called(comment, previousBody, mentionIDs)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) Delete(commentID uint) error {
+	args := m.Called(commentID)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) FindRevisions(commentID uint) (*[]model.CommentRevision, error) {
+	args := m.Called(commentID)
+	return args.Get(0).(*[]model.CommentRevision), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*taskModel.Task), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindUsersByEmailOrHandle(emails, handles []string) (*[]userModel.User, error) {
+	args := m.Called(emails, handles)
+	return args.Get(0).(*[]userModel.User), args.Error(1)
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func TestCreateComment(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+
+	t.Run("ResolvesMentions", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		body := "Ping @Alice and @bob@example.com, again @alice. Not `@carol` or mail@dave"
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindUsersByEmailOrHandle", []string{"bob@example.com"}, []string{"alice"}).
+			Return(&[]userModel.User{{ID: 2}, {ID: userID}, {ID: 3}}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Comment"), []uint{2, 3}).Return(nil)
+
+		comment, err := commentUC.Create(model.Comment{TaskID: taskID, UserID: userID, Body: body})
+		assert.NoError(t, err)
+		assert.Equal(t, body, comment.Body)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NoMentions", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Comment"), []uint(nil)).Return(nil)
+
+		_, err := commentUC.Create(model.Comment{TaskID: taskID, UserID: userID, Body: "plain text"})
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "FindUsersByEmailOrHandle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ViewerRefused", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+		workspaceID := uint(5)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: 200, WorkspaceID: &workspaceID}, nil)
+		mockRepo.On("FindRole", workspaceID, userID).Return(workspaceModel.RoleViewer, nil)
+
+		_, err := commentUC.Create(model.Comment{TaskID: taskID, UserID: userID, Body: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		assert.ErrorIs(t, commentUC.DeleteComment(7, taskID, userID), usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("TaskNotFound", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return((*taskModel.Task)(nil), gorm.ErrRecordNotFound)
+
+		_, err := commentUC.Create(model.Comment{TaskID: taskID, UserID: userID, Body: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	commentID := uint(7)
+
+	t.Run("KeepsPreviousBody", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		existing := &model.Comment{ID: commentID, TaskID: taskID, UserID: userID, Body: "first draft"}
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", commentID, taskID).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(c *model.Comment) bool {
+			return c.Body == "second draft" && c.EditedAt != nil
+		}), "first draft", []uint(nil)).Return(nil)
+
+		comment, err := commentUC.UpdateComment(commentID, taskID, userID, "second draft")
+		assert.NoError(t, err)
+		assert.NotNil(t, comment.EditedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnchangedBody", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		existing := &model.Comment{ID: commentID, TaskID: taskID, UserID: userID, Body: "same"}
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", commentID, taskID).Return(existing, nil)
+
+		comment, err := commentUC.UpdateComment(commentID, taskID, userID, "same")
+		assert.NoError(t, err)
+		assert.Nil(t, comment.EditedAt)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotAuthor", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		existing := &model.Comment{ID: commentID, TaskID: taskID, UserID: 200, Body: "theirs"}
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", commentID, taskID).Return(existing, nil)
+
+		_, err := commentUC.UpdateComment(commentID, taskID, userID, "mine now")
+		assert.ErrorIs(t, err, usecase.ErrNotAuthor)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	commentID := uint(7)
+
+	t.Run("CommentNotFound", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", commentID, taskID).Return((*model.Comment)(nil), gorm.ErrRecordNotFound)
+
+		err := commentUC.DeleteComment(commentID, taskID, userID)
+		assert.ErrorIs(t, err, usecase.ErrCommentNotFound)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockCommentRepository)
+		commentUC := usecase.NewCommentUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndTask", commentID, taskID).Return(&model.Comment{ID: commentID, TaskID: taskID, UserID: userID}, nil)
+		mockRepo.On("Delete", commentID).Return(nil)
+
+		assert.NoError(t, commentUC.DeleteComment(commentID, taskID, userID))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCommentResponseIsSanitised(t *testing.T) {
+	res := model.ToCommentResponse(model.Comment{
+		Body: "**bold** <script>alert(1)</script> [click](javascript:alert(1)) <img src=x onerror=alert(1)>",
+	})
+
+	assert.Contains(t, res.HTML, "<strong>bold</strong>")
+	for _, bad := range []string{"<script", "javascript:", "onerror"} {
+		assert.False(t, strings.Contains(res.HTML, bad), "expected %q to be stripped from %s", bad, res.HTML)
+	}
+}
@@ -0,0 +1,10 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrNotAuthor       = errors.New("only the author can change a comment")
+	ErrReadOnly        = errors.New("your role in this workspace only allows viewing tasks")
+)
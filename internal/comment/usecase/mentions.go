@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// An @ not glued to a preceding word, followed by an email address or a handle
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([\w.+-]+@[\w-]+(?:\.[\w-]+)+|\w+)`)
+	// Code is quoted text, @ signs in it are not mentions
+	codePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+)
+
+// parseMentions returns the distinct emails and handles @mentioned in a Markdown body,
+// lower-cased since both compare case-insensitively
+func parseMentions(body string) (emails, handles []string) {
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(codePattern.ReplaceAllString(body, " "), -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], "."))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if strings.Contains(name, "@") {
+			emails = append(emails, name)
+		} else {
+			handles = append(handles, name)
+		}
+	}
+	return emails, handles
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/label/model"
+	"mymodule/internal/label/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpLabelhandler struct {
+	usecase usecase.LabelUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewLabelHandler(app *fiber.App, usecase usecase.LabelUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpLabelhandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	label := app.Group("/label", middleware.Middleware(token))
+	label.Post("/", handler.Create)
+	label.Get("/", handler.GetLabelsByUser)
+	label.Put("/:id", handler.UpdateLabel)
+	label.Delete("/:id", handler.DeleteLabel)
+}
+
+func (h *HttpLabelhandler) Create(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.CreateLabelRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid label request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	label, err := h.usecase.Create(model.ToLabel(input, userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.ToLabelResponse(*label))
+}
+
+func (h *HttpLabelhandler) GetLabelsByUser(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	labels, err := h.usecase.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch labels"})
+	}
+	var resp []model.LabelResponse
+	if labels != nil {
+		resp = model.ToLabelResponseList(*labels)
+	}
+	return c.JSON(resp)
+}
+
+func (h *HttpLabelhandler) UpdateLabel(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	labelID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid label ID"})
+	}
+
+	var input model.UpdateLabelRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.UpdateLabel(&input, uint(labelID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "label updated"})
+}
+
+func (h *HttpLabelhandler) DeleteLabel(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	labelID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid label ID"})
+	}
+
+	if err := h.usecase.DeleteLabel(uint(labelID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "label deleted"})
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrLabelNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrLabelExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
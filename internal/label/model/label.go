@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Label is the DB model for a user's label, attached to tasks through task_labels
+type Label struct {
+	ID        uint      `gorm:"primaryKey" json:"id" example:"1"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_labels_user_name" json:"name" example:"work"`
+	Color     string    `gorm:"type:varchar(7);not null;default:'#808080'" json:"color" example:"#ff8800"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_labels_user_name" json:"user_id" example:"1"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateLabelRequest is the request model for creating a label
+type CreateLabelRequest struct {
+	Name  string `json:"name" example:"work" validate:"required,max=50"`
+	Color string `json:"color,omitempty" example:"#ff8800" validate:"omitempty,hexcolor"`
+}
+
+// UpdateLabelRequest is the request model for updating a label
+type UpdateLabelRequest struct {
+	Name  *string `json:"name,omitempty" example:"work" validate:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty" example:"#ff8800" validate:"omitempty,hexcolor"`
+}
+
+// LabelResponse is the response model for a label
+type LabelResponse struct {
+	ID    uint   `json:"id" example:"1"`
+	Name  string `json:"name" example:"work"`
+	Color string `json:"color" example:"#ff8800"`
+}
@@ -0,0 +1,40 @@
+package model
+
+const DefaultColor = "#808080"
+
+func ToLabel(req CreateLabelRequest, userID uint) Label {
+	color := req.Color
+	if color == "" {
+		color = DefaultColor
+	}
+	return Label{
+		Name:   req.Name,
+		Color:  color,
+		UserID: userID,
+	}
+}
+
+func ToLabelResponse(label Label) LabelResponse {
+	return LabelResponse{
+		ID:    label.ID,
+		Name:  label.Name,
+		Color: label.Color,
+	}
+}
+
+func ToLabelResponseList(labels []Label) []LabelResponse {
+	res := make([]LabelResponse, 0, len(labels))
+	for _, l := range labels {
+		res = append(res, ToLabelResponse(l))
+	}
+	return res
+}
+
+func ApplyUpdate(existing *Label, input UpdateLabelRequest) {
+	if input.Name != nil {
+		existing.Name = *input.Name
+	}
+	if input.Color != nil {
+		existing.Color = *input.Color
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"mymodule/internal/label/model"
+	"mymodule/internal/label/usecase"
+	"mymodule/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type GormLabelRepository struct {
+	db *gorm.DB
+}
+
+func NewGormLabelRepository(db *gorm.DB) usecase.LabelRepository {
+	return &GormLabelRepository{db: db}
+}
+
+func (r *GormLabelRepository) Save(label *model.Label) error {
+	if err := r.db.Create(label).Error; err != nil {
+		logger.Log.WithField("userID", label.UserID).Error("Failed to save label")
+		return err
+	}
+	logger.Log.WithField("labelID", label.ID).Info("Label saved successfully")
+	return nil
+}
+
+func (r *GormLabelRepository) FindByUser(userID uint) (*[]model.Label, error) {
+	var labels []model.Label
+	if err := r.db.Where("user_id = ?", userID).Order("name").Find(&labels).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find labels by user ID")
+		return nil, err
+	}
+	return &labels, nil
+}
+
+func (r *GormLabelRepository) FindByIDAndUser(labelID, userID uint) (*model.Label, error) {
+	var label model.Label
+	if err := r.db.Where("id = ? AND user_id = ?", labelID, userID).First(&label).Error; err != nil {
+		logger.Log.WithFields(map[string]interface{}{"labelID": labelID, "userID": userID}).Error("Failed to find label by ID and user ID")
+		return nil, err
+	}
+	return &label, nil
+}
+
+// FindByName returns nil without an error when the user has no label of that name
+func (r *GormLabelRepository) FindByName(name string, userID uint) (*model.Label, error) {
+	var label model.Label
+	result := r.db.Where("name = ? AND user_id = ?", name, userID).First(&label)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		logger.Log.WithField("userID", userID).Error("Database error finding label by name")
+		return nil, result.Error
+	}
+	return &label, nil
+}
+
+func (r *GormLabelRepository) Update(label *model.Label) error {
+	if err := r.db.Save(label).Error; err != nil {
+		logger.Log.WithField("labelID", label.ID).Error("Failed to update label")
+		return err
+	}
+	logger.Log.WithField("labelID", label.ID).Info("Label updated successfully")
+	return nil
+}
+
+// Delete removes the label and detaches it from every task
+func (r *GormLabelRepository) Delete(labelID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", labelID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Label{}, labelID).Error
+	})
+	if err != nil {
+		logger.Log.WithField("labelID", labelID).Error("Failed to delete label")
+		return err
+	}
+	logger.Log.WithField("labelID", labelID).Info("Label deleted successfully")
+	return nil
+}
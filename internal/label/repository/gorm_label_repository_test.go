@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"log"
+	"mymodule/internal/label/model"
+	"mymodule/internal/label/repository"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func WithRollback(db *gorm.DB, t *testing.T, testFunc func(tx *gorm.DB)) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin transaction: %v", tx.Error)
+	}
+
+	defer func() {
+		err := tx.Rollback().Error
+		if err != nil && err != gorm.ErrInvalidTransaction {
+			t.Fatalf("failed to rollback transaction: %v", err)
+		}
+	}()
+
+	testFunc(tx)
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	// Migrating tasks also creates labels and the task_labels join table
+	err = db.AutoMigrate(&taskModel.Task{})
+	if err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestSaveAndFindLabel(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormLabelRepository(tx)
+
+		label := model.Label{Name: "work", Color: "#ff0000", UserID: 1}
+		if err := repo.Save(&label); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if label.ID == 0 {
+			t.Fatalf("expected label ID to be set")
+		}
+
+		found, err := repo.FindByIDAndUser(label.ID, 1)
+		if err != nil || found.Name != "work" {
+			t.Errorf("expected to find label, got: %v, %v", found, err)
+		}
+		if _, err := repo.FindByIDAndUser(label.ID, 2); err != gorm.ErrRecordNotFound {
+			t.Errorf("expected another user's label to be hidden, got: %v", err)
+		}
+
+		byName, err := repo.FindByName("work", 1)
+		if err != nil || byName == nil || byName.ID != label.ID {
+			t.Errorf("expected to find label by name, got: %v, %v", byName, err)
+		}
+		missing, err := repo.FindByName("work", 2)
+		if err != nil || missing != nil {
+			t.Errorf("expected nil label and nil error, got: %v, %v", missing, err)
+		}
+	})
+}
+
+func TestFindLabelsByUser(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormLabelRepository(tx)
+
+		for _, name := range []string{"zeta", "alpha"} {
+			tx.Create(&model.Label{Name: name, Color: model.DefaultColor, UserID: 11})
+		}
+		tx.Create(&model.Label{Name: "other", Color: model.DefaultColor, UserID: 12})
+
+		labels, err := repo.FindByUser(11)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*labels) != 2 || (*labels)[0].Name != "alpha" {
+			t.Errorf("expected the user's labels sorted by name, got: %v", *labels)
+		}
+	})
+}
+
+func TestDeleteLabelDetachesTasks(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormLabelRepository(tx)
+
+		label := model.Label{Name: "doomed", Color: model.DefaultColor, UserID: 21}
+		tx.Create(&label)
+		task := taskModel.Task{Title: "Labelled", UserID: 21, Labels: []model.Label{label}}
+		tx.Create(&task)
+
+		if err := repo.Delete(label.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var links int64
+		tx.Table("task_labels").Where("label_id = ?", label.ID).Count(&links)
+		if links != 0 {
+			t.Errorf("expected task_labels rows to be removed, got: %d", links)
+		}
+		var count int64
+		tx.Model(&model.Label{}).Where("id = ?", label.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("expected label to be deleted")
+		}
+	})
+}
@@ -0,0 +1,8 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrLabelNotFound = errors.New("label not found")
+	ErrLabelExists   = errors.New("label name already in use")
+)
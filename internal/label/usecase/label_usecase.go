@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/internal/label/model"
+	"mymodule/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type LabelRepository interface {
+	Save(label *model.Label) error
+	FindByUser(userID uint) (*[]model.Label, error)
+	FindByIDAndUser(labelID, userID uint) (*model.Label, error)
+	FindByName(name string, userID uint) (*model.Label, error)
+	Update(label *model.Label) error
+	Delete(labelID uint) error
+}
+
+type LabelUsecase interface {
+	Create(label model.Label) (*model.Label, error)
+	GetByUser(userID uint) (*[]model.Label, error)
+	UpdateLabel(input *model.UpdateLabelRequest, labelID, userID uint) error
+	DeleteLabel(labelID, userID uint) error
+}
+
+type LabelusecaseImpl struct {
+	repo LabelRepository
+}
+
+func NewLabelUsecase(repo LabelRepository) LabelUsecase {
+	return &LabelusecaseImpl{
+		repo: repo,
+	}
+}
+
+func (uc *LabelusecaseImpl) Create(label model.Label) (*model.Label, error) {
+	existing, err := uc.repo.FindByName(label.Name, label.UserID)
+	if err != nil {
+		logger.Log.WithField("userID", label.UserID).Error("DB error while checking label name: ", err)
+		return nil, err
+	}
+	if existing != nil {
+		logger.Log.WithField("userID", label.UserID).Warn("Label name already exists: ", label.Name)
+		return nil, ErrLabelExists
+	}
+
+	if err := uc.repo.Save(&label); err != nil {
+		logger.Log.WithField("userID", label.UserID).Error("Failed to create label")
+		return nil, err
+	}
+
+	logger.Log.WithField("userID", label.UserID).Info("Label created successfully")
+	return &label, nil
+}
+
+func (uc *LabelusecaseImpl) GetByUser(userID uint) (*[]model.Label, error) {
+	labels, err := uc.repo.FindByUser(userID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get labels by user")
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (uc *LabelusecaseImpl) UpdateLabel(input *model.UpdateLabelRequest, labelID, userID uint) error {
+	label, err := uc.findOwned(labelID, userID)
+	if err != nil {
+		return err
+	}
+
+	if input.Name != nil && *input.Name != label.Name {
+		existing, err := uc.repo.FindByName(*input.Name, userID)
+		if err != nil {
+			logger.Log.WithField("labelID", labelID).Error("DB error while checking label name: ", err)
+			return err
+		}
+		if existing != nil {
+			logger.Log.WithField("labelID", labelID).Warn("Update failed: label name already exists")
+			return ErrLabelExists
+		}
+	}
+
+	model.ApplyUpdate(label, *input)
+	if err := uc.repo.Update(label); err != nil {
+		logger.Log.WithField("labelID", labelID).Error("Failed to update label")
+		return err
+	}
+
+	logger.Log.WithField("labelID", labelID).Info("Label updated successfully")
+	return nil
+}
+
+func (uc *LabelusecaseImpl) DeleteLabel(labelID, userID uint) error {
+	if _, err := uc.findOwned(labelID, userID); err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(labelID); err != nil {
+		logger.Log.WithField("labelID", labelID).Error("Failed to delete label")
+		return err
+	}
+
+	logger.Log.WithField("labelID", labelID).Info("Label deleted")
+	return nil
+}
+
+func (uc *LabelusecaseImpl) findOwned(labelID, userID uint) (*model.Label, error) {
+	label, err := uc.repo.FindByIDAndUser(labelID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("labelID", labelID).Warn("Label not found for this user")
+			return nil, ErrLabelNotFound
+		}
+		logger.Log.WithField("labelID", labelID).Error("DB error when finding label")
+		return nil, err
+	}
+	return label, nil
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"errors"
+	"mymodule/internal/label/model"
+	"mymodule/internal/label/usecase"
+	"mymodule/pkg/logger"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockLabelRepository struct {
+	mock.Mock
+}
+
+func (m *MockLabelRepository) Save(label *model.Label) error {
+	args := m.Called(label)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) FindByUser(userID uint) (*[]model.Label, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*[]model.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) FindByIDAndUser(labelID, userID uint) (*model.Label, error) {
+	args := m.Called(labelID, userID)
+	return args.Get(0).(*model.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) FindByName(name string, userID uint) (*model.Label, error) {
+	args := m.Called(name, userID)
+	return args.Get(0).(*model.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) Update(label *model.Label) error {
+	args := m.Called(label)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) Delete(labelID uint) error {
+	args := m.Called(labelID)
+	return args.Error(0)
+}
+
+func TestCreateLabel(t *testing.T) {
+	logger.InitLogger()
+	label := model.ToLabel(model.CreateLabelRequest{Name: "work"}, 1)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByName", "work", uint(1)).Return((*model.Label)(nil), nil)
+		mockRepo.On("Save", mock.MatchedBy(func(l *model.Label) bool {
+			return l.Color == model.DefaultColor
+		})).Return(nil)
+
+		created, err := labelUC.Create(label)
+		assert.NoError(t, err)
+		assert.Equal(t, "work", created.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByName", "work", uint(1)).Return(&model.Label{ID: 3, Name: "work"}, nil)
+
+		created, err := labelUC.Create(label)
+		assert.Nil(t, created)
+		assert.ErrorIs(t, err, usecase.ErrLabelExists)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("SaveError", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByName", "work", uint(1)).Return((*model.Label)(nil), nil)
+		mockRepo.On("Save", mock.Anything).Return(errors.New("db error"))
+
+		_, err := labelUC.Create(label)
+		assert.EqualError(t, err, "db error")
+	})
+}
+
+func TestUpdateLabel(t *testing.T) {
+	logger.InitLogger()
+	name := "home"
+	color := "#00ff00"
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(3), uint(1)).Return(&model.Label{ID: 3, Name: "work", UserID: 1}, nil)
+		mockRepo.On("FindByName", "home", uint(1)).Return((*model.Label)(nil), nil)
+		mockRepo.On("Update", mock.MatchedBy(func(l *model.Label) bool {
+			return l.Name == "home" && l.Color == "#00ff00"
+		})).Return(nil)
+
+		err := labelUC.UpdateLabel(&model.UpdateLabelRequest{Name: &name, Color: &color}, 3, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotOwned", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(3), uint(2)).Return((*model.Label)(nil), gorm.ErrRecordNotFound)
+
+		err := labelUC.UpdateLabel(&model.UpdateLabelRequest{Name: &name}, 3, 2)
+		assert.ErrorIs(t, err, usecase.ErrLabelNotFound)
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(3), uint(1)).Return(&model.Label{ID: 3, Name: "work", UserID: 1}, nil)
+		mockRepo.On("FindByName", "home", uint(1)).Return(&model.Label{ID: 4, Name: "home"}, nil)
+
+		err := labelUC.UpdateLabel(&model.UpdateLabelRequest{Name: &name}, 3, 1)
+		assert.ErrorIs(t, err, usecase.ErrLabelExists)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestDeleteLabel(t *testing.T) {
+	logger.InitLogger()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(3), uint(1)).Return(&model.Label{ID: 3, UserID: 1}, nil)
+		mockRepo.On("Delete", uint(3)).Return(nil)
+
+		err := labelUC.DeleteLabel(3, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotOwned", func(t *testing.T) {
+		mockRepo := new(MockLabelRepository)
+		labelUC := usecase.NewLabelUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(3), uint(2)).Return((*model.Label)(nil), gorm.ErrRecordNotFound)
+
+		err := labelUC.DeleteLabel(3, 2)
+		assert.ErrorIs(t, err, usecase.ErrLabelNotFound)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/project/model"
+	"mymodule/internal/project/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpProjecthandler struct {
+	usecase usecase.ProjectUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewProjectHandler(app *fiber.App, usecase usecase.ProjectUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpProjecthandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	project := app.Group("/project", middleware.Middleware(token))
+	project.Post("/", handler.Create)
+	project.Get("/", handler.GetProjectsByUser)
+	project.Put("/:id", handler.RenameProject)
+	project.Post("/:id/archive", handler.ArchiveProject)
+	project.Post("/:id/unarchive", handler.UnarchiveProject)
+	project.Delete("/:id", handler.DeleteProject)
+	project.Get("/:id/workflow", handler.GetWorkflow)
+	project.Put("/:id/workflow", handler.SetWorkflow)
+	project.Delete("/:id/workflow", handler.ResetWorkflow)
+}
+
+func (h *HttpProjecthandler) Create(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.CreateProjectRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid project request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	project, err := h.usecase.Create(model.ToProject(input, userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.ToProjectResponse(*project))
+}
+
+// GetProjectsByUser lists the user's projects, archived ones only with ?archived=true
+func (h *HttpProjecthandler) GetProjectsByUser(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	projects, err := h.usecase.GetByUser(userID, c.QueryBool("archived", false))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch projects"})
+	}
+	var resp []model.ProjectResponse
+	if projects != nil {
+		resp = model.ToProjectResponseList(*projects)
+	}
+	return c.JSON(resp)
+}
+
+func (h *HttpProjecthandler) RenameProject(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	var input model.RenameProjectRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.Rename(uint(projectID), userID, input.Name); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "project renamed"})
+}
+
+func (h *HttpProjecthandler) ArchiveProject(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	if err := h.usecase.Archive(uint(projectID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "project archived"})
+}
+
+func (h *HttpProjecthandler) UnarchiveProject(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	if err := h.usecase.Unarchive(uint(projectID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "project unarchived"})
+}
+
+// DeleteProject requires ?tasks=delete or ?tasks=move to say what happens to the project's tasks
+func (h *HttpProjecthandler) DeleteProject(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	mode := usecase.DeleteMode(strings.ToLower(c.Query("tasks")))
+	if err := h.usecase.DeleteProject(uint(projectID), userID, mode); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "project deleted"})
+}
+
+// GetWorkflow lists the statuses of the project's tasks in board order
+func (h *HttpProjecthandler) GetWorkflow(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	workflow, err := h.usecase.GetWorkflow(uint(projectID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToWorkflowResponse(*workflow))
+}
+
+// SetWorkflow replaces the statuses of the project's tasks
+func (h *HttpProjecthandler) SetWorkflow(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	var input model.WorkflowRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.SetWorkflow(uint(projectID), userID, model.ToWorkflowStatuses(input)); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "workflow saved"})
+}
+
+// ResetWorkflow puts the project back on the built-in statuses
+func (h *HttpProjecthandler) ResetWorkflow(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	if err := h.usecase.ResetWorkflow(uint(projectID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "workflow reset"})
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrProjectNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidDeleteMode), errors.Is(err, usecase.ErrInboxReserved),
+		errors.Is(err, usecase.ErrInvalidWorkflow):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrInboxProtected), errors.Is(err, usecase.ErrMoveIntoSelf),
+		errors.Is(err, usecase.ErrInboxWorkflow):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
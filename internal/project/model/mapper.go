@@ -0,0 +1,42 @@
+package model
+
+func ToProject(req CreateProjectRequest, userID uint) Project {
+	return Project{
+		Name:   req.Name,
+		UserID: userID,
+	}
+}
+
+func ToProjectResponse(p Project) ProjectResponse {
+	return ProjectResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		IsInbox:    p.IsInbox,
+		ArchivedAt: p.ArchivedAt,
+	}
+}
+
+func ToProjectResponseList(projects []Project) []ProjectResponse {
+	res := make([]ProjectResponse, 0, len(projects))
+	for _, p := range projects {
+		res = append(res, ToProjectResponse(p))
+	}
+	return res
+}
+
+// ToWorkflowStatuses numbers the requested statuses in the order they were given
+func ToWorkflowStatuses(req WorkflowRequest) []WorkflowStatus {
+	statuses := make([]WorkflowStatus, 0, len(req.Statuses))
+	for i, s := range req.Statuses {
+		statuses = append(statuses, WorkflowStatus{Key: s.Key, Name: s.Name, Category: s.Category, Position: i + 1})
+	}
+	return statuses
+}
+
+func ToWorkflowResponse(w Workflow) WorkflowResponse {
+	statuses := make([]WorkflowStatusResponse, 0, len(w.Statuses))
+	for _, s := range w.Statuses {
+		statuses = append(statuses, WorkflowStatusResponse{Key: s.Key, Name: s.Name, Category: s.Category})
+	}
+	return WorkflowResponse{Custom: w.ID != 0, Statuses: statuses}
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InboxName is the project that receives the tasks of deleted projects
+const InboxName = "Inbox"
+
+// Project is the DB model for a group of tasks
+type Project struct {
+	ID         uint           `gorm:"primaryKey" json:"id" example:"1"`
+	Name       string         `gorm:"type:text;not null" json:"name" example:"Website relaunch"`
+	UserID     uint           `gorm:"not null;index" json:"user_id" example:"1"`
+	IsInbox    bool           `gorm:"not null;default:false" json:"is_inbox" example:"false"`
+	ArchivedAt *time.Time     `gorm:"default:null" json:"archived_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// CreateProjectRequest is the request model for creating a project
+type CreateProjectRequest struct {
+	Name string `json:"name" example:"Website relaunch" validate:"required,max=100"`
+}
+
+// RenameProjectRequest is the request model for renaming a project
+type RenameProjectRequest struct {
+	Name string `json:"name" example:"Website relaunch" validate:"required,max=100"`
+}
+
+// ProjectResponse is the response model for a project
+type ProjectResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"Website relaunch"`
+	IsInbox    bool       `json:"is_inbox" example:"false"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
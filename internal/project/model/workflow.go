@@ -0,0 +1,62 @@
+package model
+
+import (
+	taskModel "mymodule/internal/task/model"
+	"time"
+)
+
+// Workflow replaces the built-in statuses for the tasks of one project
+type Workflow struct {
+	ID        uint             `gorm:"primaryKey" json:"id" example:"1"`
+	ProjectID uint             `gorm:"not null;uniqueIndex" json:"project_id" example:"1"`
+	Statuses  []WorkflowStatus `gorm:"foreignKey:WorkflowID" json:"statuses"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// WorkflowStatus is one status of a workflow. Tasks store its key as their status
+// and its category next to it.
+type WorkflowStatus struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	WorkflowID uint   `gorm:"not null;uniqueIndex:idx_workflow_statuses_key" json:"-"`
+	Key        string `gorm:"type:varchar(20);not null;uniqueIndex:idx_workflow_statuses_key" json:"key" example:"review"`
+	Name       string `gorm:"type:text;not null" json:"name" example:"In review"`
+	Category   string `gorm:"type:varchar(20);not null" json:"category" example:"in_progress"`
+	Position   int    `gorm:"not null" json:"position" example:"3"`
+}
+
+// DefaultStatuses are the built-in statuses, used by tasks outside any project and
+// by projects without a workflow of their own
+func DefaultStatuses() []WorkflowStatus {
+	return []WorkflowStatus{
+		{Key: taskModel.StatusPending, Name: "Pending", Category: taskModel.CategoryTodo, Position: 1},
+		{Key: taskModel.StatusInProgress, Name: "In progress", Category: taskModel.CategoryInProgress, Position: 2},
+		{Key: taskModel.StatusCompleted, Name: "Completed", Category: taskModel.CategoryDone, Position: 3},
+	}
+}
+
+// WorkflowRequest is the request model for setting the workflow of a project,
+// statuses in board order
+type WorkflowRequest struct {
+	Statuses []WorkflowStatusRequest `json:"statuses" validate:"required,min=2,max=20,dive"`
+}
+
+// WorkflowStatusRequest is one status of a WorkflowRequest
+type WorkflowStatusRequest struct {
+	Key      string `json:"key" example:"review" validate:"required,max=20"`
+	Name     string `json:"name" example:"In review" validate:"required,max=50"`
+	Category string `json:"category" example:"in_progress" validate:"required,oneof=todo in_progress done"`
+}
+
+// WorkflowResponse is the response model for the workflow of a project
+type WorkflowResponse struct {
+	Custom   bool                     `json:"custom" example:"true"` // false while the built-in statuses apply
+	Statuses []WorkflowStatusResponse `json:"statuses"`
+}
+
+// WorkflowStatusResponse is one status of a WorkflowResponse
+type WorkflowStatusResponse struct {
+	Key      string `json:"key" example:"review"`
+	Name     string `json:"name" example:"In review"`
+	Category string `json:"category" example:"in_progress"`
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"errors"
+	"mymodule/internal/project/model"
+	"mymodule/internal/project/usecase"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormProjectRepository struct {
+	db *gorm.DB
+}
+
+func NewGormProjectRepository(db *gorm.DB) usecase.ProjectRepository {
+	return &GormProjectRepository{db: db}
+}
+
+func (r *GormProjectRepository) Save(project *model.Project) error {
+	if err := r.db.Create(project).Error; err != nil {
+		logger.Log.WithField("userID", project.UserID).Error("Failed to save project")
+		return err
+	}
+	logger.Log.WithField("projectID", project.ID).Info("Project saved successfully")
+	return nil
+}
+
+func (r *GormProjectRepository) FindByUser(userID uint, includeArchived bool) (*[]model.Project, error) {
+	var projects []model.Project
+	query := r.db.Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	if err := query.Order("is_inbox DESC, name").Find(&projects).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find projects by user ID")
+		return nil, err
+	}
+	return &projects, nil
+}
+
+func (r *GormProjectRepository) FindByIDAndUser(projectID, userID uint) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.Where("id = ? AND user_id = ?", projectID, userID).First(&project).Error; err != nil {
+		logger.Log.WithFields(map[string]interface{}{"projectID": projectID, "userID": userID}).Error("Failed to find project by ID and user ID")
+		return nil, err
+	}
+	return &project, nil
+}
+
+// FindInbox returns nil without an error when the user has no Inbox yet
+func (r *GormProjectRepository) FindInbox(userID uint) (*model.Project, error) {
+	var project model.Project
+	result := r.db.Where("user_id = ? AND is_inbox = ?", userID, true).First(&project)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		logger.Log.WithField("userID", userID).Error("Database error finding Inbox project")
+		return nil, result.Error
+	}
+	return &project, nil
+}
+
+func (r *GormProjectRepository) Update(project *model.Project) error {
+	if err := r.db.Save(project).Error; err != nil {
+		logger.Log.WithField("projectID", project.ID).Error("Failed to update project")
+		return err
+	}
+	logger.Log.WithField("projectID", project.ID).Info("Project updated successfully")
+	return nil
+}
+
+// DeleteWithTasks soft deletes the project and every task in it
+func (r *GormProjectRepository) DeleteWithTasks(projectID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", projectID).Delete(&taskModel.Task{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Project{}, projectID).Error
+	})
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to delete project with tasks")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Project and tasks deleted")
+	return nil
+}
+
+// DeleteMovingTasks soft deletes the project after moving its tasks to targetID,
+// which always uses the built-in statuses
+func (r *GormProjectRepository) DeleteMovingTasks(projectID, targetID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&taskModel.Task{}).Where("project_id = ?", projectID).
+			Update("project_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := remapStatuses(tx, targetID, model.DefaultStatuses()); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Project{}, projectID).Error
+	})
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to delete project moving tasks")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Project deleted, tasks moved")
+	return nil
+}
+
+func (r *GormProjectRepository) FindWorkflow(projectID uint) (*model.Workflow, error) {
+	var workflow model.Workflow
+	err := r.db.Preload("Statuses", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("project_id = ?", projectID).First(&workflow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// SaveWorkflow replaces the statuses of the project's workflow, creating it the
+// first time, and moves the project's tasks onto them
+func (r *GormProjectRepository) SaveWorkflow(projectID uint, statuses []model.WorkflowStatus) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		workflow := model.Workflow{ProjectID: projectID}
+		if err := tx.Where("project_id = ?", projectID).FirstOrCreate(&workflow).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", workflow.ID).Delete(&model.WorkflowStatus{}).Error; err != nil {
+			return err
+		}
+		for i := range statuses {
+			statuses[i].ID = 0
+			statuses[i].WorkflowID = workflow.ID
+		}
+		if err := tx.Create(&statuses).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&workflow).Update("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return remapStatuses(tx, projectID, statuses)
+	})
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to save workflow")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Workflow saved")
+	return nil
+}
+
+// DeleteWorkflow drops the project's workflow, moving its tasks back onto the
+// built-in statuses. A project without one is left as it is.
+func (r *GormProjectRepository) DeleteWorkflow(projectID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var workflow model.Workflow
+		if err := tx.Where("project_id = ?", projectID).First(&workflow).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", workflow.ID).Delete(&model.WorkflowStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&workflow).Error; err != nil {
+			return err
+		}
+		return remapStatuses(tx, projectID, model.DefaultStatuses())
+	})
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to delete workflow")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Workflow deleted")
+	return nil
+}
+
+// remapStatuses brings the tasks of a project, trashed ones included, in line with
+// statuses: a status that changed category takes its tasks along, and tasks in a
+// status that is gone move to the first status of their category, the first todo
+// status when the category has none. Overdue tasks stay overdue.
+func remapStatuses(tx *gorm.DB, projectID uint, statuses []model.WorkflowStatus) error {
+	tasks := func() *gorm.DB {
+		return tx.Unscoped().Model(&taskModel.Task{}).Where("project_id = ?", projectID)
+	}
+
+	keys := []string{taskModel.StatusOverdue}
+	first := map[string]string{}
+	for _, s := range statuses {
+		keys = append(keys, s.Key)
+		if _, ok := first[s.Category]; !ok {
+			first[s.Category] = s.Key
+		}
+		if err := tasks().Where("status = ? AND status_category <> ?", s.Key, s.Category).
+			Update("status_category", s.Category).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, category := range []string{taskModel.CategoryTodo, taskModel.CategoryInProgress, taskModel.CategoryDone} {
+		target, ok := first[category]
+		targetCategory := category
+		if !ok {
+			target, targetCategory = first[taskModel.CategoryTodo], taskModel.CategoryTodo
+		}
+		if err := tasks().Where("status_category = ? AND status NOT IN ?", category, keys).
+			Updates(map[string]interface{}{"status": target, "status_category": targetCategory}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package repository_test
+
+import (
+	"log"
+	"mymodule/internal/project/model"
+	"mymodule/internal/project/repository"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Project{}, &taskModel.Task{}, &model.Workflow{}, &model.WorkflowStatus{}); err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestFindProjects(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormProjectRepository(db)
+
+	archivedAt := time.Now()
+	for _, p := range []model.Project{
+		{Name: "Website", UserID: 1},
+		{Name: "Archive me", UserID: 1, ArchivedAt: &archivedAt},
+		{Name: model.InboxName, UserID: 1, IsInbox: true},
+		{Name: "Someone else's", UserID: 2},
+	} {
+		p := p
+		if err := repo.Save(&p); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	active, err := repo.FindByUser(1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*active) != 2 || !(*active)[0].IsInbox {
+		t.Fatalf("expected Inbox first and the archived project hidden, got %+v", *active)
+	}
+
+	all, _ := repo.FindByUser(1, true)
+	if len(*all) != 3 {
+		t.Fatalf("expected 3 projects including archived, got %d", len(*all))
+	}
+
+	inbox, err := repo.FindInbox(1)
+	if err != nil || inbox == nil || inbox.Name != model.InboxName {
+		t.Fatalf("expected the Inbox, got %+v, %v", inbox, err)
+	}
+	if none, err := repo.FindInbox(2); err != nil || none != nil {
+		t.Fatalf("expected no Inbox for user 2, got %+v, %v", none, err)
+	}
+}
+
+func TestDeleteProjectTasks(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormProjectRepository(db)
+
+	project := model.Project{Name: "Website", UserID: 1}
+	inbox := model.Project{Name: model.InboxName, UserID: 1, IsInbox: true}
+	repo.Save(&project)
+	repo.Save(&inbox)
+	tasks := []taskModel.Task{
+		{Title: "Design", UserID: 1, ProjectID: &project.ID},
+		{Title: "Build", UserID: 1, ProjectID: &project.ID},
+		{Title: "Loose", UserID: 1},
+	}
+	for i := range tasks {
+		if err := db.Create(&tasks[i]).Error; err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	t.Run("MoveToInbox", func(t *testing.T) {
+		if err := repo.DeleteMovingTasks(project.ID, inbox.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var moved int64
+		db.Model(&taskModel.Task{}).Where("project_id = ?", inbox.ID).Count(&moved)
+		if moved != 2 {
+			t.Fatalf("expected 2 tasks moved to the Inbox, got %d", moved)
+		}
+		if _, err := repo.FindByIDAndUser(project.ID, 1); err == nil {
+			t.Fatalf("expected the project to be deleted")
+		}
+	})
+
+	t.Run("CascadeDelete", func(t *testing.T) {
+		if err := repo.DeleteWithTasks(inbox.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var left []taskModel.Task
+		db.Find(&left)
+		if len(left) != 1 || left[0].Title != "Loose" {
+			t.Fatalf("expected only the task outside projects to remain, got %+v", left)
+		}
+		var trashed int64
+		db.Unscoped().Model(&taskModel.Task{}).Where("project_id = ? AND deleted_at IS NOT NULL", inbox.ID).Count(&trashed)
+		if trashed != 2 {
+			t.Fatalf("expected 2 soft deleted tasks, got %d", trashed)
+		}
+	})
+}
+
+func TestWorkflow(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormProjectRepository(db)
+
+	project := model.Project{Name: "Board", UserID: 1}
+	repo.Save(&project)
+	tasks := []taskModel.Task{
+		{Title: "Waiting", UserID: 1, ProjectID: &project.ID, Status: taskModel.StatusPending, StatusCategory: taskModel.CategoryTodo},
+		{Title: "Started", UserID: 1, ProjectID: &project.ID, Status: taskModel.StatusInProgress, StatusCategory: taskModel.CategoryInProgress},
+		{Title: "Finished", UserID: 1, ProjectID: &project.ID, Status: taskModel.StatusCompleted, StatusCategory: taskModel.CategoryDone},
+		{Title: "Late", UserID: 1, ProjectID: &project.ID, Status: taskModel.StatusOverdue, StatusCategory: taskModel.CategoryTodo},
+	}
+	db.Create(&tasks)
+
+	if _, err := repo.FindWorkflow(project.ID); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected no workflow yet, got %v", err)
+	}
+
+	statuses := []model.WorkflowStatus{
+		{Key: "backlog", Name: "Backlog", Category: taskModel.CategoryTodo, Position: 1},
+		{Key: "doing", Name: "Doing", Category: taskModel.CategoryInProgress, Position: 2},
+		{Key: "done", Name: "Done", Category: taskModel.CategoryDone, Position: 3},
+		// A built-in status that stays in the workflow holds on to its tasks
+		{Key: taskModel.StatusCompleted, Name: "Shipped", Category: taskModel.CategoryDone, Position: 4},
+	}
+	if err := repo.SaveWorkflow(project.ID, statuses); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	workflow, err := repo.FindWorkflow(project.ID)
+	if err != nil || len(workflow.Statuses) != 4 || workflow.Statuses[0].Key != "backlog" {
+		t.Fatalf("expected the saved statuses in order, got %+v, %v", workflow, err)
+	}
+
+	want := map[string]string{"Waiting": "backlog", "Started": "doing", "Finished": taskModel.StatusCompleted, "Late": taskModel.StatusOverdue}
+	var got []taskModel.Task
+	db.Where("project_id = ?", project.ID).Find(&got)
+	for _, task := range got {
+		if task.Status != want[task.Title] {
+			t.Errorf("expected %s in %s, got %s", task.Title, want[task.Title], task.Status)
+		}
+	}
+
+	// Back on the built-in statuses every task lands in the first one of its category
+	if err := repo.DeleteWorkflow(project.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindWorkflow(project.ID); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected the workflow gone, got %v", err)
+	}
+	want = map[string]string{"Waiting": taskModel.StatusPending, "Started": taskModel.StatusInProgress, "Finished": taskModel.StatusCompleted, "Late": taskModel.StatusOverdue}
+	db.Where("project_id = ?", project.ID).Find(&got)
+	for _, task := range got {
+		if task.Status != want[task.Title] {
+			t.Errorf("expected %s back in %s, got %s", task.Title, want[task.Title], task.Status)
+		}
+	}
+}
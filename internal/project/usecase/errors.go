@@ -0,0 +1,13 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrInboxReserved     = errors.New("the name Inbox is reserved")
+	ErrInboxProtected    = errors.New("the Inbox project can not be renamed or archived")
+	ErrInvalidDeleteMode = errors.New("choose what happens to the project's tasks: tasks=delete or tasks=move")
+	ErrMoveIntoSelf      = errors.New("tasks of the Inbox can not be moved into the Inbox")
+	ErrInboxWorkflow     = errors.New("the Inbox project always uses the built-in statuses")
+	ErrInvalidWorkflow   = errors.New("invalid workflow")
+)
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"mymodule/internal/project/model"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeleteMode decides what happens to the tasks of a deleted project
+type DeleteMode string
+
+const (
+	DeleteTasks      DeleteMode = "delete"
+	MoveTasksToInbox DeleteMode = "move"
+)
+
+type ProjectRepository interface {
+	Save(project *model.Project) error
+	FindByUser(userID uint, includeArchived bool) (*[]model.Project, error)
+	FindByIDAndUser(projectID, userID uint) (*model.Project, error)
+	FindInbox(userID uint) (*model.Project, error)
+	Update(project *model.Project) error
+	DeleteWithTasks(projectID uint) error
+	DeleteMovingTasks(projectID, targetID uint) error
+	FindWorkflow(projectID uint) (*model.Workflow, error)
+	SaveWorkflow(projectID uint, statuses []model.WorkflowStatus) error
+	DeleteWorkflow(projectID uint) error
+}
+
+type ProjectUsecase interface {
+	Create(project model.Project) (*model.Project, error)
+	GetByUser(userID uint, includeArchived bool) (*[]model.Project, error)
+	Rename(projectID, userID uint, name string) error
+	Archive(projectID, userID uint) error
+	Unarchive(projectID, userID uint) error
+	DeleteProject(projectID, userID uint, mode DeleteMode) error
+	GetWorkflow(projectID, userID uint) (*model.Workflow, error)
+	SetWorkflow(projectID, userID uint, statuses []model.WorkflowStatus) error
+	ResetWorkflow(projectID, userID uint) error
+}
+
+type ProjectusecaseImpl struct {
+	repo ProjectRepository
+}
+
+func NewProjectUsecase(repo ProjectRepository) ProjectUsecase {
+	return &ProjectusecaseImpl{
+		repo: repo,
+	}
+}
+
+func (uc *ProjectusecaseImpl) Create(project model.Project) (*model.Project, error) {
+	if isInboxName(project.Name) {
+		return nil, ErrInboxReserved
+	}
+
+	if err := uc.repo.Save(&project); err != nil {
+		logger.Log.WithField("userID", project.UserID).Error("Failed to create project")
+		return nil, err
+	}
+
+	logger.Log.WithField("userID", project.UserID).Info("Project created successfully")
+	return &project, nil
+}
+
+func (uc *ProjectusecaseImpl) GetByUser(userID uint, includeArchived bool) (*[]model.Project, error) {
+	projects, err := uc.repo.FindByUser(userID, includeArchived)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get projects by user")
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (uc *ProjectusecaseImpl) Rename(projectID, userID uint, name string) error {
+	project, err := uc.findOwned(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project.IsInbox {
+		return ErrInboxProtected
+	}
+	if isInboxName(name) {
+		return ErrInboxReserved
+	}
+
+	project.Name = name
+	if err := uc.repo.Update(project); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to rename project")
+		return err
+	}
+
+	logger.Log.WithField("projectID", projectID).Info("Project renamed")
+	return nil
+}
+
+func (uc *ProjectusecaseImpl) Archive(projectID, userID uint) error {
+	project, err := uc.findOwned(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project.IsInbox {
+		return ErrInboxProtected
+	}
+	if project.ArchivedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	project.ArchivedAt = &now
+	if err := uc.repo.Update(project); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to archive project")
+		return err
+	}
+
+	logger.Log.WithField("projectID", projectID).Info("Project archived")
+	return nil
+}
+
+func (uc *ProjectusecaseImpl) Unarchive(projectID, userID uint) error {
+	project, err := uc.findOwned(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project.ArchivedAt == nil {
+		return nil
+	}
+
+	project.ArchivedAt = nil
+	if err := uc.repo.Update(project); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to unarchive project")
+		return err
+	}
+
+	logger.Log.WithField("projectID", projectID).Info("Project unarchived")
+	return nil
+}
+
+func (uc *ProjectusecaseImpl) DeleteProject(projectID, userID uint, mode DeleteMode) error {
+	if mode != DeleteTasks && mode != MoveTasksToInbox {
+		return ErrInvalidDeleteMode
+	}
+
+	project, err := uc.findOwned(projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	if mode == DeleteTasks {
+		if err := uc.repo.DeleteWithTasks(project.ID); err != nil {
+			logger.Log.WithField("projectID", projectID).Error("Failed to delete project with its tasks")
+			return err
+		}
+		logger.Log.WithField("projectID", projectID).Info("Project deleted with its tasks")
+		return nil
+	}
+
+	if project.IsInbox {
+		return ErrMoveIntoSelf
+	}
+	inbox, err := uc.inbox(userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteMovingTasks(project.ID, inbox.ID); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to delete project moving its tasks")
+		return err
+	}
+
+	logger.Log.WithField("projectID", projectID).Info("Project deleted, tasks moved to Inbox")
+	return nil
+}
+
+// GetWorkflow returns the statuses the project's tasks move through, the built-in
+// ones (without an ID) unless the project has a workflow of its own
+func (uc *ProjectusecaseImpl) GetWorkflow(projectID, userID uint) (*model.Workflow, error) {
+	if _, err := uc.findOwned(projectID, userID); err != nil {
+		return nil, err
+	}
+	workflow, err := uc.repo.FindWorkflow(projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.Workflow{ProjectID: projectID, Statuses: model.DefaultStatuses()}, nil
+	}
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to find project workflow")
+		return nil, err
+	}
+	return workflow, nil
+}
+
+// SetWorkflow gives the project its own statuses. Tasks in a status the workflow
+// no longer has move to its first status of the same category.
+func (uc *ProjectusecaseImpl) SetWorkflow(projectID, userID uint, statuses []model.WorkflowStatus) error {
+	project, err := uc.findOwned(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project.IsInbox {
+		return ErrInboxWorkflow
+	}
+	if err := validateWorkflow(statuses); err != nil {
+		logger.Log.WithField("projectID", projectID).Warn("Invalid workflow: ", err)
+		return err
+	}
+
+	if err := uc.repo.SaveWorkflow(projectID, statuses); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to save project workflow")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Project workflow saved")
+	return nil
+}
+
+// ResetWorkflow puts the project back on the built-in statuses, its tasks keep
+// their category
+func (uc *ProjectusecaseImpl) ResetWorkflow(projectID, userID uint) error {
+	if _, err := uc.findOwned(projectID, userID); err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteWorkflow(projectID); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to reset project workflow")
+		return err
+	}
+	logger.Log.WithField("projectID", projectID).Info("Project workflow reset")
+	return nil
+}
+
+// Status keys end up in URLs and filters, so they stay short and plain
+var statusKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// validateWorkflow wants unique, well-formed keys other than overdue, which the
+// system sets on its own, and at least one status to start and one to finish in
+func validateWorkflow(statuses []model.WorkflowStatus) error {
+	seen := map[string]bool{}
+	categories := map[string]bool{}
+	for _, s := range statuses {
+		switch {
+		case !statusKey.MatchString(s.Key):
+			return fmt.Errorf("%w: status key %q must be lowercase letters, digits and underscores", ErrInvalidWorkflow, s.Key)
+		case s.Key == taskModel.StatusOverdue:
+			return fmt.Errorf("%w: %s is reserved", ErrInvalidWorkflow, s.Key)
+		case seen[s.Key]:
+			return fmt.Errorf("%w: status %s appears twice", ErrInvalidWorkflow, s.Key)
+		}
+		seen[s.Key] = true
+		categories[s.Category] = true
+	}
+	if !categories[taskModel.CategoryTodo] || !categories[taskModel.CategoryDone] {
+		return fmt.Errorf("%w: it needs a status in the todo and in the done category", ErrInvalidWorkflow)
+	}
+	return nil
+}
+
+// inbox returns the user's Inbox project, creating it the first time it is needed
+func (uc *ProjectusecaseImpl) inbox(userID uint) (*model.Project, error) {
+	inbox, err := uc.repo.FindInbox(userID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find Inbox project")
+		return nil, err
+	}
+	if inbox != nil {
+		return inbox, nil
+	}
+
+	inbox = &model.Project{Name: model.InboxName, UserID: userID, IsInbox: true}
+	if err := uc.repo.Save(inbox); err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to create Inbox project")
+		return nil, err
+	}
+	return inbox, nil
+}
+
+func (uc *ProjectusecaseImpl) findOwned(projectID, userID uint) (*model.Project, error) {
+	project, err := uc.repo.FindByIDAndUser(projectID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("projectID", projectID).Warn("Project not found for this user")
+			return nil, ErrProjectNotFound
+		}
+		logger.Log.WithField("projectID", projectID).Error("DB error when finding project")
+		return nil, err
+	}
+	return project, nil
+}
+
+func isInboxName(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), model.InboxName)
+}
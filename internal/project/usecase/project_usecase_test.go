@@ -0,0 +1,278 @@
+package usecase_test
+
+import (
+	"mymodule/internal/project/model"
+	"mymodule/internal/project/usecase"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) Save(project *model.Project) error {
+	args := m.Called(project)
+	if project.ID == 0 {
+		project.ID = 99
+	}
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) FindByUser(userID uint, includeArchived bool) (*[]model.Project, error) {
+	args := m.Called(userID, includeArchived)
+	return args.Get(0).(*[]model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) FindByIDAndUser(projectID, userID uint) (*model.Project, error) {
+	args := m.Called(projectID, userID)
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) FindInbox(userID uint) (*model.Project, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) Update(project *model.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteWithTasks(projectID uint) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteMovingTasks(projectID, targetID uint) error {
+	args := m.Called(projectID, targetID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) FindWorkflow(projectID uint) (*model.Workflow, error) {
+	args := m.Called(projectID)
+	return args.Get(0).(*model.Workflow), args.Error(1)
+}
+
+func (m *MockProjectRepository) SaveWorkflow(projectID uint, statuses []model.WorkflowStatus) error {
+	args := m.Called(projectID, statuses)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteWorkflow(projectID uint) error {
+	args := m.Called(projectID)
+	return args.Error(0)
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func TestCreateProject(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("Save", mock.AnythingOfType("*model.Project")).Return(nil)
+
+		created, err := projectUC.Create(model.ToProject(model.CreateProjectRequest{Name: "Website"}, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "Website", created.Name)
+		assert.False(t, created.IsInbox)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InboxReserved", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		created, err := projectUC.Create(model.ToProject(model.CreateProjectRequest{Name: " inbox "}, 1))
+		assert.Nil(t, created)
+		assert.ErrorIs(t, err, usecase.ErrInboxReserved)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestRenameAndArchiveProject(t *testing.T) {
+	projectID := uint(3)
+	userID := uint(1)
+
+	t.Run("Rename", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, Name: "Old", UserID: userID}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *model.Project) bool { return p.Name == "New" })).Return(nil)
+
+		assert.NoError(t, projectUC.Rename(projectID, userID, "New"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return((*model.Project)(nil), gorm.ErrRecordNotFound)
+
+		assert.ErrorIs(t, projectUC.Rename(projectID, userID, "New"), usecase.ErrProjectNotFound)
+	})
+
+	t.Run("InboxCannotBeArchived", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, Name: model.InboxName, IsInbox: true}, nil)
+
+		assert.ErrorIs(t, projectUC.Archive(projectID, userID), usecase.ErrInboxProtected)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("ArchiveThenUnarchive", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		project := &model.Project{ID: projectID, Name: "Website", UserID: userID}
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(project, nil)
+		mockRepo.On("Update", project).Return(nil)
+
+		assert.NoError(t, projectUC.Archive(projectID, userID))
+		assert.NotNil(t, project.ArchivedAt)
+		assert.NoError(t, projectUC.Unarchive(projectID, userID))
+		assert.Nil(t, project.ArchivedAt)
+		mockRepo.AssertNumberOfCalls(t, "Update", 2)
+	})
+}
+
+func TestDeleteProject(t *testing.T) {
+	projectID := uint(3)
+	userID := uint(1)
+
+	t.Run("ModeRequired", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		err := projectUC.DeleteProject(projectID, userID, "")
+		assert.ErrorIs(t, err, usecase.ErrInvalidDeleteMode)
+		mockRepo.AssertNotCalled(t, "FindByIDAndUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CascadeDelete", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("DeleteWithTasks", projectID).Return(nil)
+
+		assert.NoError(t, projectUC.DeleteProject(projectID, userID, usecase.DeleteTasks))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MoveToExistingInbox", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("FindInbox", userID).Return(&model.Project{ID: 5, IsInbox: true}, nil)
+		mockRepo.On("DeleteMovingTasks", projectID, uint(5)).Return(nil)
+
+		assert.NoError(t, projectUC.DeleteProject(projectID, userID, usecase.MoveTasksToInbox))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MoveCreatesInbox", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		archivedAt := time.Now()
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, UserID: userID, ArchivedAt: &archivedAt}, nil)
+		mockRepo.On("FindInbox", userID).Return((*model.Project)(nil), nil)
+		mockRepo.On("Save", mock.MatchedBy(func(p *model.Project) bool {
+			return p.IsInbox && p.Name == model.InboxName && p.UserID == userID
+		})).Return(nil)
+		mockRepo.On("DeleteMovingTasks", projectID, uint(99)).Return(nil)
+
+		assert.NoError(t, projectUC.DeleteProject(projectID, userID, usecase.MoveTasksToInbox))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InboxCannotMoveIntoItself", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", projectID, userID).Return(&model.Project{ID: projectID, IsInbox: true}, nil)
+
+		assert.ErrorIs(t, projectUC.DeleteProject(projectID, userID, usecase.MoveTasksToInbox), usecase.ErrMoveIntoSelf)
+		mockRepo.AssertNotCalled(t, "DeleteMovingTasks", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWorkflow(t *testing.T) {
+	userID := uint(1)
+	project := &model.Project{ID: 5, Name: "Website", UserID: userID}
+	statuses := []model.WorkflowStatus{
+		{Key: "backlog", Name: "Backlog", Category: "todo", Position: 1},
+		{Key: "doing", Name: "Doing", Category: "in_progress", Position: 2},
+		{Key: "review", Name: "Review", Category: "in_progress", Position: 3},
+		{Key: "done", Name: "Done", Category: "done", Position: 4},
+	}
+
+	t.Run("BuiltinUntilSet", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return(project, nil)
+		mockRepo.On("FindWorkflow", uint(5)).Return((*model.Workflow)(nil), gorm.ErrRecordNotFound)
+
+		workflow, err := projectUC.GetWorkflow(5, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.DefaultStatuses(), workflow.Statuses)
+		assert.False(t, model.ToWorkflowResponse(*workflow).Custom)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return(project, nil)
+		mockRepo.On("SaveWorkflow", uint(5), statuses).Return(nil)
+
+		err := projectUC.SetWorkflow(5, userID, statuses)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, invalid := range map[string][]model.WorkflowStatus{
+			"BadKey":    {{Key: "In Review", Category: "todo"}, {Key: "done", Category: "done"}},
+			"Reserved":  {{Key: "overdue", Category: "todo"}, {Key: "done", Category: "done"}},
+			"Duplicate": {{Key: "todo", Category: "todo"}, {Key: "todo", Category: "done"}},
+			"NoDone":    {{Key: "backlog", Category: "todo"}, {Key: "doing", Category: "in_progress"}},
+		} {
+			mockRepo := new(MockProjectRepository)
+			projectUC := usecase.NewProjectUsecase(mockRepo)
+			mockRepo.On("FindByIDAndUser", uint(5), userID).Return(project, nil)
+
+			err := projectUC.SetWorkflow(5, userID, invalid)
+
+			assert.ErrorIs(t, err, usecase.ErrInvalidWorkflow, name)
+			mockRepo.AssertNotCalled(t, "SaveWorkflow", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("InboxKeepsBuiltin", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		projectUC := usecase.NewProjectUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(6), userID).Return(&model.Project{ID: 6, Name: model.InboxName, UserID: userID, IsInbox: true}, nil)
+
+		err := projectUC.SetWorkflow(6, userID, statuses)
+
+		assert.ErrorIs(t, err, usecase.ErrInboxWorkflow)
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/reminder/model"
+	"mymodule/internal/reminder/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpReminderhandler struct {
+	usecase usecase.ReminderUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewReminderHandler(app *fiber.App, usecase usecase.ReminderUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpReminderhandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	reminder := app.Group("/task/:id/reminders", middleware.Middleware(token))
+	reminder.Post("/", handler.Create)
+	reminder.Get("/", handler.GetRemindersByTask)
+	reminder.Delete("/:reminderId", handler.DeleteReminder)
+}
+
+func (h *HttpReminderhandler) Create(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.CreateReminderRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid reminder request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	reminder, err := h.usecase.Create(model.ToReminder(input, uint(taskID), userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.ToReminderResponse(*reminder))
+}
+
+func (h *HttpReminderhandler) GetRemindersByTask(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	reminders, err := h.usecase.GetByTask(uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToReminderResponseList(*reminders))
+}
+
+func (h *HttpReminderhandler) DeleteReminder(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	reminderID, err := strconv.Atoi(c.Params("reminderId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid reminder ID"})
+	}
+
+	if err := h.usecase.DeleteReminder(uint(reminderID), uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "reminder deleted"})
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound), errors.Is(err, usecase.ErrReminderNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrReminderTime),
+		errors.Is(err, usecase.ErrNoDueDate),
+		errors.Is(err, usecase.ErrReminderInPast):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrReadOnly):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+func ToReminder(req CreateReminderRequest, taskID, userID uint) Reminder {
+	reminder := Reminder{
+		TaskID:        taskID,
+		UserID:        userID,
+		OffsetMinutes: req.OffsetMinutes,
+	}
+	if req.RemindAt != nil {
+		reminder.RemindAt = *req.RemindAt
+	}
+	return reminder
+}
+
+func ToReminderResponse(r Reminder) ReminderResponse {
+	return ReminderResponse{
+		ID:            r.ID,
+		TaskID:        r.TaskID,
+		RemindAt:      r.RemindAt,
+		OffsetMinutes: r.OffsetMinutes,
+		SentAt:        r.SentAt,
+	}
+}
+
+func ToReminderResponseList(reminders []Reminder) []ReminderResponse {
+	res := make([]ReminderResponse, 0, len(reminders))
+	for _, r := range reminders {
+		res = append(res, ToReminderResponse(r))
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Reminder is the DB model for a notification about a task. Relative reminders
+// keep their offset so they follow the task's due date when it moves.
+type Reminder struct {
+	ID            uint       `gorm:"primaryKey" json:"id" example:"1"`
+	TaskID        uint       `gorm:"not null;index" json:"task_id" example:"1"`
+	UserID        uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	RemindAt      time.Time  `gorm:"not null;index" json:"remind_at" example:"2025-08-10T14:00:00Z"`
+	OffsetMinutes *int       `gorm:"default:null" json:"offset_minutes,omitempty" example:"60"`
+	SentAt        *time.Time `gorm:"default:null" json:"sent_at,omitempty"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// DueReminder is a reminder ready to fire together with what the notification needs
+type DueReminder struct {
+	Reminder
+	Title   string
+	DueDate *time.Time
+}
+
+// CreateReminderRequest is the request model for a reminder, either at a fixed
+// time or a number of minutes before the task's due date
+type CreateReminderRequest struct {
+	RemindAt      *time.Time `json:"remind_at,omitempty" example:"2025-08-10T14:00:00Z"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" example:"60" validate:"omitempty,min=0,max=525600"`
+}
+
+// ReminderResponse is the response model for a reminder
+type ReminderResponse struct {
+	ID            uint       `json:"id" example:"1"`
+	TaskID        uint       `json:"task_id" example:"1"`
+	RemindAt      time.Time  `json:"remind_at" example:"2025-08-10T14:00:00Z"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" example:"60"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// RemindAtFor is when a reminder offsetMinutes before dueDate fires
+func RemindAtFor(dueDate time.Time, offsetMinutes int) time.Time {
+	return dueDate.Add(-time.Duration(offsetMinutes) * time.Minute)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"mymodule/internal/reminder/model"
+	"mymodule/internal/reminder/usecase"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewGormReminderRepository(db *gorm.DB) usecase.ReminderRepository {
+	return &GormReminderRepository{db: db}
+}
+
+func (r *GormReminderRepository) Save(reminder *model.Reminder) error {
+	if err := r.db.Create(reminder).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(reminder.TaskID, reminder.UserID)).Error("Failed to save reminder")
+		return err
+	}
+	logger.Log.WithField("reminderID", reminder.ID).Info("Reminder saved successfully")
+	return nil
+}
+
+func (r *GormReminderRepository) FindByTask(taskID, userID uint) (*[]model.Reminder, error) {
+	var reminders []model.Reminder
+	if err := r.db.Where("task_id = ? AND user_id = ?", taskID, userID).Order("remind_at, id").Find(&reminders).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find reminders by task")
+		return nil, err
+	}
+	return &reminders, nil
+}
+
+func (r *GormReminderRepository) FindByIDAndTask(reminderID, taskID, userID uint) (*model.Reminder, error) {
+	var reminder model.Reminder
+	if err := r.db.Where("id = ? AND task_id = ? AND user_id = ?", reminderID, taskID, userID).First(&reminder).Error; err != nil {
+		logger.Log.WithField("reminderID", reminderID).Error("Failed to find reminder")
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// FindTask returns the task with taskID if userID can see it
+func (r *GormReminderRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	var task taskModel.Task
+	if err := r.db.Scopes(taskModel.VisibleTo(userID)).Where("id = ?", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task for reminder")
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindRole returns the role of userID in a workspace, empty when not a member
+func (r *GormReminderRepository) FindRole(workspaceID, userID uint) (string, error) {
+	var member workspaceModel.Member
+	result := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Limit(1).Find(&member)
+	if result.Error != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace role")
+		return "", result.Error
+	}
+	return member.Role, nil
+}
+
+func (r *GormReminderRepository) Delete(reminderID uint) error {
+	if err := r.db.Delete(&model.Reminder{}, reminderID).Error; err != nil {
+		logger.Log.WithField("reminderID", reminderID).Error("Failed to delete reminder")
+		return err
+	}
+	return nil
+}
+
+// FindDue returns unsent reminders due by now, oldest first. Reminders of deleted
+// or finished tasks never fire, nor do those that failed too often.
+func (r *GormReminderRepository) FindDue(now time.Time, limit int) (*[]model.DueReminder, error) {
+	var due []model.DueReminder
+	err := r.db.Table("reminders").
+		Select("reminders.*, tasks.title, tasks.due_date").
+		Joins("JOIN tasks ON tasks.id = reminders.task_id").
+		Where("reminders.sent_at IS NULL AND reminders.remind_at <= ? AND reminders.attempts < ?", now.UTC(), usecase.MaxAttempts).
+		Where("tasks.deleted_at IS NULL AND tasks.status_category <> ?", taskModel.CategoryDone).
+		Order("reminders.remind_at, reminders.id").
+		Limit(limit).
+		Scan(&due).Error
+	if err != nil {
+		logger.Log.Error("Failed to find due reminders: ", err)
+		return nil, err
+	}
+	return &due, nil
+}
+
+func (r *GormReminderRepository) MarkSent(reminderID uint, sentAt time.Time) error {
+	return r.db.Model(&model.Reminder{}).Where("id = ?", reminderID).
+		Updates(map[string]interface{}{"sent_at": sentAt, "last_error": ""}).Error
+}
+
+func (r *GormReminderRepository) MarkFailed(reminderID uint, reason string) error {
+	return r.db.Model(&model.Reminder{}).Where("id = ?", reminderID).
+		Updates(map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "last_error": reason}).Error
+}
+
+// ShiftReminders moves the reminders set relative to a task's due date along with
+// it, re-arming those that now lie ahead
+func (r *GormReminderRepository) ShiftReminders(taskID uint, dueDate time.Time) error {
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var reminders []model.Reminder
+		if err := tx.Where("task_id = ? AND offset_minutes IS NOT NULL", taskID).Find(&reminders).Error; err != nil {
+			return err
+		}
+		for _, reminder := range reminders {
+			remindAt := model.RemindAtFor(dueDate, *reminder.OffsetMinutes).UTC()
+			changes := map[string]interface{}{"remind_at": remindAt}
+			if remindAt.After(now) {
+				changes["sent_at"] = nil
+				changes["attempts"] = 0
+			}
+			if err := tx.Model(&reminder).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to shift reminders")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"log"
+	"mymodule/internal/reminder/model"
+	"mymodule/internal/reminder/repository"
+	"mymodule/internal/reminder/usecase"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&taskModel.Task{}, &model.Reminder{}); err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestFindDue(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormReminderRepository(db)
+	now := time.Now().UTC()
+
+	open := taskModel.Task{Title: "Open", UserID: 1, Status: "pending"}
+	done := taskModel.Task{Title: "Done", UserID: 1, Status: "completed", StatusCategory: taskModel.CategoryDone}
+	gone := taskModel.Task{Title: "Gone", UserID: 1, Status: "pending"}
+	for _, task := range []*taskModel.Task{&open, &done, &gone} {
+		if err := db.Create(task).Error; err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	db.Delete(&gone)
+
+	sentAt := now.Add(-time.Minute)
+	reminders := []model.Reminder{
+		{TaskID: open.ID, UserID: 1, RemindAt: now.Add(-time.Hour)},
+		{TaskID: open.ID, UserID: 1, RemindAt: now.Add(time.Hour)},
+		{TaskID: open.ID, UserID: 1, RemindAt: now.Add(-time.Hour), SentAt: &sentAt},
+		{TaskID: open.ID, UserID: 1, RemindAt: now.Add(-time.Hour), Attempts: usecase.MaxAttempts},
+		{TaskID: done.ID, UserID: 1, RemindAt: now.Add(-time.Hour)},
+		{TaskID: gone.ID, UserID: 1, RemindAt: now.Add(-time.Hour)},
+	}
+	for i := range reminders {
+		if err := repo.Save(&reminders[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	due, err := repo.FindDue(now, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*due) != 1 || (*due)[0].ID != reminders[0].ID || (*due)[0].Title != "Open" {
+		t.Fatalf("expected only the first reminder to be due, got: %+v", *due)
+	}
+
+	if err := repo.MarkFailed(reminders[0].ID, "timeout"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.MarkSent(reminders[0].ID, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stored model.Reminder
+	db.First(&stored, reminders[0].ID)
+	if stored.SentAt == nil || stored.Attempts != 1 {
+		t.Errorf("expected the reminder sent after one failed attempt, got: %+v", stored)
+	}
+	if due, _ := repo.FindDue(now, 10); len(*due) != 0 {
+		t.Errorf("expected nothing left to send, got: %+v", *due)
+	}
+}
+
+func TestShiftReminders(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormReminderRepository(db)
+	due := time.Now().Add(-2 * time.Hour).UTC()
+	task := taskModel.Task{Title: "Dentist", UserID: 1, DueDate: &due}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	offset := 60
+	sentAt := due.Add(-time.Hour)
+	fixed := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	relative := model.Reminder{TaskID: task.ID, UserID: 1, RemindAt: sentAt, OffsetMinutes: &offset, SentAt: &sentAt, Attempts: 2}
+	absolute := model.Reminder{TaskID: task.ID, UserID: 1, RemindAt: fixed}
+	for _, reminder := range []*model.Reminder{&relative, &absolute} {
+		if err := repo.Save(reminder); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	newDue := time.Now().Add(48 * time.Hour)
+	if err := repo.ShiftReminders(task.ID, newDue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var shifted, kept model.Reminder
+	db.First(&shifted, relative.ID)
+	if !shifted.RemindAt.Equal(newDue.Add(-time.Hour)) || shifted.SentAt != nil || shifted.Attempts != 0 {
+		t.Errorf("expected the relative reminder to follow the due date and re-arm, got: %+v", shifted)
+	}
+	db.First(&kept, absolute.ID)
+	if !kept.RemindAt.Equal(fixed) {
+		t.Errorf("expected the absolute reminder to stay put, got: %+v", kept)
+	}
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrReminderNotFound = errors.New("reminder not found")
+	ErrReminderTime     = errors.New("set exactly one of remind_at or offset_minutes")
+	ErrNoDueDate        = errors.New("a reminder relative to the due date needs a task with a due date")
+	ErrReminderInPast   = errors.New("reminder time is in the past")
+	ErrReadOnly         = errors.New("your role in this workspace only allows viewing tasks")
+)
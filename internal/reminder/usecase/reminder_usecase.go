@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/internal/reminder/model"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReminderRepository interface {
+	Save(reminder *model.Reminder) error
+	FindByTask(taskID, userID uint) (*[]model.Reminder, error)
+	FindByIDAndTask(reminderID, taskID, userID uint) (*model.Reminder, error)
+	FindTask(taskID, userID uint) (*taskModel.Task, error)
+	FindRole(workspaceID, userID uint) (string, error)
+	Delete(reminderID uint) error
+	FindDue(now time.Time, limit int) (*[]model.DueReminder, error)
+	MarkSent(reminderID uint, sentAt time.Time) error
+	MarkFailed(reminderID uint, reason string) error
+	ShiftReminders(taskID uint, dueDate time.Time) error
+}
+
+type ReminderUsecase interface {
+	Create(reminder model.Reminder) (*model.Reminder, error)
+	GetByTask(taskID, userID uint) (*[]model.Reminder, error)
+	DeleteReminder(reminderID, taskID, userID uint) error
+}
+
+type ReminderusecaseImpl struct {
+	repo ReminderRepository
+	now  func() time.Time
+}
+
+func NewReminderUsecase(repo ReminderRepository) ReminderUsecase {
+	return &ReminderusecaseImpl{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Create sets a reminder on a task, reminder.RemindAt is ignored for relative ones
+func (uc *ReminderusecaseImpl) Create(reminder model.Reminder) (*model.Reminder, error) {
+	absolute := !reminder.RemindAt.IsZero()
+	if absolute == (reminder.OffsetMinutes != nil) {
+		return nil, ErrReminderTime
+	}
+
+	task, err := uc.findTask(reminder.TaskID, reminder.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkWritable(task, reminder.UserID); err != nil {
+		return nil, err
+	}
+
+	if !absolute {
+		if task.DueDate == nil {
+			return nil, ErrNoDueDate
+		}
+		reminder.RemindAt = model.RemindAtFor(*task.DueDate, *reminder.OffsetMinutes)
+	}
+	if reminder.RemindAt.Before(uc.now()) {
+		return nil, ErrReminderInPast
+	}
+	reminder.RemindAt = reminder.RemindAt.UTC()
+
+	if err := uc.repo.Save(&reminder); err != nil {
+		logger.Log.WithFields(logger.LogFields(reminder.TaskID, reminder.UserID)).Error("Failed to create reminder")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(reminder.TaskID, reminder.UserID)).Info("Reminder created successfully")
+	return &reminder, nil
+}
+
+func (uc *ReminderusecaseImpl) GetByTask(taskID, userID uint) (*[]model.Reminder, error) {
+	if _, err := uc.findTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	reminders, err := uc.repo.FindByTask(taskID, userID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get reminders")
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (uc *ReminderusecaseImpl) DeleteReminder(reminderID, taskID, userID uint) error {
+	task, err := uc.findTask(taskID, userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkWritable(task, userID); err != nil {
+		return err
+	}
+	reminder, err := uc.repo.FindByIDAndTask(reminderID, taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("reminderID", reminderID).Warn("Reminder not found for this user")
+			return ErrReminderNotFound
+		}
+		return err
+	}
+
+	if err := uc.repo.Delete(reminder.ID); err != nil {
+		logger.Log.WithField("reminderID", reminderID).Error("Failed to delete reminder")
+		return err
+	}
+
+	logger.Log.WithField("reminderID", reminderID).Info("Reminder deleted")
+	return nil
+}
+
+func (uc *ReminderusecaseImpl) findTask(taskID, userID uint) (*taskModel.Task, error) {
+	task, err := uc.repo.FindTask(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for this user")
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// checkWritable refuses changes by userID on a task of a workspace where they
+// may only view, as the task module does
+func (uc *ReminderusecaseImpl) checkWritable(task *taskModel.Task, userID uint) error {
+	if task.WorkspaceID == nil {
+		return nil
+	}
+	role, err := uc.repo.FindRole(*task.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+		logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Viewer tried to change a task")
+		return ErrReadOnly
+	}
+	return nil
+}
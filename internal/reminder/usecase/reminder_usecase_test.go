@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"mymodule/internal/reminder/model"
+	"mymodule/internal/reminder/usecase"
+	taskModel "mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/notifier"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) Save(reminder *model.Reminder) error {
+	args := m.Called(reminder)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) FindByTask(taskID, userID uint) (*[]model.Reminder, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*[]model.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) FindByIDAndTask(reminderID, taskID, userID uint) (*model.Reminder, error) {
+	args := m.Called(reminderID, taskID, userID)
+	return args.Get(0).(*model.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*taskModel.Task), args.Error(1)
+}
+
+func (m *MockReminderRepository) FindRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockReminderRepository) Delete(reminderID uint) error {
+	args := m.Called(reminderID)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) FindDue(now time.Time, limit int) (*[]model.DueReminder, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).(*[]model.DueReminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) MarkSent(reminderID uint, sentAt time.Time) error {
+	args := m.Called(reminderID, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) MarkFailed(reminderID uint, reason string) error {
+	args := m.Called(reminderID, reason)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) ShiftReminders(taskID uint, dueDate time.Time) error {
+	args := m.Called(taskID, dueDate)
+	return args.Error(0)
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func TestCreateReminder(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	due := time.Now().Add(48 * time.Hour)
+	offset := 90
+
+	t.Run("Relative", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID, DueDate: &due}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(r *model.Reminder) bool {
+			return r.RemindAt.Equal(due.Add(-90 * time.Minute))
+		})).Return(nil)
+
+		created, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{OffsetMinutes: &offset}, taskID, userID))
+		assert.NoError(t, err)
+		assert.Equal(t, time.UTC, created.RemindAt.Location())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Absolute", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		at := time.Now().Add(time.Hour)
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Reminder")).Return(nil)
+
+		created, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{RemindAt: &at}, taskID, userID))
+		assert.NoError(t, err)
+		assert.Nil(t, created.OffsetMinutes)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NeedsExactlyOneTime", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		at := time.Now().Add(time.Hour)
+		_, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrReminderTime)
+		_, err = reminderUC.Create(model.ToReminder(model.CreateReminderRequest{RemindAt: &at, OffsetMinutes: &offset}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrReminderTime)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("RelativeWithoutDueDate", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+
+		_, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{OffsetMinutes: &offset}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrNoDueDate)
+	})
+
+	t.Run("InPast", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		at := time.Now().Add(-time.Hour)
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: userID}, nil)
+
+		_, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{RemindAt: &at}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrReminderInPast)
+	})
+
+	t.Run("ViewerRefused", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+		workspaceID := uint(5)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: 200, WorkspaceID: &workspaceID, DueDate: &due}, nil)
+		mockRepo.On("FindRole", workspaceID, userID).Return(workspaceModel.RoleViewer, nil)
+
+		_, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{OffsetMinutes: &offset}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		assert.ErrorIs(t, reminderUC.DeleteReminder(3, taskID, userID), usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("ForeignTask", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		reminderUC := usecase.NewReminderUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return((*taskModel.Task)(nil), gorm.ErrRecordNotFound)
+
+		_, err := reminderUC.Create(model.ToReminder(model.CreateReminderRequest{OffsetMinutes: &offset}, taskID, userID))
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	due := []model.DueReminder{
+		{Reminder: model.Reminder{ID: 1, TaskID: 10, UserID: 100}, Title: "Pay rent"},
+		{Reminder: model.Reminder{ID: 2, TaskID: 11, UserID: 100}, Title: "Call mum"},
+	}
+
+	t.Run("DeliversAndMarksSent", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		memory := notifier.NewMemoryNotifier()
+		scheduler := usecase.NewScheduler(mockRepo, memory, time.Minute)
+
+		mockRepo.On("FindDue", mock.AnythingOfType("time.Time"), 100).Return(&due, nil)
+		mockRepo.On("MarkSent", uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+		mockRepo.On("MarkSent", uint(2), mock.AnythingOfType("time.Time")).Return(nil)
+
+		sent, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, sent)
+		assert.Len(t, memory.Sent(), 2)
+		assert.Equal(t, "Pay rent", memory.Sent()[0].Title)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("FailedDeliveryIsRetriedLater", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		memory := notifier.NewMemoryNotifier()
+		memory.Err = errors.New("smtp down")
+		scheduler := usecase.NewScheduler(mockRepo, memory, time.Minute)
+
+		mockRepo.On("FindDue", mock.AnythingOfType("time.Time"), 100).Return(&due, nil)
+		mockRepo.On("MarkFailed", uint(1), "smtp down").Return(nil)
+		mockRepo.On("MarkFailed", uint(2), "smtp down").Return(nil)
+
+		sent, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		mockRepo.AssertNotCalled(t, "MarkSent", mock.Anything, mock.Anything)
+		mockRepo.AssertNumberOfCalls(t, "FindDue", 1)
+	})
+
+	t.Run("StartStopsWithContext", func(t *testing.T) {
+		mockRepo := new(MockReminderRepository)
+		scheduler := usecase.NewScheduler(mockRepo, notifier.NewMemoryNotifier(), time.Hour)
+
+		mockRepo.On("FindDue", mock.AnythingOfType("time.Time"), 100).Return(&[]model.DueReminder{}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			scheduler.Start(ctx)
+			close(done)
+		}()
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not stop")
+		}
+		mockRepo.AssertNumberOfCalls(t, "FindDue", 1)
+	})
+}
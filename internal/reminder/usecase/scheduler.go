@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/notifier"
+	"time"
+)
+
+const (
+	// MaxAttempts is how often delivery of one reminder is tried before giving up
+	MaxAttempts = 5
+	// schedulerBatch caps the reminders fired per tick
+	schedulerBatch = 100
+)
+
+// Scheduler fires due reminders from the reminders table. All of its state lives
+// in the table, so reminders that came due while the process was down go out on
+// the first tick after a restart.
+type Scheduler struct {
+	repo     ReminderRepository
+	notifier notifier.Notifier
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(repo ReminderRepository, notifier notifier.Notifier, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		repo:     repo,
+		notifier: notifier,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the scheduler until ctx is cancelled, checking once right away
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			logger.Log.Error("Reminder scheduler run failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce delivers every reminder due by now and returns how many went out.
+// A reminder is marked sent only after the notifier accepted it, failures are
+// retried on later runs up to MaxAttempts.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		failed := 0
+		due, err := s.repo.FindDue(s.now(), schedulerBatch)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, r := range *due {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			err := s.notifier.Notify(ctx, notifier.Notification{
+				UserID:  r.UserID,
+				TaskID:  r.TaskID,
+				Title:   r.Title,
+				DueDate: r.DueDate,
+				SendAt:  r.RemindAt,
+			})
+			if err != nil {
+				logger.Log.WithField("reminderID", r.ID).Warn("Reminder delivery failed: ", err)
+				if err := s.repo.MarkFailed(r.ID, err.Error()); err != nil {
+					return sent, err
+				}
+				failed++
+				continue
+			}
+			if err := s.repo.MarkSent(r.ID, s.now()); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+
+		// Failed reminders would come straight back, leave them to the next run
+		if len(*due) < schedulerBatch || failed > 0 {
+			return sent, nil
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"mymodule/internal/task/model"
 	"mymodule/internal/task/usecase"
 	"mymodule/pkg/auth"
+	"mymodule/pkg/cursor"
 	"mymodule/pkg/helper"
 	"mymodule/pkg/logger"
 	"mymodule/pkg/middleware"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -17,24 +20,53 @@ type HttpTaskhandler struct {
 	usecase usecase.TaskUsecase
 	token   auth.TokenService
 	valid   *validator.Validate
+	cursor  cursor.Signer
 }
 
-func NewTaskHandler(app *fiber.App, usecase usecase.TaskUsecase, token auth.TokenService, valid *validator.Validate) {
+// NewTaskHandler registers the task routes, workspace resolves the active
+// workspace of each request after the token is checked and roles confirms an
+// admin still is one
+func NewTaskHandler(app *fiber.App, usecase usecase.TaskUsecase, token auth.TokenService, valid *validator.Validate, cursor cursor.Signer, workspace fiber.Handler, roles middleware.RoleLookup) {
 	handler := &HttpTaskhandler{
 		usecase: usecase,
 		token:   token,
 		valid:   valid,
+		cursor:  cursor,
 	}
-	task := app.Group("/task", middleware.Middleware(token))
+	task := app.Group("/task", middleware.Middleware(token), workspace)
+
+	// Admins see every user's tasks, registered before /:id so "admin" is not read as an ID
+	admin := task.Group("/admin", middleware.RequireRole(auth.RoleAdmin, roles))
+	admin.Get("/", handler.GetAllTasks)
+	admin.Get("/search", handler.SearchAllTasks)
+	admin.Get("/:id", handler.GetTaskByID)
+	admin.Delete("/:id", handler.ForceDeleteTask)
+
 	task.Post("/", handler.Create)
 	task.Get("/", handler.GetTaskByUser)
+	task.Get("/search", handler.Search)
+	task.Get("/trash", handler.GetTrash)
+	task.Post("/bulk", handler.Bulk)
+	task.Get("/board", handler.GetBoard)
+	task.Get("/wip-limits", handler.GetWipLimits)
+	task.Put("/wip-limits", handler.SetWipLimit)
+	task.Delete("/wip-limits/:limitId", handler.DeleteWipLimit)
+	task.Get("/estimates/report", handler.GetEstimateReport)
+	task.Get("/export.csv", handler.ExportCSV)
+	task.Post("/import", handler.ImportCSV)
 	task.Get("/:id", handler.GetTaskByIDAndUser)
 	task.Put("/:id", handler.UpdateTask)
+	task.Get("/:id/subtasks", handler.GetSubtasks)
+	task.Post("/:id/subtasks", handler.CreateSubtask)
+	task.Put("/:id/parent", handler.MoveTask)
+	task.Post("/:id/move", handler.MoveOnBoard)
+	task.Post("/:id/dependencies", handler.AddDependency)
+	task.Delete("/:id/dependencies/:blockerId", handler.RemoveDependency)
+	task.Delete("/:id/recurrence", handler.EndSeries)
+	task.Get("/:id/history", handler.GetHistory)
+	task.Post("/:id/restore", handler.Restore)
+	task.Delete("/:id/purge", handler.Purge)
 	task.Delete("/:id", handler.DeleteTask)
-
-	// for Admin get all task regardless userID
-	task.Get("/admin/:id", handler.GetTaskByID)
-
 }
 
 func (h *HttpTaskhandler) Create(c *fiber.Ctx) error {
@@ -54,17 +86,105 @@ func (h *HttpTaskhandler) Create(c *fiber.Ctx) error {
 	}
 
 	task := model.ToTask(input, uint(userID))
+	task.WorkspaceID = activeWorkspace(c)
 	if err := h.usecase.Create(task); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"message": "Create task successfully "})
 }
 
-// For admin
+// GetTaskByID reads any user's task, admin only
 func (h *HttpTaskhandler) GetTaskByID(c *fiber.Ctx) error {
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	task, err := h.usecase.GetByID(uint(taskID))
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToDetailTaskResponse(model.TaskDetail{Task: *task}))
+}
+
+// GetAllTasks lists every user's tasks with the filters of GET /task, admin only.
+// ?user_id= and ?workspace_id= narrow it to one creator or workspace.
+func (h *HttpTaskhandler) GetAllTasks(c *fiber.Ctx) error {
+	filter, err := parseTaskFilter(c, h.cursor)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	for key, dst := range map[string]**uint{"user_id": &filter.UserID, "workspace_id": &filter.WorkspaceID} {
+		raw := c.Query(key)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid " + key})
+		}
+		value := uint(id)
+		*dst = &value
+	}
+	if err := h.valid.Struct(filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	page, err := h.usecase.GetAll(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
+	}
+	next, err := encodeTaskCursor(h.cursor, page.Next)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
+	}
+	prev, err := encodeTaskCursor(h.cursor, page.Prev)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
+	}
+	return c.JSON(model.ToTaskListResponse(*page, filter, next, prev))
+}
+
+// SearchAllTasks searches every user's tasks, admin only
+func (h *HttpTaskhandler) SearchAllTasks(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultTaskLimit)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 100"})
+	}
+
+	query := c.Query("q")
+	if strings.TrimSpace(query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "search query is required"})
+	}
+
+	results, err := h.usecase.SearchAll(query, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to search tasks"})
+	}
+	var resp []model.TaskSearchResponse
+	if results != nil {
+		resp = model.ToTaskSearchResponseList(*results)
+	}
+	return c.JSON(resp)
+}
+
+// ForceDeleteTask deletes any user's task with its subtasks, admin only
+func (h *HttpTaskhandler) ForceDeleteTask(c *fiber.Ctx) error {
+	adminID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
 
-	return nil
+	if err := h.usecase.ForceDelete(uint(taskID), adminID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "task deleted"})
 }
 
 // All task
@@ -74,16 +194,58 @@ func (h *HttpTaskhandler) GetTaskByUser(c *fiber.Ctx) error {
 		logger.Log.Error("Unauthorized access: ", err)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
-	tasks, err := h.usecase.GetByUser(userID)
+	filter, err := parseTaskFilter(c, h.cursor)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.valid.Struct(filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter.WorkspaceID = activeWorkspace(c)
+
+	page, err := h.usecase.GetByUser(userID, filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
 	}
-	var resp []model.TaskResponse
-	if tasks != nil {
-		resp = model.ToTaskResponseList(*tasks)
+	next, err := encodeTaskCursor(h.cursor, page.Next)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
+	}
+	prev, err := encodeTaskCursor(h.cursor, page.Prev)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tasks"})
+	}
+	return c.JSON(model.ToTaskListResponse(*page, filter, next, prev))
+
+}
+
+// Full-text search over the user's tasks
+func (h *HttpTaskhandler) Search(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	limit := c.QueryInt("limit", defaultTaskLimit)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 100"})
+	}
+
+	query := c.Query("q")
+	if strings.TrimSpace(query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "search query is required"})
 	}
-	return c.JSON(resp)
 
+	results, err := h.usecase.Search(userID, query, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to search tasks"})
+	}
+	var resp []model.TaskSearchResponse
+	if results != nil {
+		resp = model.ToTaskSearchResponseList(*results)
+	}
+	return c.JSON(resp)
 }
 
 // Detail task
@@ -98,13 +260,13 @@ func (h *HttpTaskhandler) GetTaskByIDAndUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
 	}
 
-	task, err := h.usecase.GetByIDAndUser(uint(taskID), userID)
+	detail, err := h.usecase.GetDetail(uint(taskID), userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "task not found or unauthorized"})
 	}
 	var resp model.DetailTaskResponse
-	if task != nil {
-		resp = model.ToDetailTaskResponse(*task)
+	if detail != nil {
+		resp = model.ToDetailTaskResponse(*detail)
 	}
 
 	return c.JSON(resp)
@@ -132,12 +294,33 @@ func (h *HttpTaskhandler) UpdateTask(c *fiber.Ctx) error {
 	}
 
 	if err := h.usecase.UpdateTask(&input,uint(taskID), uint(userID)); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		if body, ok := refusal(err); ok {
+			return c.Status(fiber.StatusConflict).JSON(body)
+		}
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"message": "task updated"})
 }
 
+// refusal describes a refused move: for a status change which move was refused and
+// what is possible instead, for a full column the WIP limit and its load
+func refusal(err error) (fiber.Map, bool) {
+	var refused *usecase.TransitionError
+	if errors.As(err, &refused) {
+		body := fiber.Map{"error": err.Error(), "from": refused.From, "to": refused.To}
+		if refused.Allowed != nil {
+			body["allowed"] = refused.Allowed
+		}
+		return body, true
+	}
+	var full *usecase.WipLimitError
+	if errors.As(err, &full) {
+		return fiber.Map{"error": err.Error(), "wip_limit": model.ToWipLimitResponse(full.Limit), "load": full.Load}, true
+	}
+	return nil, false
+}
+
 
 func (h *HttpTaskhandler) DeleteTask(c *fiber.Ctx) error {
 	userID, err := helper.GetUserIDFromContext(c)
@@ -150,8 +333,440 @@ func (h *HttpTaskhandler) DeleteTask(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
 	}
 	if err := h.usecase.DeleteTask(uint(taskID),userID); err != nil{
-	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"message": "task deleted"})
 }
+
+func (h *HttpTaskhandler) GetSubtasks(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	children, err := h.usecase.GetSubtasks(uint(taskID), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "task not found or unauthorized"})
+	}
+	var resp []model.TaskResponse
+	if children != nil {
+		resp = model.ToTaskResponseList(*children)
+	}
+	return c.JSON(resp)
+}
+
+func (h *HttpTaskhandler) CreateSubtask(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	parentID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.CreateTaskRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid subtask request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	parent := uint(parentID)
+	input.ParentID = &parent
+	task := model.ToTask(input, userID)
+	task.WorkspaceID = activeWorkspace(c)
+	if err := h.usecase.Create(task); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Create subtask successfully"})
+}
+
+func (h *HttpTaskhandler) MoveTask(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.MoveTaskRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	if err := h.usecase.MoveTask(uint(taskID), userID, input.ParentID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "task moved"})
+}
+
+func (h *HttpTaskhandler) AddDependency(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.AddDependencyRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.AddDependency(uint(taskID), input.BlockedByID, userID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "dependency added"})
+}
+
+func (h *HttpTaskhandler) RemoveDependency(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+	blockerID, err := strconv.Atoi(c.Params("blockerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid blocking task ID"})
+	}
+
+	if err := h.usecase.RemoveDependency(uint(taskID), uint(blockerID), userID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "dependency removed"})
+}
+
+// EndSeries stops a recurring task, no further occurrences are generated
+func (h *HttpTaskhandler) EndSeries(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	if err := h.usecase.EndSeries(uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "series ended"})
+}
+
+// Bulk applies one operation to many tasks, an atomic request that failed for
+// any task answers 409 with the per-task results and changes nothing
+func (h *HttpTaskhandler) Bulk(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.BulkRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result, err := h.usecase.Bulk(input, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to apply bulk operation"})
+	}
+	if !result.Committed {
+		return c.Status(fiber.StatusConflict).JSON(result)
+	}
+	return c.JSON(result)
+}
+
+// GetTrash lists the deleted tasks of the active workspace that can be restored
+func (h *HttpTaskhandler) GetTrash(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	tasks, err := h.usecase.GetTrash(userID, activeWorkspace(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch trash"})
+	}
+	return c.JSON(model.ToTrashedTaskResponseList(*tasks))
+}
+
+// Restore brings a deleted task back together with its deleted subtasks
+func (h *HttpTaskhandler) Restore(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	if err := h.usecase.Restore(uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "task restored"})
+}
+
+// Purge deletes a task in the trash for good
+func (h *HttpTaskhandler) Purge(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	if err := h.usecase.Purge(uint(taskID), userID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "task purged"})
+}
+
+// GetHistory returns who changed what on a task and when, oldest first
+func (h *HttpTaskhandler) GetHistory(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	entries, err := h.usecase.GetHistory(uint(taskID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToTaskHistoryResponseList(*entries))
+}
+
+// GetBoard returns the board of the project given by the project_id query parameter
+func (h *HttpTaskhandler) GetBoard(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	projectID, err := strconv.Atoi(c.Query("project_id"))
+	if err != nil || projectID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+	}
+
+	board, err := h.usecase.GetBoard(uint(projectID), userID, activeWorkspace(c))
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToBoardResponse(*board))
+}
+
+// MoveOnBoard reorders a task within its board column or drops it into another one
+func (h *HttpTaskhandler) MoveOnBoard(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+	}
+
+	var input model.BoardMoveRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.MoveOnBoard(uint(taskID), userID, input); err != nil {
+		if body, ok := refusal(err); ok {
+			return c.Status(fiber.StatusConflict).JSON(body)
+		}
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "task moved"})
+}
+
+// GetWipLimits lists the WIP limits of the project given by the project_id query
+// parameter, or else those of the active workspace
+func (h *HttpTaskhandler) GetWipLimits(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var projectID *uint
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+		}
+		project := uint(id)
+		projectID = &project
+	}
+
+	limits, err := h.usecase.GetWipLimits(userID, activeWorkspace(c), projectID)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToWipLimitResponseList(limits))
+}
+
+// SetWipLimit sets the WIP limit of a status, on a project or the active workspace
+func (h *HttpTaskhandler) SetWipLimit(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.WipLimitRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	limit, err := h.usecase.SetWipLimit(model.ToWipLimit(input, activeWorkspace(c)), userID, activeWorkspace(c))
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToWipLimitResponse(*limit))
+}
+
+// DeleteWipLimit lifts a WIP limit
+func (h *HttpTaskhandler) DeleteWipLimit(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	limitID, err := strconv.Atoi(c.Params("limitId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid WIP limit ID"})
+	}
+
+	if err := h.usecase.DeleteWipLimit(uint(limitID), userID, activeWorkspace(c)); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "WIP limit deleted"})
+}
+
+// GetEstimateReport compares estimates with the effort of the tasks completed in
+// the given days, in the active workspace and optionally one project
+func (h *HttpTaskhandler) GetEstimateReport(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	query := model.EstimateQuery{From: c.Query("from"), To: c.Query("to"), TZ: c.Query("tz"), WorkspaceID: activeWorkspace(c)}
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+		}
+		project := uint(id)
+		query.ProjectID = &project
+	}
+	if err := h.valid.Struct(query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.usecase.GetEstimateReport(userID, query)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToEstimateReportResponse(*report))
+}
+
+// activeWorkspace is the workspace resolved for the request, nil outside one
+func activeWorkspace(c *fiber.Ctx) *uint {
+	workspaceID, err := helper.GetWorkspaceIDFromContext(c)
+	if err != nil || workspaceID == 0 {
+		return nil
+	}
+	return &workspaceID
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound),
+		errors.Is(err, usecase.ErrNotInTrash),
+		errors.Is(err, usecase.ErrParentNotFound),
+		errors.Is(err, usecase.ErrBlockerNotFound),
+		errors.Is(err, usecase.ErrDependencyNotFound),
+		errors.Is(err, usecase.ErrWipLimitNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrLabelNotFound),
+		errors.Is(err, usecase.ErrProjectNotFound),
+		errors.Is(err, usecase.ErrAssigneeNotFound),
+		errors.Is(err, usecase.ErrAssigneeNotMember),
+		errors.Is(err, usecase.ErrInvalidRecurrence),
+		errors.Is(err, usecase.ErrRecurrenceNeedsDueDate),
+		errors.Is(err, usecase.ErrEstimatePrecision),
+		errors.Is(err, usecase.ErrUnknownStatus),
+		errors.Is(err, usecase.ErrInvalidNeighbour),
+		errors.Is(err, usecase.ErrWipNoOwner),
+		errors.Is(err, usecase.ErrInvalidTimezone),
+		errors.Is(err, usecase.ErrInvalidPeriod):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrTaskCycle),
+		errors.Is(err, usecase.ErrOpenSubtasks),
+		errors.Is(err, usecase.ErrDependencyCycle),
+		errors.Is(err, usecase.ErrTaskBlocked),
+		errors.Is(err, usecase.ErrIllegalTransition),
+		errors.Is(err, usecase.ErrProjectArchived),
+		errors.Is(err, usecase.ErrNotRecurring),
+		errors.Is(err, usecase.ErrParentInTrash),
+		errors.Is(err, usecase.ErrNotOnBoard),
+		errors.Is(err, usecase.ErrWipLimit):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrNotCreator), errors.Is(err, usecase.ErrReadOnly), errors.Is(err, usecase.ErrNotWipManager):
+		return fiber.StatusForbidden
+	default:
+		return fallback
+	}
+}
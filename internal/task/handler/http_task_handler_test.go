@@ -0,0 +1,820 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	labelModel "mymodule/internal/label/model"
+	"mymodule/internal/task/handler"
+	"mymodule/internal/task/model"
+	"mymodule/internal/task/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/cursor"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTaskUsecase) Create(task model.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetByID(taskID uint) (*model.Task, error) {
+	args := m.Called(taskID)
+	if task := args.Get(0); task != nil {
+		return task.(*model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetByUser(userID uint, filter model.TaskFilter) (*model.TaskPage, error) {
+	args := m.Called(userID, filter)
+	if page := args.Get(0); page != nil {
+		return page.(*model.TaskPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetByIDAndUser(taskID, userID uint) (*model.Task, error) {
+	args := m.Called(taskID, userID)
+	if task := args.Get(0); task != nil {
+		return task.(*model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetDetail(taskID, userID uint) (*model.TaskDetail, error) {
+	args := m.Called(taskID, userID)
+	if detail := args.Get(0); detail != nil {
+		return detail.(*model.TaskDetail), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetSubtasks(taskID, userID uint) (*[]model.Task, error) {
+	args := m.Called(taskID, userID)
+	if tasks := args.Get(0); tasks != nil {
+		return tasks.(*[]model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error) {
+	args := m.Called(userID, query, limit)
+	if results := args.Get(0); results != nil {
+		return results.(*[]model.TaskSearchResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) UpdateTask(task *model.UpdateTaskInput, taskID, userID uint) error {
+	args := m.Called(task, taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) MoveTask(taskID, userID uint, parentID *uint) error {
+	args := m.Called(taskID, userID, parentID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) AddDependency(taskID, blockedByID, userID uint) error {
+	args := m.Called(taskID, blockedByID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) RemoveDependency(taskID, blockedByID, userID uint) error {
+	args := m.Called(taskID, blockedByID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) EndSeries(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) DeleteTask(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetAll(filter model.TaskFilter) (*model.TaskPage, error) {
+	args := m.Called(filter)
+	if page := args.Get(0); page != nil {
+		return page.(*model.TaskPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) SearchAll(query string, limit int) (*[]model.TaskSearchResult, error) {
+	args := m.Called(query, limit)
+	if results := args.Get(0); results != nil {
+		return results.(*[]model.TaskSearchResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetTrash(userID uint, workspaceID *uint) (*[]model.Task, error) {
+	args := m.Called(userID, workspaceID)
+	if tasks := args.Get(0); tasks != nil {
+		return tasks.(*[]model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) Restore(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) Purge(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) Bulk(req model.BulkRequest, userID uint) (*model.BulkResult, error) {
+	args := m.Called(req, userID)
+	if result := args.Get(0); result != nil {
+		return result.(*model.BulkResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetBoard(projectID, userID uint, workspaceID *uint) (*model.Board, error) {
+	args := m.Called(projectID, userID, workspaceID)
+	if board := args.Get(0); board != nil {
+		return board.(*model.Board), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) MoveOnBoard(taskID, userID uint, req model.BoardMoveRequest) error {
+	args := m.Called(taskID, userID, req)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetWipLimits(userID uint, workspaceID, projectID *uint) ([]model.WipLimit, error) {
+	args := m.Called(userID, workspaceID, projectID)
+	if limits := args.Get(0); limits != nil {
+		return limits.([]model.WipLimit), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) SetWipLimit(limit model.WipLimit, userID uint, workspaceID *uint) (*model.WipLimit, error) {
+	args := m.Called(limit, userID, workspaceID)
+	if saved := args.Get(0); saved != nil {
+		return saved.(*model.WipLimit), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) DeleteWipLimit(limitID, userID uint, workspaceID *uint) error {
+	args := m.Called(limitID, userID, workspaceID)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetEstimateReport(userID uint, query model.EstimateQuery) (*model.EstimateReport, error) {
+	args := m.Called(userID, query)
+	return args.Get(0).(*model.EstimateReport), args.Error(1)
+}
+
+// Export hands each task the test returns to each
+func (m *MockTaskUsecase) Export(userID uint, workspaceID *uint, each func(task model.Task) error) error {
+	args := m.Called(userID, workspaceID)
+	for _, task := range args.Get(0).([]model.Task) {
+		if err := each(task); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTaskUsecase) Import(rows []model.ImportRow, userID uint, workspaceID, projectID *uint, commit bool) (*model.ImportResult, error) {
+	args := m.Called(rows, userID, workspaceID, projectID, commit)
+	if result := args.Get(0); result != nil {
+		return result.(*model.ImportResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetHistory(taskID, userID uint) (*[]model.TaskHistory, error) {
+	args := m.Called(taskID, userID)
+	if entries := args.Get(0); entries != nil {
+		return entries.(*[]model.TaskHistory), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskUsecase) ForceDelete(taskID, adminID uint) error {
+	args := m.Called(taskID, adminID)
+	return args.Error(0)
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+// setupApp mounts the task routes with a real token manager, the workspace
+// middleware is left out since the admin routes ignore the active workspace.
+// The role lookup finds every user still an admin.
+func setupApp(uc usecase.TaskUsecase) (*fiber.App, auth.TokenService) {
+	return setupAppWithRole(uc, auth.RoleAdmin)
+}
+
+// setupAppWithRole is setupApp with every user holding role now
+func setupAppWithRole(uc usecase.TaskUsecase, role string) (*fiber.App, auth.TokenService) {
+	app := fiber.New()
+	token := auth.NewJwtManager("test-secret", time.Hour)
+	noWorkspace := func(c *fiber.Ctx) error { return c.Next() }
+	roles := func(userID uint) (string, error) { return role, nil }
+	handler.NewTaskHandler(app, uc, token, validator.InitValidator(), cursor.NewHmacSigner("test-cursor"), noWorkspace, roles)
+	return app, token
+}
+
+func request(t *testing.T, app *fiber.App, token auth.TokenService, method, target, role string, body ...string) *http.Response {
+	var payload io.Reader
+	if len(body) > 0 {
+		payload = strings.NewReader(body[0])
+	}
+	req := httptest.NewRequest(method, target, payload)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if role != "" {
+		jwt, err := token.GenerateToken(1, role)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+jwt)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestAdminRoutes(t *testing.T) {
+	routes := []struct {
+		method string
+		target string
+	}{
+		{http.MethodGet, "/task/admin/"},
+		{http.MethodGet, "/task/admin/search?q=report"},
+		{http.MethodGet, "/task/admin/7"},
+		{http.MethodDelete, "/task/admin/7"},
+	}
+
+	t.Run("RejectsMissingToken", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		for _, r := range routes {
+			resp := request(t, app, token, r.method, r.target, "")
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, r.method+" "+r.target)
+		}
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("ForbidsRegularUsers", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		for _, r := range routes {
+			resp := request(t, app, token, r.method, r.target, auth.RoleUser)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, r.method+" "+r.target)
+		}
+		// Nothing reaches the usecase
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("ForbidsRevokedAdmins", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupAppWithRole(mockUC, auth.RoleUser)
+
+		for _, r := range routes {
+			resp := request(t, app, token, r.method, r.target, auth.RoleAdmin)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, r.method+" "+r.target)
+		}
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("GetAnyTask", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("GetByID", uint(7)).Return(&model.Task{ID: 7, Title: "Someone else's", UserID: 42}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/admin/7", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body model.DetailTaskResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, uint(42), body.CreatorID)
+		assert.Equal(t, "Someone else's", body.Title)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("GetMissingTask", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("GetByID", uint(7)).Return(nil, usecase.ErrTaskNotFound)
+
+		resp := request(t, app, token, http.MethodGet, "/task/admin/7", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("ListByCreator", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("GetAll", mock.MatchedBy(func(f model.TaskFilter) bool {
+			return f.UserID != nil && *f.UserID == 42 && f.WorkspaceID == nil
+		})).Return(&model.TaskPage{Tasks: []model.Task{{ID: 7, UserID: 42}}, Total: 1}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/admin/?user_id=42", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("ListInvalidUserID", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		resp := request(t, app, token, http.MethodGet, "/task/admin/?user_id=abc", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("SearchAll", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("SearchAll", "report", 20).Return(&[]model.TaskSearchResult{{ID: 7, Title: "Quarterly report"}}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/admin/search?q=report", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("ForceDelete", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("ForceDelete", uint(7), uint(1)).Return(nil)
+
+		resp := request(t, app, token, http.MethodDelete, "/task/admin/7", auth.RoleAdmin)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+}
+
+func TestBulk(t *testing.T) {
+	t.Run("RejectsInvalidRequests", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"UnknownOperation": `{"operation":"archive","task_ids":[1]}`,
+			"NoTasks":          `{"operation":"delete","task_ids":[]}`,
+			"StatusMissing":    `{"operation":"set_status","task_ids":[1]}`,
+			"BadStatus":        `{"operation":"set_status","task_ids":[1],"status":"far_too_long_for_any_status"}`,
+			"LabelMissing":     `{"operation":"remove_label","task_ids":[1]}`,
+		} {
+			mockUC := new(MockTaskUsecase)
+			app, token := setupApp(mockUC)
+
+			resp := request(t, app, token, http.MethodPost, "/task/bulk", auth.RoleUser, body)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, name)
+			mockUC.AssertExpectations(t)
+		}
+	})
+
+	t.Run("ReportsPerTask", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("Bulk", mock.MatchedBy(func(req model.BulkRequest) bool {
+			return req.Operation == model.BulkSetStatus && *req.Status == "completed" && len(req.TaskIDs) == 2
+		}), uint(1)).Return(&model.BulkResult{Committed: true, Results: []model.BulkItemResult{
+			{TaskID: 1, Status: model.BulkOK},
+			{TaskID: 2, Status: model.BulkForbidden, Error: "forbidden"},
+		}}, nil)
+
+		resp := request(t, app, token, http.MethodPost, "/task/bulk", auth.RoleUser,
+			`{"operation":"set_status","task_ids":[1,2],"status":"completed"}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body model.BulkResult
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Len(t, body.Results, 2)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("AtomicFailureConflicts", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("Bulk", mock.AnythingOfType("model.BulkRequest"), uint(1)).Return(&model.BulkResult{Results: []model.BulkItemResult{
+			{TaskID: 1, Status: model.BulkRolledBack},
+			{TaskID: 2, Status: model.BulkNotFound, Error: "task not found"},
+		}}, nil)
+
+		resp := request(t, app, token, http.MethodPost, "/task/bulk", auth.RoleUser,
+			`{"operation":"delete","task_ids":[1,2],"atomic":true}`)
+
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+}
+
+func TestUpdateTaskStatus(t *testing.T) {
+	t.Run("IllegalTransitionIsStructured", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("UpdateTask", mock.AnythingOfType("*model.UpdateTaskInput"), uint(7), uint(1)).Return(&usecase.TransitionError{
+			From: model.StatusOverdue, To: model.StatusInProgress, Allowed: []string{model.StatusCompleted}, Err: usecase.ErrIllegalTransition,
+		})
+
+		resp := request(t, app, token, http.MethodPut, "/task/7", auth.RoleUser, `{"status":"in_progress"}`)
+
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		var body struct {
+			Error   string   `json:"error"`
+			From    string   `json:"from"`
+			To      string   `json:"to"`
+			Allowed []string `json:"allowed"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, model.StatusOverdue, body.From)
+		assert.Equal(t, model.StatusInProgress, body.To)
+		assert.Equal(t, []string{model.StatusCompleted}, body.Allowed)
+	})
+
+	t.Run("AcceptsEchoedOverdue", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("UpdateTask", mock.MatchedBy(func(input *model.UpdateTaskInput) bool {
+			return *input.Status == model.StatusOverdue
+		}), uint(7), uint(1)).Return(nil)
+
+		resp := request(t, app, token, http.MethodPut, "/task/7", auth.RoleUser, `{"status":"overdue"}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+}
+
+func TestBoard(t *testing.T) {
+	t.Run("GetBoardNeedsProject", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		resp := request(t, app, token, http.MethodGet, "/task/board", auth.RoleUser)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockUC.AssertNotCalled(t, "GetBoard", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetBoardColumns", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("GetBoard", uint(3), uint(1), mock.Anything).Return(&model.Board{ProjectID: 3, Columns: []model.BoardColumn{
+			{Status: model.StatusPending, Name: "Pending", Category: model.CategoryTodo, Tasks: []model.Task{{ID: 9, Title: "Card"}}},
+			{Status: model.StatusCompleted, Name: "Completed", Category: model.CategoryDone, Tasks: []model.Task{}},
+		}}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/board?project_id=3", auth.RoleUser)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body model.BoardResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		if assert.Len(t, body.Columns, 2) {
+			assert.Equal(t, model.StatusPending, body.Columns[0].Status)
+			assert.Equal(t, uint(9), body.Columns[0].Tasks[0].ID)
+			assert.NotNil(t, body.Columns[1].Tasks)
+		}
+	})
+
+	t.Run("MovePassesNeighbours", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("MoveOnBoard", uint(7), uint(1), mock.MatchedBy(func(req model.BoardMoveRequest) bool {
+			return *req.AfterID == 2 && *req.BeforeID == 3 && req.Status == nil
+		})).Return(nil)
+
+		resp := request(t, app, token, http.MethodPost, "/task/7/move", auth.RoleUser, `{"after_id":2,"before_id":3}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("MoveErrors", func(t *testing.T) {
+		for err, status := range map[error]int{
+			usecase.ErrInvalidNeighbour: fiber.StatusBadRequest,
+			usecase.ErrNotOnBoard:       fiber.StatusConflict,
+			&usecase.TransitionError{From: model.StatusOverdue, To: model.StatusPending, Err: usecase.ErrIllegalTransition}: fiber.StatusConflict,
+		} {
+			mockUC := new(MockTaskUsecase)
+			app, token := setupApp(mockUC)
+			mockUC.On("MoveOnBoard", uint(7), uint(1), mock.Anything).Return(err)
+
+			resp := request(t, app, token, http.MethodPost, "/task/7/move", auth.RoleUser, `{"status":"pending"}`)
+
+			assert.Equal(t, status, resp.StatusCode, err.Error())
+		}
+	})
+}
+
+func TestWipLimits(t *testing.T) {
+	t.Run("FullColumnIsStructured", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		limit := model.WipLimit{ID: 4, Status: model.StatusInProgress, Scope: model.WipPerUser, Max: 3}
+		mockUC.On("UpdateTask", mock.AnythingOfType("*model.UpdateTaskInput"), uint(7), uint(1)).Return(&usecase.WipLimitError{Limit: limit, Load: 3})
+
+		resp := request(t, app, token, http.MethodPut, "/task/7", auth.RoleUser, `{"status":"in_progress"}`)
+
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		var body struct {
+			Error    string                 `json:"error"`
+			WipLimit model.WipLimitResponse `json:"wip_limit"`
+			Load     int64                  `json:"load"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, uint(4), body.WipLimit.ID)
+		assert.Equal(t, int64(3), body.Load)
+	})
+
+	t.Run("OverrideFlagPassed", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("MoveOnBoard", uint(7), uint(1), mock.MatchedBy(func(req model.BoardMoveRequest) bool {
+			return req.OverrideWip
+		})).Return(nil)
+
+		resp := request(t, app, token, http.MethodPost, "/task/7/move", auth.RoleUser, `{"status":"in_progress","override_wip":true}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("SetValidates", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		resp := request(t, app, token, http.MethodPut, "/task/wip-limits", auth.RoleUser, `{"status":"in_progress","scope":"team","max":3}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockUC.AssertNotCalled(t, "SetWipLimit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetOnProject", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		projectID := uint(3)
+		mockUC.On("SetWipLimit", mock.MatchedBy(func(limit model.WipLimit) bool {
+			return *limit.ProjectID == projectID && limit.WorkspaceID == nil && limit.Max == 2
+		}), uint(1), (*uint)(nil)).Return(&model.WipLimit{ID: 1, ProjectID: &projectID, Status: model.StatusInProgress, Scope: model.WipPerProject, Max: 2}, nil)
+
+		resp := request(t, app, token, http.MethodPut, "/task/wip-limits", auth.RoleUser, `{"project_id":3,"status":"in_progress","scope":"project","max":2}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("DeleteForbidden", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("DeleteWipLimit", uint(4), uint(1), (*uint)(nil)).Return(usecase.ErrNotWipManager)
+
+		resp := request(t, app, token, http.MethodDelete, "/task/wip-limits/4", auth.RoleUser)
+
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestEstimates(t *testing.T) {
+	t.Run("ReportQueryPassed", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+		report := &model.EstimateReport{From: from, To: from.AddDate(0, 0, 7), TZ: "UTC", Overall: model.EstimateStats{Tasks: 2, Ratio: 1.23456}}
+		mockUC.On("GetEstimateReport", uint(1), mock.MatchedBy(func(q model.EstimateQuery) bool {
+			return q.From == "2025-03-03" && q.To == "2025-03-09" && q.ProjectID != nil && *q.ProjectID == 7
+		})).Return(report, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/estimates/report?from=2025-03-03&to=2025-03-09&project_id=7", auth.RoleUser, "")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body model.EstimateReportResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "2025-03-09", body.To)
+		assert.Equal(t, 1.23, body.Overall.Ratio)
+	})
+
+	t.Run("BadDay", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		resp := request(t, app, token, http.MethodGet, "/task/estimates/report?from=March", auth.RoleUser, "")
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockUC.AssertNotCalled(t, "GetEstimateReport", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EstimateUnitsExclusive", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+
+		resp := request(t, app, token, http.MethodPost, "/task", auth.RoleUser, `{"title":"Both","estimate_minutes":30,"estimate_points":2}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockUC.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+// upload posts csv as the file of an import, with mapping when it is not empty
+func upload(t *testing.T, app *fiber.App, token auth.TokenService, target, csv, mapping string) *http.Response {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if mapping != "" {
+		assert.NoError(t, form.WriteField("mapping", mapping))
+	}
+	file, err := form.CreateFormFile("file", "tasks.csv")
+	assert.NoError(t, err)
+	_, err = file.Write([]byte(csv))
+	assert.NoError(t, err)
+	assert.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	jwt, err := token.GenerateToken(1, auth.RoleUser)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestCSV(t *testing.T) {
+	t.Run("Export", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		due := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+		mockUC.On("Export", uint(1), (*uint)(nil)).Return([]model.Task{
+			{ID: 1, Title: "Plain", Status: "pending", Priority: "none"},
+			{ID: 2, Title: "Quoted, \"really\"", Description: "two\nlines", DueDate: &due, Status: "completed", Priority: "high",
+				Labels: []labelModel.Label{{Name: "work"}, {Name: "urgent"}}},
+		}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/export.csv", auth.RoleUser)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "tasks.csv")
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "title,description,due_date,status,priority,labels\n"+
+			"Plain,,,pending,none,\n"+
+			"\"Quoted, \"\"really\"\"\",\"two\nlines\",2025-03-10T09:00:00Z,completed,high,work;urgent\n", string(body))
+	})
+
+	t.Run("ExportEscapesFormulas", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("Export", uint(1), (*uint)(nil)).Return([]model.Task{
+			{ID: 1, Title: "=HYPERLINK(\"http://evil\")", Description: "+1", Status: "pending", Priority: "none",
+				Labels: []labelModel.Label{{Name: "@home"}, {Name: "-x"}}},
+			{ID: 2, Title: "'quoted", Description: "a-b", Status: "pending", Priority: "none"},
+		}, nil)
+
+		resp := request(t, app, token, http.MethodGet, "/task/export.csv", auth.RoleUser)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "title,description,due_date,status,priority,labels\n"+
+			"\"'=HYPERLINK(\"\"http://evil\"\")\",'+1,,pending,none,'@home;'-x\n"+
+			"''quoted,a-b,,pending,none,\n", string(body))
+	})
+
+	t.Run("ImportUnescapesFormulas", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		var rows []model.ImportRow
+		mockUC.On("Import", mock.Anything, uint(1), (*uint)(nil), (*uint)(nil), false).
+			Run(func(args mock.Arguments) { rows = args.Get(0).([]model.ImportRow) }).
+			Return(&model.ImportResult{DryRun: true, Rows: 1, Errors: []model.ImportError{}}, nil)
+
+		resp := upload(t, app, token, "/task/import", "title,description,labels\n'=SUM(A1),''quoted,'@home;'plain\n", "")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "=SUM(A1)", rows[0].Request.Title)
+			assert.Equal(t, "'quoted", rows[0].Request.Description)
+			assert.Equal(t, []string{"@home", "'plain"}, rows[0].Labels)
+		}
+	})
+
+	t.Run("DryRunWithRowErrors", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		var rows []model.ImportRow
+		mockUC.On("Import", mock.Anything, uint(1), (*uint)(nil), (*uint)(nil), false).
+			Run(func(args mock.Arguments) { rows = args.Get(0).([]model.ImportRow) }).
+			Return(&model.ImportResult{DryRun: true, Rows: 4, Valid: 1, Errors: []model.ImportError{}}, nil)
+
+		csv := "Title,Priority,Due_Date,Labels\n" +
+			"Good,HIGH,2025-03-10,work; home\n" +
+			",low,,\n" +
+			"Loud,shouting,,\n" +
+			"Late,,tomorrow,\n"
+		resp := upload(t, app, token, "/task/import", csv, "")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Len(t, rows, 4)
+		assert.Empty(t, rows[0].Errors)
+		assert.Equal(t, "high", rows[0].Request.Priority)
+		assert.Equal(t, []string{"work", "home"}, rows[0].Labels)
+		assert.Equal(t, []model.ImportError{{Line: 3, Field: "title", Message: "required"}}, rows[1].Errors)
+		assert.Equal(t, []model.ImportError{{Line: 4, Field: "priority", Message: "oneof=none low medium high urgent"}}, rows[2].Errors)
+		assert.Equal(t, "due_date", rows[3].Errors[0].Field)
+	})
+
+	t.Run("MappingAndCommit", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		projectID := uint(7)
+		mockUC.On("Import", mock.MatchedBy(func(rows []model.ImportRow) bool {
+			return len(rows) == 1 && rows[0].Line == 2 && rows[0].Request.Title == "Ship it" && rows[0].Status == "in_progress"
+		}), uint(1), (*uint)(nil), &projectID, true).Return(&model.ImportResult{Rows: 1, Valid: 1, Imported: 1, Errors: []model.ImportError{}}, nil)
+
+		resp := upload(t, app, token, "/task/import?commit=true&project_id=7", "\ufeffName,State\nShip it,in_progress\n", `{"title":"name","status":"State"}`)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("CommitWithErrors", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		mockUC.On("Import", mock.Anything, uint(1), (*uint)(nil), (*uint)(nil), true).
+			Return(&model.ImportResult{Rows: 1, Errors: []model.ImportError{{Line: 2, Field: "status", Message: "status is not part of the task's workflow"}}}, nil)
+
+		resp := upload(t, app, token, "/task/import?commit=true", "title,status\nOne,limbo\n", "")
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("BadFile", func(t *testing.T) {
+		cases := map[string]struct{ csv, mapping string }{
+			"NoTitle":       {"name,status\nOne,pending\n", ""},
+			"UnknownColumn": {"title\nOne\n", `{"owner":"Owner"}`},
+			"MissingHeader": {"title\nOne\n", `{"status":"State"}`},
+			"BadMapping":    {"title\nOne\n", `["title"]`},
+		}
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockUC := new(MockTaskUsecase)
+				app, token := setupApp(mockUC)
+
+				resp := upload(t, app, token, "/task/import", c.csv, c.mapping)
+
+				assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+				mockUC.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("ShortRowReported", func(t *testing.T) {
+		mockUC := new(MockTaskUsecase)
+		app, token := setupApp(mockUC)
+		var rows []model.ImportRow
+		mockUC.On("Import", mock.Anything, uint(1), (*uint)(nil), (*uint)(nil), false).
+			Run(func(args mock.Arguments) { rows = args.Get(0).([]model.ImportRow) }).
+			Return(&model.ImportResult{DryRun: true, Errors: []model.ImportError{}}, nil)
+
+		resp := upload(t, app, token, "/task/import", "title,priority\nOne,low\nTwo\n", "")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, []model.ImportError{{Line: 3, Message: "expected 2 fields, got 1"}}, rows[1].Errors)
+	})
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	maxImportRows = 1000 // rows one import may hold
+	exportFlush   = 100  // rows an export writes before flushing them out
+)
+
+// ExportCSV streams the tasks of the active workspace as CSV in the columns of
+// model.CSVColumns. The rows are written while they are read, a failure halfway
+// can only cut the file short.
+func (h *HttpTaskhandler) ExportCSV(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID := activeWorkspace(c)
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tasks.csv"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		out := csv.NewWriter(w)
+		if err := out.Write(model.CSVColumns); err != nil {
+			return
+		}
+		rows := 0
+		err := h.usecase.Export(userID, workspaceID, func(task model.Task) error {
+			if err := out.Write(model.ToCSVRecord(task)); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlush == 0 {
+				out.Flush()
+				if err := out.Error(); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			return nil
+		})
+		out.Flush()
+		if err == nil {
+			err = out.Error()
+		}
+		if err != nil {
+			logger.Log.WithField("userID", userID).Error("Task export cut short: ", err)
+		}
+	})
+	return nil
+}
+
+// ImportCSV imports the tasks in the CSV sent as the multipart file "file", into
+// the active workspace and optionally ?project_id=. The optional form field
+// "mapping" is a JSON object naming the header of the file that holds each CSV
+// column, columns not named are read from the header of the same name. Without
+// ?commit=true the import is a dry run that only reports what would fail; a
+// committed import with errors answers 422 and writes nothing.
+func (h *HttpTaskhandler) ImportCSV(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var projectID *uint
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid project ID"})
+		}
+		project := uint(id)
+		projectID = &project
+	}
+	commit := c.QueryBool("commit", false)
+
+	mapping := map[string]string{}
+	if raw := c.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid mapping: expected a JSON object of column to header"})
+		}
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		logger.Log.Error("Invalid import request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Log.Error("Failed to open uploaded file: ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	defer file.Close()
+
+	rows, err := readImport(file, mapping)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	for i := range rows {
+		rows[i].Errors = append(rows[i].Errors, h.validateRow(rows[i])...)
+	}
+
+	result, err := h.usecase.Import(rows, userID, activeWorkspace(c), projectID, commit)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if commit && len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	return c.JSON(result)
+}
+
+// validateRow checks a row against the rules of CreateTaskRequest, each failed
+// rule becoming an error on its column
+func (h *HttpTaskhandler) validateRow(row model.ImportRow) []model.ImportError {
+	err := h.valid.Struct(row)
+	if err == nil {
+		return nil
+	}
+	var fields validator.ValidationErrors
+	if !errors.As(err, &fields) {
+		return []model.ImportError{{Line: row.Line, Message: err.Error()}}
+	}
+	errs := make([]model.ImportError, 0, len(fields))
+	for _, f := range fields {
+		message := f.Tag()
+		if f.Param() != "" {
+			message += "=" + f.Param()
+		}
+		errs = append(errs, model.ImportError{Line: row.Line, Field: model.ImportField(f.StructField()), Message: message})
+	}
+	return errs
+}
+
+// readImport reads the rows of a CSV whose first line is its header. mapping names
+// the header holding a CSV column, headers are matched without regard to case.
+// A row with the wrong number of fields is kept with an error so the other rows
+// still get checked.
+func readImport(r io.Reader, mapping map[string]string) ([]model.ImportRow, error) {
+	for column := range mapping {
+		if !slices.Contains(model.CSVColumns, column) {
+			return nil, fmt.Errorf("invalid mapping: unknown column %q", column)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: no header line")
+	}
+	if len(headers) > 0 {
+		headers[0] = strings.TrimPrefix(headers[0], "\ufeff")
+	}
+	index := make(map[string]int, len(headers))
+	for i, name := range headers {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	columns := make(map[string]int, len(model.CSVColumns))
+	for _, column := range model.CSVColumns {
+		name, mapped := mapping[column]
+		if !mapped {
+			name = column
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("invalid mapping: header %q not found", name)
+			}
+			continue
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("invalid CSV: no title column")
+	}
+
+	var rows []model.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("too many rows: at most %d tasks can be imported at once", maxImportRows)
+		}
+		row := model.ToImportRow(line, record, columns)
+		if err != nil {
+			row.Errors = append(row.Errors, model.ImportError{Line: line, Message: fmt.Sprintf("expected %d fields, got %d", len(headers), len(record))})
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
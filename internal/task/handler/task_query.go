@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/cursor"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultTaskLimit = 20
+
+// parseTaskFilter reads the listing query string of GET /task
+func parseTaskFilter(c *fiber.Ctx, signer cursor.Signer) (model.TaskFilter, error) {
+	filter := model.TaskFilter{
+		SortBy: c.Query("sort_by", "smart"),
+		Order:  strings.ToLower(c.Query("order", "desc")),
+		Limit:  c.QueryInt("limit", defaultTaskLimit),
+		Offset: c.QueryInt("offset", 0),
+		Mine:   strings.ToLower(c.Query("mine")),
+	}
+
+	if status := c.Query("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				filter.Status = append(filter.Status, s)
+			}
+		}
+	}
+
+	if labels := c.Query("labels"); labels != "" {
+		for _, raw := range strings.Split(labels, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid labels: expected comma separated label IDs")
+			}
+			filter.LabelIDs = append(filter.LabelIDs, uint(id))
+		}
+		filter.LabelMatch = strings.ToLower(c.Query("label_match", "any"))
+	}
+
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid project_id: expected a project ID")
+		}
+		projectID := uint(id)
+		filter.ProjectID = &projectID
+	}
+
+	times := []struct {
+		key string
+		dst **time.Time
+	}{
+		{"due_from", &filter.DueFrom},
+		{"due_to", &filter.DueTo},
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"updated_from", &filter.UpdatedFrom},
+		{"updated_to", &filter.UpdatedTo},
+	}
+	for _, t := range times {
+		raw := c.Query(t.key)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseQueryTime(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s: expected RFC3339 or YYYY-MM-DD", t.key)
+		}
+		*t.dst = &parsed
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		var pos model.TaskCursor
+		if err := signer.Decode(raw, &pos); err != nil {
+			return filter, err
+		}
+		if (c.Query("sort_by") != "" && c.Query("sort_by") != pos.SortBy) ||
+			(c.Query("order") != "" && strings.ToLower(c.Query("order")) != pos.Order) {
+			return filter, fmt.Errorf("cursor does not match the requested sort order")
+		}
+		filter.SortBy, filter.Order = pos.SortBy, pos.Order
+		filter.Cursor = &pos
+	}
+
+	return filter, nil
+}
+
+// encodeTaskCursor signs a page cursor, an absent cursor encodes to ""
+func encodeTaskCursor(signer cursor.Signer, pos *model.TaskCursor) (string, error) {
+	if pos == nil {
+		return "", nil
+	}
+	return signer.Encode(pos)
+}
+
+// parseQueryTime accepts a full RFC3339 timestamp or a plain date in UTC
+func parseQueryTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
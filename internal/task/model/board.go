@@ -0,0 +1,50 @@
+package model
+
+// ColumnKey names one board column: the tasks of a project in one status
+type ColumnKey struct {
+	ProjectID uint
+	Status    string
+}
+
+// BoardMoveRequest is the request model for moving a task on the board of its
+// project. The task lands between after_id, the task above, and before_id, the
+// task below; leaving one out puts it at that end of the column. With status it
+// changes column too.
+type BoardMoveRequest struct {
+	Status   *string `json:"status,omitempty" example:"review" validate:"omitempty,max=20"`
+	AfterID  *uint   `json:"after_id,omitempty" example:"3"`
+	BeforeID *uint   `json:"before_id,omitempty" example:"5"`
+	// OverrideWip lets whoever manages a full column's WIP limit move the task in anyway
+	OverrideWip bool `json:"override_wip,omitempty"`
+}
+
+// Board is the tasks of a project in one column per status, each in rank order
+type Board struct {
+	ProjectID uint
+	Columns   []BoardColumn
+}
+
+// BoardColumn is one status of a board with its tasks
+type BoardColumn struct {
+	Status   string
+	Name     string
+	Category string
+	Tasks    []Task
+	Limits   []WipLoad
+}
+
+// BoardResponse is the response model for a board
+type BoardResponse struct {
+	ProjectID uint                  `json:"project_id" example:"1"`
+	Columns   []BoardColumnResponse `json:"columns"`
+}
+
+// BoardColumnResponse is the response model for one column of a board
+type BoardColumnResponse struct {
+	Status   string         `json:"status" example:"review"`
+	Name     string         `json:"name" example:"In review"`
+	Category string         `json:"category" example:"in_progress"`
+	Tasks    []TaskResponse `json:"tasks"`
+	// WipLimits are the limits on the column with the current load against each
+	WipLimits []WipLoadResponse `json:"wip_limits,omitempty"`
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// CSVColumns are the columns of a task CSV, in the order they are exported
+var CSVColumns = []string{"title", "description", "due_date", "status", "priority", "labels"}
+
+// CSVLabelSeparator separates the label names in the labels column
+const CSVLabelSeparator = ";"
+
+// csvFormulaPrefixes start a cell a spreadsheet would run as a formula
+const csvFormulaPrefixes = "=+-@"
+
+// csvText escapes text a user wrote so a spreadsheet shows it as is: a cell that
+// would read as a formula, or that starts with the escape itself, gets a leading '
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaPrefixes+"'", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// fromCSVText undoes csvText
+func fromCSVText(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes+"'", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// ToCSVRecord writes task as a CSV row in the order of CSVColumns, its due date
+// in RFC 3339 and its labels by name. Title, description and label names are
+// escaped with csvText.
+func ToCSVRecord(task Task) []string {
+	due := ""
+	if task.DueDate != nil {
+		due = task.DueDate.UTC().Format(time.RFC3339)
+	}
+	labels := make([]string, 0, len(task.Labels))
+	for _, l := range task.Labels {
+		labels = append(labels, csvText(l.Name))
+	}
+	return []string{csvText(task.Title), csvText(task.Description), due, task.Status, task.Priority, strings.Join(labels, CSVLabelSeparator)}
+}
+
+// ImportRow is one row of an imported CSV read into a task request. Line is its
+// line in the file, the header being line 1. Status and Labels are by name, the
+// import resolves them.
+type ImportRow struct {
+	Line    int
+	Request CreateTaskRequest
+	Status  string `validate:"omitempty,max=20"`
+	Labels  []string
+	Errors  []ImportError // found while reading the row, it is not imported
+}
+
+// ToImportRow reads record into a row, columns giving the index of each CSV
+// column found in the file
+func ToImportRow(line int, record []string, columns map[string]int) ImportRow {
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	row := ImportRow{
+		Line: line,
+		Request: CreateTaskRequest{
+			Title:       fromCSVText(strings.TrimSpace(value("title"))),
+			Description: fromCSVText(value("description")),
+			Priority:    strings.ToLower(strings.TrimSpace(value("priority"))),
+		},
+		Status: strings.TrimSpace(value("status")),
+	}
+	if due := strings.TrimSpace(value("due_date")); due != "" {
+		parsed, err := time.Parse(time.RFC3339, due)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", due)
+		}
+		if err != nil {
+			row.Errors = append(row.Errors, ImportError{Line: line, Field: "due_date", Message: "expected an RFC 3339 time or a YYYY-MM-DD date"})
+		} else {
+			row.Request.DueDate = &parsed
+		}
+	}
+	for _, name := range strings.Split(value("labels"), CSVLabelSeparator) {
+		if name = fromCSVText(strings.TrimSpace(name)); name != "" {
+			row.Labels = append(row.Labels, name)
+		}
+	}
+	return row
+}
+
+// ImportField is the CSV column of a field of ImportRow or its request, for
+// reporting validation errors
+func ImportField(field string) string {
+	switch field {
+	case "Title":
+		return "title"
+	case "Description":
+		return "description"
+	case "DueDate":
+		return "due_date"
+	case "Status":
+		return "status"
+	case "Priority":
+		return "priority"
+	case "LabelIDs":
+		return "labels"
+	default:
+		return ""
+	}
+}
+
+// ImportError is why a row cannot be imported, Field naming the column at fault
+// when it is down to one
+type ImportError struct {
+	Line    int    `json:"line" example:"3"`
+	Field   string `json:"field,omitempty" example:"priority"`
+	Message string `json:"message" example:"must be one of none low medium high urgent"`
+}
+
+// ImportResult is the outcome of a CSV import, also its response model. A dry run
+// or an import with errors writes nothing, Valid counting the rows that would go in.
+type ImportResult struct {
+	DryRun   bool          `json:"dry_run" example:"true"`
+	Rows     int           `json:"rows" example:"20"`
+	Valid    int           `json:"valid" example:"19"`
+	Imported int           `json:"imported" example:"0"`
+	Errors   []ImportError `json:"errors"`
+}
@@ -0,0 +1,124 @@
+package model
+
+import "time"
+
+// Units a task is estimated in
+const (
+	UnitMinutes = "minutes"
+	UnitPoints  = "points"
+)
+
+// Sources of the actual effort of a task: the time logged on it, or else the time
+// from when it was started until it was done
+const (
+	ActualLogged  = "logged"
+	ActualDerived = "derived"
+)
+
+// EstimateQuery selects the estimated tasks completed in the days From to To, both
+// included, as they fall in the time zone TZ
+type EstimateQuery struct {
+	From        string `validate:"omitempty,datetime=2006-01-02"`
+	To          string `validate:"omitempty,datetime=2006-01-02"`
+	TZ          string `validate:"omitempty,max=64"`
+	ProjectID   *uint
+	WorkspaceID *uint // only the tasks of this workspace
+}
+
+// EstimateSample is one completed task measured against its estimate. Points are
+// turned into minutes at the rate the report's point estimated tasks took.
+type EstimateSample struct {
+	TaskID           uint
+	Title            string
+	WorkerID         uint
+	Unit             string
+	Estimate         float64 // in Unit
+	EstimatedMinutes float64
+	ActualMinutes    float64
+	Source           string
+	CompletedAt      time.Time
+	Ratio            float64 // actual over estimated, above 1 when the task took longer
+}
+
+// EstimateStats sums up how well a group of tasks was estimated. Ratio is the
+// actual effort over the estimated one, OnTarget the share of tasks that came
+// within EstimateMargin of their estimate.
+type EstimateStats struct {
+	Tasks            int
+	EstimatedMinutes float64
+	ActualMinutes    float64
+	Ratio            float64
+	OnTarget         float64
+}
+
+// EstimateMargin is how far off a task may be and still count as on target
+const EstimateMargin = 0.25
+
+// OutlierFactor is how far off a task has to be, either way, to be an outlier
+const OutlierFactor = 2.0
+
+// EstimateGroup is the stats of the tasks of one user, label or week
+type EstimateGroup struct {
+	Key  string
+	Name string
+	EstimateStats
+}
+
+// EstimateReport compares estimates with the actual effort of the tasks completed
+// in a period. Tasks without a measurable effort are only counted in Unmeasured.
+type EstimateReport struct {
+	From            time.Time
+	To              time.Time // exclusive
+	TZ              string
+	MinutesPerPoint float64
+	Unmeasured      int
+	Overall         EstimateStats
+	ByUser          []EstimateGroup
+	ByLabel         []EstimateGroup
+	ByWeek          []EstimateGroup
+	Outliers        []EstimateSample
+}
+
+// EstimateStatsResponse is the response model for EstimateStats
+type EstimateStatsResponse struct {
+	Tasks            int     `json:"tasks" example:"12"`
+	EstimatedMinutes float64 `json:"estimated_minutes" example:"1440"`
+	ActualMinutes    float64 `json:"actual_minutes" example:"1800"`
+	Ratio            float64 `json:"ratio" example:"1.25"`
+	OnTarget         float64 `json:"on_target" example:"0.5"`
+}
+
+// EstimateGroupResponse is the response model for an EstimateGroup
+type EstimateGroupResponse struct {
+	Key  string `json:"key" example:"2025-W10"`
+	Name string `json:"name,omitempty" example:"2025-03-03"`
+	EstimateStatsResponse
+}
+
+// EstimateSampleResponse is the response model for an outlier
+type EstimateSampleResponse struct {
+	TaskID           uint      `json:"task_id" example:"1"`
+	Title            string    `json:"title" example:"Write blog post"`
+	WorkerID         uint      `json:"worker_id" example:"2"`
+	Unit             string    `json:"unit" example:"points"`
+	Estimate         float64   `json:"estimate" example:"3"`
+	EstimatedMinutes float64   `json:"estimated_minutes" example:"180"`
+	ActualMinutes    float64   `json:"actual_minutes" example:"420"`
+	Source           string    `json:"source" example:"logged"`
+	CompletedAt      time.Time `json:"completed_at"`
+	Ratio            float64   `json:"ratio" example:"2.33"`
+}
+
+// EstimateReportResponse is the response model for an EstimateReport
+type EstimateReportResponse struct {
+	From            string                   `json:"from" example:"2025-03-01"`
+	To              string                   `json:"to" example:"2025-03-31"`
+	TZ              string                   `json:"tz" example:"Europe/Berlin"`
+	MinutesPerPoint float64                  `json:"minutes_per_point" example:"60"`
+	Unmeasured      int                      `json:"unmeasured" example:"2"`
+	Overall         EstimateStatsResponse    `json:"overall"`
+	ByUser          []EstimateGroupResponse  `json:"by_user"`
+	ByLabel         []EstimateGroupResponse  `json:"by_label"`
+	ByWeek          []EstimateGroupResponse  `json:"by_week"`
+	Outliers        []EstimateSampleResponse `json:"outliers"`
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions recorded in a task's history
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// SystemActorID is the actor of the changes the system makes on its own, such as
+// moving a task to overdue
+const SystemActorID uint = 0
+
+// TaskHistory is one immutable entry in the activity timeline of a task. Rows are
+// only ever inserted and stay when the task is soft deleted.
+type TaskHistory struct {
+	ID        uint              `gorm:"primaryKey" json:"id" example:"1"`
+	TaskID    uint              `gorm:"not null;index" json:"task_id" example:"1"`
+	ActorID   uint              `gorm:"not null;index" json:"actor_id" example:"1"`
+	Action    string            `gorm:"type:varchar(20);not null" json:"action" example:"updated"`
+	Changes   []TaskFieldChange `gorm:"foreignKey:HistoryID" json:"changes"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (TaskHistory) TableName() string {
+	return "task_history"
+}
+
+// TaskFieldChange is the old and new value of one field in a history entry, in
+// text form. A nil value means the field was unset.
+type TaskFieldChange struct {
+	ID        uint    `gorm:"primaryKey" json:"-"`
+	HistoryID uint    `gorm:"not null;index" json:"-"`
+	Field     string  `gorm:"type:varchar(30);not null" json:"field" example:"status"`
+	OldValue  *string `gorm:"type:text" json:"old_value" example:"in_progress"`
+	NewValue  *string `gorm:"type:text" json:"new_value" example:"completed"`
+}
+
+// TaskHistoryResponse is the response model for a history entry
+type TaskHistoryResponse struct {
+	ID        uint                  `json:"id" example:"1"`
+	ActorID   uint                  `json:"actor_id" example:"1"`
+	Action    string                `json:"action" example:"updated"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// FieldChangeResponse is the response model for one changed field
+type FieldChangeResponse struct {
+	Field    string  `json:"field" example:"status"`
+	OldValue *string `json:"old_value" example:"in_progress"`
+	NewValue *string `json:"new_value" example:"completed"`
+}
+
+// Audited fields of a task in the order their changes are listed. Dependencies
+// are recorded under "blocked_by" as the blocking task's ID.
+var auditedFields = []string{
+	"title", "description", "due_date", "status", "priority", "estimate",
+	"assignee_id", "project_id", "parent_id", "labels", "recurrence",
+}
+
+// TaskSnapshot holds the audited fields of a task in text form, nil when unset
+type TaskSnapshot map[string]*string
+
+// Snapshot captures the audited fields of task so they can be compared once it changed
+func Snapshot(task Task) TaskSnapshot {
+	snap := TaskSnapshot{
+		"title":       textValue(task.Title),
+		"description": textValue(task.Description),
+		"status":      textValue(task.Status),
+		"priority":    textValue(task.Priority),
+		"assignee_id": idValue(task.AssigneeID),
+		"project_id":  idValue(task.ProjectID),
+		"parent_id":   idValue(task.ParentID),
+	}
+	if task.DueDate != nil {
+		due := task.DueDate.UTC().Format(time.RFC3339)
+		snap["due_date"] = &due
+	}
+	switch {
+	case task.EstimateMinutes != nil:
+		estimate := strconv.Itoa(*task.EstimateMinutes) + "m"
+		snap["estimate"] = &estimate
+	case task.EstimatePoints != nil:
+		estimate := strconv.FormatFloat(*task.EstimatePoints, 'f', -1, 64) + "pt"
+		snap["estimate"] = &estimate
+	}
+	if len(task.Labels) > 0 {
+		ids := make([]int, 0, len(task.Labels))
+		for _, l := range task.Labels {
+			ids = append(ids, int(l.ID))
+		}
+		sort.Ints(ids)
+		parts := make([]string, len(ids))
+		for i, id := range ids {
+			parts[i] = strconv.Itoa(id)
+		}
+		labels := strings.Join(parts, ",")
+		snap["labels"] = &labels
+	}
+	if task.Series != nil && task.Series.EndedAt == nil {
+		snap["recurrence"] = textValue(task.Series.RRule)
+	}
+	return snap
+}
+
+// Diff lists the fields whose value differs between before and after
+func (before TaskSnapshot) Diff(after TaskSnapshot) []TaskFieldChange {
+	var changes []TaskFieldChange
+	for _, field := range auditedFields {
+		from, to := before[field], after[field]
+		if from == nil && to == nil || from != nil && to != nil && *from == *to {
+			continue
+		}
+		changes = append(changes, TaskFieldChange{Field: field, OldValue: from, NewValue: to})
+	}
+	return changes
+}
+
+// IDChange records an ID field going from one value to another, either may be nil
+func IDChange(field string, from, to *uint) TaskFieldChange {
+	return TaskFieldChange{Field: field, OldValue: idValue(from), NewValue: idValue(to)}
+}
+
+func textValue(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func idValue(id *uint) *string {
+	if id == nil {
+		return nil
+	}
+	s := strconv.FormatUint(uint64(*id), 10)
+	return &s
+}
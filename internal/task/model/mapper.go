@@ -1,13 +1,39 @@
 package model
 
+import (
+	labelModel "mymodule/internal/label/model"
+	"math"
+	"time"
+)
+
 func ToTask(req CreateTaskRequest, userID uint) Task {
+	priority := req.Priority
+	if priority == "" {
+		priority = PriorityNone
+	}
 	return Task{
 		Title:       req.Title,
 		Description: req.Description,
 		DueDate:     req.DueDate,
-		Status:      "pending", // default
+		Status:      StatusPending, // default, moved to its workflow's first status on create
+		Priority:    priority,
 		UserID:      userID,
+		ParentID:    req.ParentID,
+		ProjectID:   req.ProjectID,
+		AssigneeID:  req.AssigneeID,
+		Series:      seriesFromRule(req.Recurrence),
+		Labels:      labelsFromIDs(req.LabelIDs),
+		EstimateMinutes: req.EstimateMinutes,
+		EstimatePoints:  req.EstimatePoints,
+	}
+}
+
+// seriesFromRule carries the requested rule only, the usecase fills in the series
+func seriesFromRule(rule string) *TaskSeries {
+	if rule == "" {
+		return nil
 	}
+	return &TaskSeries{RRule: rule}
 }
 
 func ToTaskResponse(task Task) TaskResponse {
@@ -15,16 +41,78 @@ func ToTaskResponse(task Task) TaskResponse {
 		ID:          task.ID,
 		Title:       task.Title,
 		Status:      task.Status,
+		StatusCategory: task.StatusCategory,
+		Priority:    task.Priority,
+		CreatorID:   task.UserID,
+		AssigneeID:  task.AssigneeID,
+		WorkspaceID: task.WorkspaceID,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		SeriesID:    task.SeriesID,
+		EstimateMinutes: task.EstimateMinutes,
+		EstimatePoints:  task.EstimatePoints,
+		Labels:      labelModel.ToLabelResponseList(task.Labels),
 	}
 }
 
-func ToDetailTaskResponse(task Task) DetailTaskResponse {
+func ToDetailTaskResponse(detail TaskDetail) DetailTaskResponse {
+	task := detail.Task
 	return DetailTaskResponse{
 		Title:       task.Title,
 		Description: task.Description,
 		DueDate:     task.DueDate,
 		Status:      task.Status,
+		StatusCategory: task.StatusCategory,
+		Priority:    task.Priority,
+		CreatorID:   task.UserID,
+		AssigneeID:  task.AssigneeID,
+		WorkspaceID: task.WorkspaceID,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		Recurrence:  toRecurrenceResponse(task),
+		Progress:    detail.Progress,
+		EstimateMinutes: task.EstimateMinutes,
+		EstimatePoints:  task.EstimatePoints,
+		StatusEnteredAt: statusEnteredAt(task.StatusEntries),
+		Labels:      labelModel.ToLabelResponseList(task.Labels),
+		BlockedBy:   ToTaskResponseList(detail.BlockedBy),
+		Blocks:      ToTaskResponseList(detail.Blocks),
+	}
+}
+
+func statusEnteredAt(entries []TaskStatusEntry) map[string]time.Time {
+	if len(entries) == 0 {
+		return nil
+	}
+	entered := make(map[string]time.Time, len(entries))
+	for _, e := range entries {
+		entered[e.Status] = e.EnteredAt
+	}
+	return entered
+}
+
+func toRecurrenceResponse(task Task) *RecurrenceResponse {
+	if task.Series == nil {
+		return nil
+	}
+	return &RecurrenceResponse{
+		SeriesID:   task.Series.ID,
+		RRule:      task.Series.RRule,
+		Occurrence: task.Occurrence,
+		EndedAt:    task.Series.EndedAt,
+	}
+}
+
+// labelsFromIDs references labels by ID only, the usecase loads the real rows
+func labelsFromIDs(ids []uint) []labelModel.Label {
+	if len(ids) == 0 {
+		return nil
+	}
+	labels := make([]labelModel.Label, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, labelModel.Label{ID: id})
 	}
+	return labels
 }
 
 func ToTaskFromUpdate(req UpdateTaskInput) *Task {
@@ -44,6 +132,123 @@ func ToTaskResponseList(tasks []Task) []TaskResponse {
 	return res
 }
 
+func ToTrashedTaskResponseList(tasks []Task) []TrashedTaskResponse {
+	res := make([]TrashedTaskResponse, 0, len(tasks))
+	for _, t := range tasks {
+		res = append(res, TrashedTaskResponse{TaskResponse: ToTaskResponse(t), DeletedAt: t.DeletedAt.Time})
+	}
+	return res
+}
+
+func ToTaskListResponse(page TaskPage, filter TaskFilter, next, prev string) TaskListResponse {
+	return TaskListResponse{
+		Data: ToTaskResponseList(page.Tasks),
+		Meta: ListMeta{
+			Total:      page.Total,
+			Limit:      filter.Limit,
+			Offset:     filter.Offset,
+			NextCursor: next,
+			PrevCursor: prev,
+		},
+	}
+}
+
+// ToTaskCursor captures the sort key of task for the given listing order
+func ToTaskCursor(task Task, sortBy, order string, backward bool) *TaskCursor {
+	c := &TaskCursor{SortBy: sortBy, Order: order, ID: task.ID, Backward: backward}
+	switch sortBy {
+	case "updated_at":
+		c.Time = &task.UpdatedAt
+	case "due_date":
+		c.Time = task.DueDate
+	case "title":
+		c.Text = task.Title
+	case "status":
+		c.Text = task.Status
+	case "smart":
+		rank := SmartRank(task)
+		c.Rank = &rank
+		c.Time = task.DueDate
+	default:
+		c.SortBy = "created_at"
+		c.Time = &task.CreatedAt
+	}
+	return c
+}
+
+func ToTaskSearchResponseList(results []TaskSearchResult) []TaskSearchResponse {
+	res := make([]TaskSearchResponse, 0, len(results))
+	for _, r := range results {
+		res = append(res, TaskSearchResponse{
+			ID:                   r.ID,
+			Title:                r.Title,
+			Status:               r.Status,
+			DueDate:              r.DueDate,
+			TitleHighlight:       r.TitleHighlight,
+			DescriptionHighlight: r.DescriptionHighlight,
+			Rank:                 r.Rank,
+		})
+	}
+	return res
+}
+
+// ToTaskSeries makes the series that task is the first occurrence of
+func ToTaskSeries(task Task, rule string) *TaskSeries {
+	return &TaskSeries{
+		UserID:      task.UserID,
+		RRule:       rule,
+		StartsAt:    *task.DueDate,
+		LastDueAt:   *task.DueDate,
+		Generated:   1,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		ProjectID:   task.ProjectID,
+	}
+}
+
+// ToNextOccurrence copies the series template into the occurrence due at dueDate,
+// series.Generated must already count the new occurrence
+func ToNextOccurrence(series TaskSeries, previous Task, dueDate time.Time) Task {
+	return Task{
+		Title:       series.Title,
+		Description: series.Description,
+		DueDate:     &dueDate,
+		Status:      StatusPending, // moved to its workflow's first status when saved
+		Priority:    series.Priority,
+		UserID:      series.UserID,
+		AssigneeID:  previous.AssigneeID,
+		WorkspaceID: previous.WorkspaceID,
+		ParentID:    previous.ParentID,
+		ProjectID:   series.ProjectID,
+		SeriesID:    &series.ID,
+		Occurrence:  series.Generated,
+		Labels:      previous.Labels,
+		EstimateMinutes: previous.EstimateMinutes,
+		EstimatePoints:  previous.EstimatePoints,
+	}
+}
+
+// ApplySeriesUpdate carries edits made with the series scope over to the template
+func ApplySeriesUpdate(series *TaskSeries, input UpdateTaskInput) {
+	if input.Title != nil {
+		series.Title = *input.Title
+	}
+	if input.Description != nil {
+		series.Description = *input.Description
+	}
+	if input.Priority != nil {
+		series.Priority = *input.Priority
+	}
+	if input.ProjectID != nil {
+		if *input.ProjectID == 0 {
+			series.ProjectID = nil
+		} else {
+			series.ProjectID = input.ProjectID
+		}
+	}
+}
+
 func ApplyUpdate(existing *Task, input UpdateTaskInput) {
     if input.Title != nil {
         existing.Title = *input.Title
@@ -57,4 +262,173 @@ func ApplyUpdate(existing *Task, input UpdateTaskInput) {
     if input.Status != nil {
         existing.Status = *input.Status
     }
+    if input.Priority != nil {
+        existing.Priority = *input.Priority
+    }
+    if input.ProjectID != nil {
+        if *input.ProjectID == 0 {
+            existing.ProjectID = nil
+        } else {
+            existing.ProjectID = input.ProjectID
+        }
+    }
+    if input.AssigneeID != nil {
+        if *input.AssigneeID == 0 {
+            existing.AssigneeID = nil
+        } else {
+            existing.AssigneeID = input.AssigneeID
+        }
+    }
+    if input.EstimateMinutes != nil {
+        existing.EstimateMinutes, existing.EstimatePoints = nil, nil
+        if *input.EstimateMinutes != 0 {
+            existing.EstimateMinutes = input.EstimateMinutes
+        }
+    }
+    if input.EstimatePoints != nil {
+        existing.EstimateMinutes, existing.EstimatePoints = nil, nil
+        if *input.EstimatePoints != 0 {
+            existing.EstimatePoints = input.EstimatePoints
+        }
+    }
+}
+
+// OnlyStatus reports whether an update changes nothing but the status, the one
+// edit an assignee may make
+func OnlyStatus(input UpdateTaskInput) bool {
+    return input.Title == nil && input.Description == nil && input.DueDate == nil &&
+        input.Priority == nil && input.ProjectID == nil && input.AssigneeID == nil &&
+        input.Recurrence == nil && input.Scope == "" &&
+        input.EstimateMinutes == nil && input.EstimatePoints == nil &&
+        len(input.AddLabelIDs) == 0 && len(input.RemoveLabelIDs) == 0
+}
+
+func ToTaskHistoryResponseList(entries []TaskHistory) []TaskHistoryResponse {
+	resp := make([]TaskHistoryResponse, 0, len(entries))
+	for _, e := range entries {
+		changes := make([]FieldChangeResponse, 0, len(e.Changes))
+		for _, c := range e.Changes {
+			changes = append(changes, FieldChangeResponse{Field: c.Field, OldValue: c.OldValue, NewValue: c.NewValue})
+		}
+		resp = append(resp, TaskHistoryResponse{
+			ID:        e.ID,
+			ActorID:   e.ActorID,
+			Action:    e.Action,
+			Changes:   changes,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func ToBoardResponse(board Board) BoardResponse {
+	columns := make([]BoardColumnResponse, 0, len(board.Columns))
+	for _, c := range board.Columns {
+		columns = append(columns, BoardColumnResponse{
+			Status:    c.Status,
+			Name:      c.Name,
+			Category:  c.Category,
+			Tasks:     ToTaskResponseList(c.Tasks),
+			WipLimits: ToWipLoadResponseList(c.Limits),
+		})
+	}
+	return BoardResponse{ProjectID: board.ProjectID, Columns: columns}
+}
+
+func ToWipLimit(req WipLimitRequest, workspaceID *uint) WipLimit {
+	limit := WipLimit{ProjectID: req.ProjectID, Status: req.Status, Scope: req.Scope, Max: req.Max}
+	if req.ProjectID == nil {
+		limit.WorkspaceID = workspaceID
+	}
+	return limit
+}
+
+func ToWipLimitResponse(limit WipLimit) WipLimitResponse {
+	return WipLimitResponse{
+		ID:          limit.ID,
+		WorkspaceID: limit.WorkspaceID,
+		ProjectID:   limit.ProjectID,
+		Status:      limit.Status,
+		Scope:       limit.Scope,
+		Max:         limit.Max,
+	}
+}
+
+func ToWipLimitResponseList(limits []WipLimit) []WipLimitResponse {
+	resp := make([]WipLimitResponse, 0, len(limits))
+	for _, l := range limits {
+		resp = append(resp, ToWipLimitResponse(l))
+	}
+	return resp
+}
+
+func ToWipLoadResponseList(loads []WipLoad) []WipLoadResponse {
+	if len(loads) == 0 {
+		return nil
+	}
+	resp := make([]WipLoadResponse, 0, len(loads))
+	for _, l := range loads {
+		resp = append(resp, WipLoadResponse{
+			LimitID:     l.Limit.ID,
+			WorkspaceID: l.Limit.WorkspaceID,
+			ProjectID:   l.Limit.ProjectID,
+			Scope:       l.Limit.Scope,
+			Max:         l.Limit.Max,
+			Load:        l.Load,
+		})
+	}
+	return resp
+}
+
+func ToEstimateReportResponse(r EstimateReport) EstimateReportResponse {
+	outliers := make([]EstimateSampleResponse, 0, len(r.Outliers))
+	for _, s := range r.Outliers {
+		outliers = append(outliers, EstimateSampleResponse{
+			TaskID:           s.TaskID,
+			Title:            s.Title,
+			WorkerID:         s.WorkerID,
+			Unit:             s.Unit,
+			Estimate:         s.Estimate,
+			EstimatedMinutes: round2(s.EstimatedMinutes),
+			ActualMinutes:    round2(s.ActualMinutes),
+			Source:           s.Source,
+			CompletedAt:      s.CompletedAt,
+			Ratio:            round2(s.Ratio),
+		})
+	}
+	loc := r.From.Location()
+	return EstimateReportResponse{
+		From:            r.From.Format("2006-01-02"),
+		To:              r.To.In(loc).AddDate(0, 0, -1).Format("2006-01-02"),
+		TZ:              r.TZ,
+		MinutesPerPoint: round2(r.MinutesPerPoint),
+		Unmeasured:      r.Unmeasured,
+		Overall:         toEstimateStatsResponse(r.Overall),
+		ByUser:          toEstimateGroupResponseList(r.ByUser),
+		ByLabel:         toEstimateGroupResponseList(r.ByLabel),
+		ByWeek:          toEstimateGroupResponseList(r.ByWeek),
+		Outliers:        outliers,
+	}
+}
+
+func toEstimateStatsResponse(s EstimateStats) EstimateStatsResponse {
+	return EstimateStatsResponse{
+		Tasks:            s.Tasks,
+		EstimatedMinutes: round2(s.EstimatedMinutes),
+		ActualMinutes:    round2(s.ActualMinutes),
+		Ratio:            round2(s.Ratio),
+		OnTarget:         round2(s.OnTarget),
+	}
+}
+
+func toEstimateGroupResponseList(groups []EstimateGroup) []EstimateGroupResponse {
+	resp := make([]EstimateGroupResponse, 0, len(groups))
+	for _, g := range groups {
+		resp = append(resp, EstimateGroupResponse{Key: g.Key, Name: g.Name, EstimateStatsResponse: toEstimateStatsResponse(g.EstimateStats)})
+	}
+	return resp
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
 }
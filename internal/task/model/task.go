@@ -1,6 +1,7 @@
 package model
 
 import (
+	labelModel "mymodule/internal/label/model"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,18 +13,227 @@ type Task struct {
 	Title       string     `gorm:"type:text;not null" json:"title" example:"Write blog post" validate:"required"`
 	Description string     `gorm:"type:text" json:"description" example:"Write about Clean Architecture"`
 	DueDate     *time.Time `gorm:"default:null" json:"due_date,omitempty" example:"2025-08-10T15:00:00Z"`
-	Status      string     `gorm:"type:varchar(20);default:'pending'" json:"status" example:"pending" validate:"oneof=pending in_progress completed"`
-	UserID      uint       `gorm:"not null" json:"user_id" example:"1"`
+	Status      string     `gorm:"type:varchar(20);default:'pending'" json:"status" example:"pending" validate:"max=20"` // a status of the task's workflow
+	StatusCategory string  `gorm:"type:varchar(20);not null;default:'todo'" json:"status_category" example:"todo"`
+	Priority    string     `gorm:"type:varchar(10);not null;default:'none'" json:"priority" example:"high" validate:"oneof=none low medium high urgent"`
+	UserID      uint       `gorm:"not null" json:"user_id" example:"1"` // the creator
+	AssigneeID  *uint      `gorm:"index;default:null" json:"assignee_id,omitempty" example:"2"`
+	WorkspaceID *uint      `gorm:"index;default:null" json:"workspace_id,omitempty" example:"1"`
+	ParentID    *uint      `gorm:"index;default:null" json:"parent_id,omitempty" example:"1"`
+	ProjectID   *uint      `gorm:"index;default:null" json:"project_id,omitempty" example:"1"`
+	SeriesID    *uint      `gorm:"index;default:null" json:"series_id,omitempty" example:"1"`
+	Occurrence  int        `gorm:"not null;default:0" json:"occurrence,omitempty" example:"3"`
+	BoardRank   string     `gorm:"type:varchar(64);default:null" json:"board_rank,omitempty" example:"i"` // place in its board column, see pkg/rank
+	EstimateMinutes *int     `gorm:"default:null" json:"estimate_minutes,omitempty" example:"90"`
+	EstimatePoints  *float64 `gorm:"type:numeric(6,1);default:null" json:"estimate_points,omitempty" example:"3"` // a task is estimated in minutes or in points, not both
+	Series      *TaskSeries `gorm:"foreignKey:SeriesID" json:"-"`
+	Labels      []labelModel.Label `gorm:"many2many:task_labels" json:"labels,omitempty"`
+	StatusEntries []TaskStatusEntry `gorm:"foreignKey:TaskID" json:"-"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// VisibleTo limits a tasks query to the rows userID may see: every task of the
+// workspaces they belong to, the tasks assigned to them and their own tasks that
+// are in no workspace
+func VisibleTo(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`(tasks.assignee_id = ?
+			OR tasks.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+			OR (tasks.workspace_id IS NULL AND tasks.user_id = ?))`, userID, userID, userID)
+	}
+}
+
+// Owner is whose labels and projects a task may use: those of every member of its
+// workspace, or outside one those of its creator
+type Owner struct {
+	WorkspaceID *uint
+	UserID      uint
+}
+
+// OwnerOf is the Owner of the labels and projects task may use
+func OwnerOf(task Task) Owner {
+	return Owner{WorkspaceID: task.WorkspaceID, UserID: task.UserID}
+}
+
+// OwnedBy limits a query on a table with a user_id column, such as labels or
+// projects, to the rows of owner
+func OwnedBy(owner Owner) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if owner.WorkspaceID != nil {
+			return db.Where("user_id IN (SELECT user_id FROM workspace_members WHERE workspace_id = ?)", *owner.WorkspaceID)
+		}
+		return db.Where("user_id = ?", owner.UserID)
+	}
+}
+
+// Built-in statuses of a task, used unless its project has a workflow of its own.
+// Overdue belongs to every workflow and is only ever set by the system, once the
+// due date of an open task has passed.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusOverdue    = "overdue"
+)
+
+// Categories every status falls in, whichever workflow it belongs to. Overdue
+// counts as todo.
+const (
+	CategoryTodo       = "todo"
+	CategoryInProgress = "in_progress"
+	CategoryDone       = "done"
+)
+
+// Done reports whether the task is in a status of the done category
+func (t Task) Done() bool {
+	return t.StatusCategory == CategoryDone
+}
+
+// Worker is who works on the task: its assignee, or else its creator
+func (t Task) Worker() uint {
+	if t.AssigneeID != nil {
+		return *t.AssigneeID
+	}
+	return t.UserID
+}
+
+// TaskStatusEntry records when a task last entered a status
+type TaskStatusEntry struct {
+	TaskID    uint      `gorm:"primaryKey" json:"-"`
+	Status    string    `gorm:"primaryKey;type:varchar(20)" json:"status" example:"in_progress"`
+	EnteredAt time.Time `gorm:"not null" json:"entered_at"`
+}
+
+// Priorities in ascending order of importance
+const (
+	PriorityNone   = "none"
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// PriorityRanks weighs each priority for the smart ordering, higher comes first
+var PriorityRanks = map[string]int{
+	PriorityNone:   0,
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+// OverdueRank lifts overdue tasks above every priority in the smart ordering
+const OverdueRank = 10
+
+// SmartRank is the urgency of a task: overdue first, then by priority
+func SmartRank(task Task) int {
+	rank := PriorityRanks[task.Priority]
+	if task.Status == StatusOverdue {
+		rank += OverdueRank
+	}
+	return rank
+}
+
+// TaskSeries is a recurring task: its rule, how far it has run and the template
+// every generated occurrence is copied from
+type TaskSeries struct {
+	ID          uint       `gorm:"primaryKey" json:"id" example:"1"`
+	UserID      uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	RRule       string     `gorm:"type:text;not null" json:"rrule" example:"FREQ=WEEKLY;BYDAY=MO"`
+	StartsAt    time.Time  `gorm:"not null" json:"starts_at"`   // due date of the first occurrence
+	LastDueAt   time.Time  `gorm:"not null" json:"last_due_at"` // scheduled due date of the latest occurrence
+	Generated   int        `gorm:"not null;default:1" json:"generated" example:"3"`
+	Title       string     `gorm:"type:text;not null" json:"title" example:"Take out the bins"`
+	Description string     `gorm:"type:text" json:"description"`
+	Priority    string     `gorm:"type:varchar(10);not null;default:'none'" json:"priority" example:"low"`
+	ProjectID   *uint      `gorm:"default:null" json:"project_id,omitempty" example:"1"`
+	EndedAt     *time.Time `gorm:"default:null" json:"ended_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Edit scopes of a recurring task
+const (
+	ScopeOccurrence = "occurrence"
+	ScopeSeries     = "series"
+)
+
+// TaskDependency records that TaskID cannot progress until BlockedByID is completed
+type TaskDependency struct {
+	TaskID      uint      `gorm:"primaryKey" json:"task_id" example:"1"`
+	BlockedByID uint      `gorm:"primaryKey;index" json:"blocked_by_id" example:"2"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 // CreateTaskRequest is the request model for creating a task
 type CreateTaskRequest struct {
 	Title       string     `json:"title" example:"Write blog post" validate:"required"`
 	Description string     `json:"description,omitempty" example:"Write about Clean Architecture"`
 	DueDate     *time.Time `json:"due_date,omitempty" example:"2025-08-10T15:00:00Z"`
+	Priority    string     `json:"priority,omitempty" example:"high" validate:"omitempty,oneof=none low medium high urgent"`
+	ParentID    *uint      `json:"parent_id,omitempty" example:"1"`
+	ProjectID   *uint      `json:"project_id,omitempty" example:"1"`
+	AssigneeID  *uint      `json:"assignee_id,omitempty" example:"2"`
+	Recurrence  string     `json:"recurrence,omitempty" example:"FREQ=WEEKLY;BYDAY=MO"`
+	LabelIDs    []uint     `json:"label_ids,omitempty" example:"1,2"`
+	EstimateMinutes *int     `json:"estimate_minutes,omitempty" example:"90" validate:"omitempty,min=1,max=100000,excluded_with=EstimatePoints"`
+	EstimatePoints  *float64 `json:"estimate_points,omitempty" example:"3" validate:"omitempty,gt=0,max=1000"`
+}
+
+// MoveTaskRequest is the request model for moving a task under another parent,
+// a null parent_id makes it a top-level task
+type MoveTaskRequest struct {
+	ParentID *uint `json:"parent_id" example:"1"`
+}
+
+// AddDependencyRequest is the request model for marking a task as blocked by another
+type AddDependencyRequest struct {
+	BlockedByID uint `json:"blocked_by_id" example:"2" validate:"required"`
+}
+
+// Operations of a bulk request
+const (
+	BulkSetStatus   = "set_status"
+	BulkSetDueDate  = "set_due_date"
+	BulkAddLabel    = "add_label"
+	BulkRemoveLabel = "remove_label"
+	BulkDelete      = "delete"
+	BulkRestore     = "restore"
+)
+
+// BulkRequest is the request model for applying one operation to many tasks. With
+// atomic set a single failure leaves every task as it was.
+type BulkRequest struct {
+	Operation string     `json:"operation" example:"set_status" validate:"required,oneof=set_status set_due_date add_label remove_label delete restore"`
+	TaskIDs   []uint     `json:"task_ids" example:"1,2,3" validate:"required,min=1,max=100,dive,required"`
+	Status    *string    `json:"status,omitempty" example:"completed" validate:"required_if=Operation set_status,omitempty,max=20"`
+	DueDate   *time.Time `json:"due_date,omitempty" example:"2025-08-10T15:00:00Z" validate:"required_if=Operation set_due_date"`
+	LabelID   *uint      `json:"label_id,omitempty" example:"1" validate:"required_if=Operation add_label,required_if=Operation remove_label"`
+	Atomic    bool       `json:"atomic" example:"true"`
+}
+
+// Outcomes of one task in a bulk request
+const (
+	BulkOK         = "ok"
+	BulkNotFound   = "not_found"
+	BulkForbidden  = "forbidden"
+	BulkInvalid    = "invalid"
+	BulkRolledBack = "rolled_back" // it went through, but an atomic request failed elsewhere
+)
+
+// BulkItemResult is the outcome of a bulk operation on one task
+type BulkItemResult struct {
+	TaskID uint   `json:"task_id" example:"1"`
+	Status string `json:"status" example:"ok"`
+	Error  string `json:"error,omitempty" example:"task is blocked by unfinished tasks"`
+}
+
+// BulkResult is the outcome of a bulk request, also its response model
+type BulkResult struct {
+	Committed bool             `json:"committed" example:"true"`
+	Results   []BulkItemResult `json:"results"`
 }
 
 // UpdateTaskRequest is the request model for updating a task
@@ -31,7 +241,17 @@ type UpdateTaskInput struct {
     Title       *string     `json:"title,omitempty"`
     Description *string     `json:"description,omitempty"`
     DueDate     *time.Time  `json:"due_date,omitempty"`
-    Status      *string     `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed"`
+    Status      *string     `json:"status,omitempty" validate:"omitempty,max=20"` // a status of the task's workflow, sending the current one back is a no-op
+    Priority    *string     `json:"priority,omitempty" validate:"omitempty,oneof=none low medium high urgent"`
+    ProjectID   *uint       `json:"project_id,omitempty"` // 0 takes the task out of its project
+    AssigneeID  *uint       `json:"assignee_id,omitempty"` // 0 unassigns the task
+    Recurrence  *string     `json:"recurrence,omitempty" example:"FREQ=MONTHLY;BYMONTHDAY=1"`
+    Scope       string      `json:"scope,omitempty" example:"series" validate:"omitempty,oneof=occurrence series"` // a recurring task's edits apply to this occurrence by default
+    AddLabelIDs    []uint   `json:"add_label_ids,omitempty"`
+    RemoveLabelIDs []uint   `json:"remove_label_ids,omitempty"`
+    OverrideWip    bool     `json:"override_wip,omitempty"` // lets whoever manages a full column's WIP limit move the task in anyway
+    EstimateMinutes *int     `json:"estimate_minutes,omitempty" validate:"omitempty,min=0,max=100000,excluded_with=EstimatePoints"` // 0 clears the estimate, either unit replaces the other
+    EstimatePoints  *float64 `json:"estimate_points,omitempty" validate:"omitempty,min=0,max=1000"`
 }
 
 // TaskResponse is the response model for a task
@@ -39,11 +259,142 @@ type TaskResponse struct {
 	ID          uint       `json:"id" example:"1"`
 	Title       string     `json:"title" example:"Write blog post"`
 	Status      string     `json:"status" example:"pending"`
+	StatusCategory string  `json:"status_category" example:"todo"`
+	Priority    string     `json:"priority" example:"high"`
+	CreatorID   uint       `json:"creator_id" example:"1"`
+	AssigneeID  *uint      `json:"assignee_id,omitempty" example:"2"`
+	WorkspaceID *uint      `json:"workspace_id,omitempty" example:"1"`
+	ParentID    *uint      `json:"parent_id,omitempty" example:"1"`
+	ProjectID   *uint      `json:"project_id,omitempty" example:"1"`
+	SeriesID    *uint      `json:"series_id,omitempty" example:"1"`
+	EstimateMinutes *int     `json:"estimate_minutes,omitempty" example:"90"`
+	EstimatePoints  *float64 `json:"estimate_points,omitempty" example:"3"`
+	Labels      []labelModel.LabelResponse `json:"labels"`
 }
+// TrashedTaskResponse is the response model for a task in the trash
+type TrashedTaskResponse struct {
+	TaskResponse
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
 type DetailTaskResponse struct {
 	Title       string     `json:"title" example:"Write blog post"`
 	Description string     `json:"description" example:"Write about Clean Architecture"`
 	DueDate     *time.Time `json:"due_date,omitempty" example:"2025-08-10T15:00:00Z"`
 	Status      string     `json:"status" example:"pending"`
+	StatusCategory string  `json:"status_category" example:"todo"`
+	Priority    string     `json:"priority" example:"high"`
+	CreatorID   uint       `json:"creator_id" example:"1"`
+	AssigneeID  *uint      `json:"assignee_id,omitempty" example:"2"`
+	WorkspaceID *uint      `json:"workspace_id,omitempty" example:"1"`
+	ParentID    *uint      `json:"parent_id,omitempty" example:"1"`
+	ProjectID   *uint      `json:"project_id,omitempty" example:"1"`
+	Recurrence  *RecurrenceResponse `json:"recurrence,omitempty"`
+	Progress    *float64   `json:"progress,omitempty" example:"66.67"`
+	EstimateMinutes *int     `json:"estimate_minutes,omitempty" example:"90"`
+	EstimatePoints  *float64 `json:"estimate_points,omitempty" example:"3"`
+	StatusEnteredAt map[string]time.Time `json:"status_entered_at,omitempty"` // when the task last entered each status
+	Labels      []labelModel.LabelResponse `json:"labels"`
+	BlockedBy   []TaskResponse `json:"blocked_by"`
+	Blocks      []TaskResponse `json:"blocks"`
+}
+
+// RecurrenceResponse describes the series a recurring task belongs to
+type RecurrenceResponse struct {
+	SeriesID   uint       `json:"series_id" example:"1"`
+	RRule      string     `json:"rrule" example:"FREQ=WEEKLY;BYDAY=MO"`
+	Occurrence int        `json:"occurrence" example:"3"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
 }
 
+// TaskDetail is a task together with what is derived from related rows
+type TaskDetail struct {
+	Task      Task
+	Progress  *float64
+	BlockedBy []Task
+	Blocks    []Task
+}
+
+// Values of TaskFilter.Mine
+const (
+	MineAssigned = "assigned" // tasks assigned to me
+	MineCreated  = "created"  // tasks I created
+)
+
+// TaskFilter holds the query options for listing the tasks a user can see
+type TaskFilter struct {
+	WorkspaceID *uint // only the tasks of this workspace
+	UserID      *uint // admin listings only: the tasks this user created
+	Status      []string   `validate:"omitempty,dive,required,max=20"`
+	DueFrom     *time.Time
+	DueTo       *time.Time
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	SortBy      string `validate:"omitempty,oneof=smart created_at updated_at due_date title status"`
+	Order       string `validate:"omitempty,oneof=asc desc"`
+	Limit       int    `validate:"min=0,max=100"`
+	Offset      int    `validate:"min=0"`
+	Cursor      *TaskCursor
+	LabelIDs    []uint
+	LabelMatch  string `validate:"omitempty,oneof=any all"`
+	ProjectID   *uint
+	Mine        string `validate:"omitempty,oneof=assigned created"`
+}
+
+// TaskCursor marks a position in a sorted task listing by (sort key, id)
+type TaskCursor struct {
+	SortBy   string     `json:"s"`
+	Order    string     `json:"o"`
+	Time     *time.Time `json:"t,omitempty"`
+	Text     string     `json:"x,omitempty"`
+	Rank     *int       `json:"r,omitempty"`
+	ID       uint       `json:"i"`
+	Backward bool       `json:"b,omitempty"`
+}
+
+// TaskPage is one page of a task listing with what is needed to fetch its neighbours
+type TaskPage struct {
+	Tasks []Task
+	Total int64
+	Next  *TaskCursor
+	Prev  *TaskCursor
+}
+
+// TaskListResponse is the response model for a page of tasks
+type TaskListResponse struct {
+	Data []TaskResponse `json:"data"`
+	Meta ListMeta       `json:"meta"`
+}
+
+// ListMeta carries the pagination metadata of a task listing
+type ListMeta struct {
+	Total      int64  `json:"total" example:"42"`
+	Limit      int    `json:"limit" example:"20"`
+	Offset     int    `json:"offset" example:"0"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// TaskSearchResult is a task matched by full-text search, best match first
+type TaskSearchResult struct {
+	ID                   uint
+	Title                string
+	Status               string
+	DueDate              *time.Time
+	TitleHighlight       string
+	DescriptionHighlight string
+	Rank                 float64
+}
+
+// TaskSearchResponse is the response model for a search hit
+type TaskSearchResponse struct {
+	ID                   uint       `json:"id" example:"1"`
+	Title                string     `json:"title" example:"Write blog post"`
+	Status               string     `json:"status" example:"pending"`
+	DueDate              *time.Time `json:"due_date,omitempty" example:"2025-08-10T15:00:00Z"`
+	TitleHighlight       string     `json:"title_highlight" example:"Write <mark>blog</mark> post"`
+	DescriptionHighlight string     `json:"description_highlight" example:"Write about <mark>Clean</mark> Architecture"`
+	Rank                 float64    `json:"rank" example:"0.6"`
+}
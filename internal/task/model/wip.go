@@ -0,0 +1,101 @@
+package model
+
+import "time"
+
+// Scopes of a WIP limit: what one limit counts the tasks of
+const (
+	WipPerUser    = "user"    // the tasks each user works on, their assignee or else their creator
+	WipPerProject = "project" // the tasks of each project
+)
+
+// WipLimit caps how many tasks may be in one status at a time. A workspace sets it
+// for its tasks, a project for its own; both apply to a task in either.
+type WipLimit struct {
+	ID          uint      `gorm:"primaryKey" json:"id" example:"1"`
+	WorkspaceID *uint     `gorm:"index;default:null" json:"workspace_id,omitempty" example:"1"`
+	ProjectID   *uint     `gorm:"index;default:null" json:"project_id,omitempty" example:"1"`
+	Status      string    `gorm:"type:varchar(20);not null" json:"status" example:"in_progress"`
+	Scope       string    `gorm:"type:varchar(10);not null" json:"scope" example:"user"`
+	Max         int       `gorm:"not null" json:"max" example:"3"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WipQuery selects the tasks that count against a WIP limit
+type WipQuery struct {
+	Status      string
+	WorkspaceID *uint
+	ProjectID   *uint
+	WorkerID    *uint
+	ExcludeID   uint // the task being moved, which does not count against itself
+}
+
+// Bucket is the query counting the tasks that share the limit with task, false
+// when the limit does not apply to it
+func (l WipLimit) Bucket(task Task) (WipQuery, bool) {
+	if task.Status != l.Status {
+		return WipQuery{}, false
+	}
+	query := WipQuery{Status: l.Status, ExcludeID: task.ID}
+	switch {
+	case l.WorkspaceID != nil:
+		if task.WorkspaceID == nil || *task.WorkspaceID != *l.WorkspaceID {
+			return WipQuery{}, false
+		}
+		query.WorkspaceID = l.WorkspaceID
+	case l.ProjectID != nil:
+		if task.ProjectID == nil || *task.ProjectID != *l.ProjectID {
+			return WipQuery{}, false
+		}
+		query.ProjectID = l.ProjectID
+	}
+
+	switch l.Scope {
+	case WipPerProject:
+		if task.ProjectID == nil {
+			return WipQuery{}, false
+		}
+		query.ProjectID = task.ProjectID
+	case WipPerUser:
+		worker := task.Worker()
+		query.WorkerID = &worker
+	}
+	return query, true
+}
+
+// WipLimitRequest is the request model for setting a WIP limit, on the project
+// when one is given and on the active workspace otherwise. A status has one limit
+// per workspace or project, setting it again replaces it.
+type WipLimitRequest struct {
+	ProjectID *uint  `json:"project_id,omitempty" example:"1"`
+	Status    string `json:"status" example:"in_progress" validate:"required,max=20"`
+	Scope     string `json:"scope" example:"user" validate:"required,oneof=user project"`
+	Max       int    `json:"max" example:"3" validate:"required,min=1,max=1000"`
+}
+
+// WipLimitResponse is the response model for a WIP limit
+type WipLimitResponse struct {
+	ID          uint   `json:"id" example:"1"`
+	WorkspaceID *uint  `json:"workspace_id,omitempty" example:"1"`
+	ProjectID   *uint  `json:"project_id,omitempty" example:"1"`
+	Status      string `json:"status" example:"in_progress"`
+	Scope       string `json:"scope" example:"user"`
+	Max         int    `json:"max" example:"3"`
+}
+
+// WipLoad is how close a board column is to one of its limits. For a per user
+// limit the load is that of the user looking at the board.
+type WipLoad struct {
+	Limit WipLimit
+	Load  int64
+}
+
+// WipLoadResponse is the response model for a WipLoad
+type WipLoadResponse struct {
+	LimitID     uint   `json:"limit_id" example:"1"`
+	WorkspaceID *uint  `json:"workspace_id,omitempty" example:"1"`
+	ProjectID   *uint  `json:"project_id,omitempty" example:"1"`
+	Scope       string `json:"scope" example:"user"`
+	Max         int    `json:"max" example:"3"`
+	Load        int64  `json:"load" example:"2"`
+}
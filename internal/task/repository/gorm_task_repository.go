@@ -1,12 +1,24 @@
 package repository
 
 import (
+	"html"
+	labelModel "mymodule/internal/label/model"
+	projectModel "mymodule/internal/project/model"
+	reminderRepo "mymodule/internal/reminder/repository"
 	"mymodule/internal/task/model"
 	"mymodule/internal/task/usecase"
+	timeEntryRepo "mymodule/internal/timeentry/repository"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
 	"mymodule/pkg/logger"
+	"mymodule/pkg/rank"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormTaskRepository struct {
@@ -17,18 +29,28 @@ func NewGormTaskRepository(db *gorm.DB) usecase.TaskRepository {
 	return &GormTaskRepository{db: db}
 }
 
-func (r *GormTaskRepository) Save(task model.Task) error {
-	if err := r.db.Create(&task).Error; err != nil {
-		logger.LogTask(task).Error("Failed to save task")
+// Transaction runs fn with a repository whose every method works inside one
+// database transaction, committed when fn returns nil. Calling Transaction on
+// that repository again opens a savepoint.
+func (r *GormTaskRepository) Transaction(fn func(repo usecase.TaskRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormTaskRepository{db: tx})
+	})
+}
+
+func (r *GormTaskRepository) Save(task *model.Task) error {
+	// Link labels through task_labels without touching the label rows themselves
+	if err := r.db.Omit("Labels.*", "Series").Create(task).Error; err != nil {
+		logger.LogTask(*task).Error("Failed to save task")
 		return err
 	}
-	logger.LogTask(task).Info("Task saved successfully")
+	logger.LogTask(*task).Info("Task saved successfully")
 	return nil
 }
 
 func (r *GormTaskRepository) FindByID(taskID uint) (*model.Task, error) {
 	var task model.Task
-	if err := r.db.First(&task, taskID).Error; err != nil {
+	if err := r.db.Preload("Labels").Preload("Series").Preload("StatusEntries").First(&task, taskID).Error; err != nil {
 		logger.Log.WithField("taskID", taskID).Error("Failed to find task by ID")
 		return nil, err
 	}
@@ -36,19 +58,82 @@ func (r *GormTaskRepository) FindByID(taskID uint) (*model.Task, error) {
 	return &task, nil
 }
 
-func (r *GormTaskRepository) FindByUser(userID uint) (*[]model.Task, error) {
-	var tasks []model.Task
-	if err := r.db.Where("user_id = ?", userID).Find(&tasks).Error; err != nil {
+func (r *GormTaskRepository) FindByUser(userID uint, filter model.TaskFilter) (*[]model.Task, int64, error) {
+	query := r.db.Model(&model.Task{}).Scopes(model.VisibleTo(userID))
+	if filter.WorkspaceID != nil {
+		query = query.Where("tasks.workspace_id = ?", *filter.WorkspaceID)
+	}
+	switch filter.Mine {
+	case model.MineCreated:
+		query = query.Where("tasks.user_id = ?", userID)
+	case model.MineAssigned:
+		query = query.Where("tasks.assignee_id = ?", userID)
+	}
+
+	tasks, total, err := findPage(query, filter)
+	if err != nil {
 		logger.Log.WithField("userID", userID).Error("Failed to find tasks by user ID")
-		return nil, err
+		return nil, 0, err
 	}
 	logger.Log.WithField("userID", userID).Info("Tasks found by user ID")
-	return &tasks, nil
+	return tasks, total, nil
+}
+
+// FindAll lists the tasks of every user, filter.UserID narrows it to one creator
+func (r *GormTaskRepository) FindAll(filter model.TaskFilter) (*[]model.Task, int64, error) {
+	query := r.db.Model(&model.Task{})
+	if filter.UserID != nil {
+		query = query.Where("tasks.user_id = ?", *filter.UserID)
+	}
+	if filter.WorkspaceID != nil {
+		query = query.Where("tasks.workspace_id = ?", *filter.WorkspaceID)
+	}
+
+	tasks, total, err := findPage(query, filter)
+	if err != nil {
+		logger.Log.Error("Failed to find all tasks: ", err)
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+// findPage applies the filter, ordering and paging to a tasks query and counts
+// the rows matching before paging
+func findPage(query *gorm.DB, filter model.TaskFilter) (*[]model.Task, int64, error) {
+	var tasks []model.Task
+	var total int64
+
+	query = applyTaskFilter(query, filter).Session(&gorm.Session{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	if filter.Cursor != nil {
+		query = applyTaskCursor(query, *filter.Cursor)
+	} else if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	query = applyTaskOrder(query, filter.SortBy, filter.Order, backward)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if err := query.Preload("Labels").Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	// Backward pages are read in reverse, put them back in listing order
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+	return &tasks, total, nil
 }
 
 func (r *GormTaskRepository) FindByIDAndUser(taskID, userID uint) (*model.Task, error) {
 	var task model.Task
-	if err := r.db.Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error; err != nil {
+	if err := r.db.Scopes(model.VisibleTo(userID)).Preload("Labels").Preload("Series").Preload("StatusEntries").Where("id = ?", taskID).First(&task).Error; err != nil {
 		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task by ID and user ID")
 		return nil, err
 	}
@@ -56,8 +141,141 @@ func (r *GormTaskRepository) FindByIDAndUser(taskID, userID uint) (*model.Task,
 	return &task, nil
 }
 
+func (r *GormTaskRepository) FindChildren(taskID, userID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	if err := r.db.Scopes(relatedTo(taskID, userID)).Preload("Labels").Where("parent_id = ?", taskID).Order("id").Find(&tasks).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find subtasks")
+		return nil, err
+	}
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Subtasks found")
+	return &tasks, nil
+}
+
+// FindDescendants returns every subtask below taskID, at any depth
+func (r *GormTaskRepository) FindDescendants(taskID, userID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	tree := r.db.Raw(`WITH RECURSIVE tree AS (
+			SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN tree ON t.parent_id = tree.id WHERE t.deleted_at IS NULL
+		) SELECT id FROM tree`, taskID)
+	if err := r.db.Scopes(relatedTo(taskID, userID)).Where("id IN (?)", tree).Order("id").Find(&tasks).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find descendant tasks")
+		return nil, err
+	}
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Descendant tasks found")
+	return &tasks, nil
+}
+
+func (r *GormTaskRepository) AddDependency(taskID, blockedByID uint) error {
+	dep := model.TaskDependency{TaskID: taskID, BlockedByID: blockedByID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep).Error; err != nil {
+		logger.Log.WithFields(map[string]interface{}{"taskID": taskID, "blockedByID": blockedByID}).Error("Failed to add dependency")
+		return err
+	}
+	logger.Log.WithFields(map[string]interface{}{"taskID": taskID, "blockedByID": blockedByID}).Info("Dependency added")
+	return nil
+}
+
+func (r *GormTaskRepository) RemoveDependency(taskID, blockedByID uint) error {
+	result := r.db.Where("task_id = ? AND blocked_by_id = ?", taskID, blockedByID).Delete(&model.TaskDependency{})
+	if result.Error != nil {
+		logger.Log.WithFields(map[string]interface{}{"taskID": taskID, "blockedByID": blockedByID}).Error("Failed to remove dependency")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	logger.Log.WithFields(map[string]interface{}{"taskID": taskID, "blockedByID": blockedByID}).Info("Dependency removed")
+	return nil
+}
+
+// FindBlockers returns the tasks that taskID is blocked by
+func (r *GormTaskRepository) FindBlockers(taskID, userID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	if err := r.db.Scopes(relatedTo(taskID, userID)).Preload("Labels").
+		Joins("JOIN task_dependencies ON task_dependencies.blocked_by_id = tasks.id").
+		Where("task_dependencies.task_id = ?", taskID).
+		Order("tasks.id").Find(&tasks).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find blocking tasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// FindBlocked returns the tasks that are blocked by taskID
+func (r *GormTaskRepository) FindBlocked(taskID, userID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	if err := r.db.Scopes(relatedTo(taskID, userID)).Preload("Labels").
+		Joins("JOIN task_dependencies ON task_dependencies.task_id = tasks.id").
+		Where("task_dependencies.blocked_by_id = ?", taskID).
+		Order("tasks.id").Find(&tasks).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find blocked tasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// DependsOn reports whether taskID is blocked by blockerID, directly or through other tasks
+func (r *GormTaskRepository) DependsOn(taskID, blockerID uint) (bool, error) {
+	var count int64
+	err := r.db.Raw(`WITH RECURSIVE chain AS (
+			SELECT blocked_by_id FROM task_dependencies WHERE task_id = ?
+			UNION
+			SELECT d.blocked_by_id FROM task_dependencies d JOIN chain ON d.task_id = chain.blocked_by_id
+		) SELECT COUNT(*) FROM chain WHERE blocked_by_id = ?`, taskID, blockerID).Scan(&count).Error
+	if err != nil {
+		logger.Log.WithFields(map[string]interface{}{"taskID": taskID, "blockerID": blockerID}).Error("Failed to walk dependencies")
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GormTaskRepository) Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error) {
+	results, err := r.search(model.VisibleTo(userID), query, limit)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to search tasks: ", err)
+		return nil, err
+	}
+	logger.Log.WithField("userID", userID).Info("Tasks searched by user ID")
+	return results, nil
+}
+
+// SearchAll searches the tasks of every user
+func (r *GormTaskRepository) SearchAll(query string, limit int) (*[]model.TaskSearchResult, error) {
+	results, err := r.search(func(db *gorm.DB) *gorm.DB { return db }, query, limit)
+	if err != nil {
+		logger.Log.Error("Failed to search all tasks: ", err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// search runs a full-text search over the tasks the scope allows
+func (r *GormTaskRepository) search(scope func(*gorm.DB) *gorm.DB, query string, limit int) (*[]model.TaskSearchResult, error) {
+	var results []model.TaskSearchResult
+	var err error
+
+	switch r.db.Dialector.Name() {
+	case "postgres":
+		err = r.searchPostgres(scope, query, limit, &results)
+	default:
+		err = r.searchSQLite(scope, query, limit, &results)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].TitleHighlight = highlightHTML(results[i].TitleHighlight)
+		results[i].DescriptionHighlight = highlightHTML(results[i].DescriptionHighlight)
+	}
+	return &results, nil
+}
+
 func (r *GormTaskRepository) Update(task *model.Task) error {
-	if err := r.db.Save(task).Error; err != nil {
+	// Labels change through ReplaceLabels only
+	if err := r.db.Omit(clause.Associations).Save(task).Error; err != nil {
 		logger.LogTask(*task).Error("Failed to update task")
 		return err
 	}
@@ -65,6 +283,384 @@ func (r *GormTaskRepository) Update(task *model.Task) error {
 	return nil
 }
 
+// FindLabels returns the labels among labelIDs that belong to owner
+func (r *GormTaskRepository) FindLabels(owner model.Owner, labelIDs []uint) (*[]labelModel.Label, error) {
+	var labels []labelModel.Label
+	if err := r.db.Scopes(model.OwnedBy(owner)).Where("id IN ?", labelIDs).Order("id").Find(&labels).Error; err != nil {
+		logger.Log.WithField("userID", owner.UserID).Error("Failed to find labels")
+		return nil, err
+	}
+	return &labels, nil
+}
+
+// FindLabelsByName returns the labels of owner whose lower-cased name is among names
+func (r *GormTaskRepository) FindLabelsByName(owner model.Owner, names []string) (*[]labelModel.Label, error) {
+	var labels []labelModel.Label
+	if err := r.db.Scopes(model.OwnedBy(owner)).Where("LOWER(name) IN ?", names).Order("id").Find(&labels).Error; err != nil {
+		logger.Log.WithField("userID", owner.UserID).Error("Failed to find labels by name")
+		return nil, err
+	}
+	return &labels, nil
+}
+
+// ReplaceLabels makes labels the exact set of labels attached to task
+func (r *GormTaskRepository) ReplaceLabels(task *model.Task, labels []labelModel.Label) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_labels WHERE task_id = ?", task.ID).Error; err != nil {
+			return err
+		}
+		if len(labels) == 0 {
+			return nil
+		}
+		rows := make([]map[string]interface{}, 0, len(labels))
+		for _, l := range labels {
+			rows = append(rows, map[string]interface{}{"task_id": task.ID, "label_id": l.ID})
+		}
+		return tx.Table("task_labels").Create(&rows).Error
+	})
+	if err != nil {
+		logger.LogTask(*task).Error("Failed to replace task labels")
+		return err
+	}
+	task.Labels = labels
+	logger.LogTask(*task).Info("Task labels replaced")
+	return nil
+}
+
+// SaveWithSeries creates a recurring task's series together with its first occurrence
+func (r *GormTaskRepository) SaveWithSeries(series *model.TaskSeries, task *model.Task) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		task.SeriesID = &series.ID
+		return tx.Omit("Labels.*", "Series").Create(task).Error
+	})
+	if err != nil {
+		logger.LogTask(*task).Error("Failed to save recurring task")
+		return err
+	}
+	logger.LogTask(*task).Info("Recurring task saved successfully")
+	return nil
+}
+
+// SaveSeries creates a new series or updates an existing one
+func (r *GormTaskRepository) SaveSeries(series *model.TaskSeries) error {
+	if err := r.db.Save(series).Error; err != nil {
+		logger.Log.WithField("seriesID", series.ID).Error("Failed to save task series")
+		return err
+	}
+	logger.Log.WithField("seriesID", series.ID).Info("Task series saved successfully")
+	return nil
+}
+
+// SaveOccurrence stores the advanced series and its newly generated occurrence at once
+func (r *GormTaskRepository) SaveOccurrence(series *model.TaskSeries, task *model.Task) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(series).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Labels.*", "Series").Create(task).Error
+	})
+	if err != nil {
+		logger.Log.WithField("seriesID", series.ID).Error("Failed to save next occurrence")
+		return err
+	}
+	logger.Log.WithField("seriesID", series.ID).Info("Next occurrence saved successfully")
+	return nil
+}
+
+// ShiftReminders moves the reminders set relative to a task's due date along with
+// it, through the reminder repository so they move in the same transaction
+func (r *GormTaskRepository) ShiftReminders(taskID uint, dueDate time.Time) error {
+	return reminderRepo.NewGormReminderRepository(r.db).ShiftReminders(taskID, dueDate)
+}
+
+// FindUser returns the user with userID, used to check an assignee exists
+func (r *GormTaskRepository) FindUser(userID uint) (*userModel.User, error) {
+	var user userModel.User
+	if err := r.db.Select("id").First(&user, userID).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find user")
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindRole returns userID's role in the workspace, empty when they are not a member
+func (r *GormTaskRepository) FindRole(workspaceID, userID uint) (string, error) {
+	var member workspaceModel.Member
+	result := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Limit(1).Find(&member)
+	if result.Error != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace role")
+		return "", result.Error
+	}
+	return member.Role, nil
+}
+
+// FindProject returns the project with projectID if it belongs to owner
+func (r *GormTaskRepository) FindProject(projectID uint, owner model.Owner) (*projectModel.Project, error) {
+	var project projectModel.Project
+	if err := r.db.Scopes(model.OwnedBy(owner)).Where("id = ?", projectID).First(&project).Error; err != nil {
+		logger.Log.WithFields(map[string]interface{}{"projectID": projectID, "userID": owner.UserID}).Error("Failed to find project")
+		return nil, err
+	}
+	return &project, nil
+}
+
+// FindWorkflow returns the workflow of a project with its statuses in board order
+func (r *GormTaskRepository) FindWorkflow(projectID uint) (*projectModel.Workflow, error) {
+	var workflow projectModel.Workflow
+	err := r.db.Preload("Statuses", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("project_id = ?", projectID).First(&workflow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// unrankedLast orders a board column by rank, tasks without one at its end
+const unrankedLast = "COALESCE(board_rank, '') = '', board_rank, id"
+
+// LastRank returns the highest rank in a board column, empty when it has none
+func (r *GormTaskRepository) LastRank(projectID uint, status string) (string, error) {
+	var ranks []string
+	if err := r.db.Model(&model.Task{}).
+		Where("project_id = ? AND status = ? AND board_rank <> ''", projectID, status).
+		Order("board_rank DESC").Limit(1).Pluck("board_rank", &ranks).Error; err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to find last rank")
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return "", nil
+	}
+	return ranks[0], nil
+}
+
+// RankAfter returns the rank that follows boardRank in a board column, leaving out
+// task excludeID, empty when boardRank is the last
+func (r *GormTaskRepository) RankAfter(column model.ColumnKey, boardRank string, excludeID uint) (string, error) {
+	return r.adjacentRank(column, "board_rank > ?", "board_rank", boardRank, excludeID)
+}
+
+// RankBefore returns the rank that precedes boardRank in a board column, leaving
+// out task excludeID, empty when boardRank is the first
+func (r *GormTaskRepository) RankBefore(column model.ColumnKey, boardRank string, excludeID uint) (string, error) {
+	return r.adjacentRank(column, "board_rank < ?", "board_rank DESC", boardRank, excludeID)
+}
+
+func (r *GormTaskRepository) adjacentRank(column model.ColumnKey, bound, order, boardRank string, excludeID uint) (string, error) {
+	var ranks []string
+	if err := r.db.Model(&model.Task{}).
+		Where("project_id = ? AND status = ? AND board_rank <> '' AND id <> ?", column.ProjectID, column.Status, excludeID).
+		Where(bound, boardRank).
+		Order(order).Limit(1).Pluck("board_rank", &ranks).Error; err != nil {
+		logger.Log.WithField("projectID", column.ProjectID).Error("Failed to find adjacent rank")
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return "", nil
+	}
+	return ranks[0], nil
+}
+
+// FindBoard returns the tasks of a project userID can see in board order. Tasks
+// without a rank come last.
+func (r *GormTaskRepository) FindBoard(projectID, userID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	if err := r.db.Scopes(model.VisibleTo(userID)).Preload("Labels").
+		Where("project_id = ?", projectID).
+		Order(unrankedLast).Find(&tasks).Error; err != nil {
+		logger.Log.WithFields(map[string]interface{}{"projectID": projectID, "userID": userID}).Error("Failed to find board tasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// FindCrowdedColumns returns up to limit board columns holding a rank longer than maxLength
+func (r *GormTaskRepository) FindCrowdedColumns(maxLength, limit int) ([]model.ColumnKey, error) {
+	var columns []model.ColumnKey
+	if err := r.db.Model(&model.Task{}).Select("project_id, status").
+		Where("project_id IS NOT NULL AND LENGTH(board_rank) > ?", maxLength).
+		Group("project_id, status").Order("project_id, status").Limit(limit).
+		Scan(&columns).Error; err != nil {
+		logger.Log.Error("Failed to find crowded board columns: ", err)
+		return nil, err
+	}
+	return columns, nil
+}
+
+// RebalanceColumn rewrites the ranks of a board column evenly spaced and short,
+// keeping the order of its tasks. Trashed tasks keep theirs.
+func (r *GormTaskRepository) RebalanceColumn(column model.ColumnKey) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&model.Task{}).
+			Where("project_id = ? AND status = ?", column.ProjectID, column.Status).
+			Order(unrankedLast).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for i, next := range rank.Spread(len(ids)) {
+			if err := tx.Model(&model.Task{}).Where("id = ?", ids[i]).UpdateColumn("board_rank", next).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Log.WithField("projectID", column.ProjectID).Error("Failed to rebalance board column")
+		return err
+	}
+	return nil
+}
+
+// FindWipLimits returns the WIP limits of a workspace and of a project, either
+// may be nil
+func (r *GormTaskRepository) FindWipLimits(workspaceID, projectID *uint) ([]model.WipLimit, error) {
+	var limits []model.WipLimit
+	if workspaceID == nil && projectID == nil {
+		return limits, nil
+	}
+	query := r.db.Where("1 = 0")
+	if workspaceID != nil {
+		query = query.Or("workspace_id = ?", *workspaceID)
+	}
+	if projectID != nil {
+		query = query.Or("project_id = ?", *projectID)
+	}
+	if err := query.Order("id").Find(&limits).Error; err != nil {
+		logger.Log.Error("Failed to find WIP limits: ", err)
+		return nil, err
+	}
+	return limits, nil
+}
+
+// FindWipLimit returns a WIP limit by its ID
+func (r *GormTaskRepository) FindWipLimit(limitID uint) (*model.WipLimit, error) {
+	var limit model.WipLimit
+	if err := r.db.First(&limit, limitID).Error; err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// SaveWipLimit sets the limit of its workspace or project on its status, replacing
+// the one already there
+func (r *GormTaskRepository) SaveWipLimit(limit *model.WipLimit) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.WipLimit
+		query := tx.Where("status = ?", limit.Status)
+		if limit.ProjectID != nil {
+			query = query.Where("project_id = ?", *limit.ProjectID)
+		} else {
+			query = query.Where("workspace_id = ?", *limit.WorkspaceID)
+		}
+		result := query.Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			limit.ID, limit.CreatedAt = existing.ID, existing.CreatedAt
+		}
+		return tx.Save(limit).Error
+	})
+	if err != nil {
+		logger.Log.WithField("status", limit.Status).Error("Failed to save WIP limit")
+		return err
+	}
+	return nil
+}
+
+// DeleteWipLimit removes a WIP limit
+func (r *GormTaskRepository) DeleteWipLimit(limitID uint) error {
+	if err := r.db.Delete(&model.WipLimit{}, limitID).Error; err != nil {
+		logger.Log.WithField("limitID", limitID).Error("Failed to delete WIP limit")
+		return err
+	}
+	return nil
+}
+
+// LockWipLimit holds the row of a WIP limit until the transaction ends, so the
+// tasks counted against it cannot change in the meantime
+func (r *GormTaskRepository) LockWipLimit(limitID uint) error {
+	var limit model.WipLimit
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&limit, limitID).Error; err != nil {
+		logger.Log.WithField("limitID", limitID).Error("Failed to lock WIP limit")
+		return err
+	}
+	return nil
+}
+
+// CountWip counts the tasks query selects, trashed tasks do not count
+func (r *GormTaskRepository) CountWip(query model.WipQuery) (int64, error) {
+	db := r.db.Model(&model.Task{}).Where("status = ?", query.Status)
+	if query.WorkspaceID != nil {
+		db = db.Where("workspace_id = ?", *query.WorkspaceID)
+	}
+	if query.ProjectID != nil {
+		db = db.Where("project_id = ?", *query.ProjectID)
+	}
+	if query.WorkerID != nil {
+		db = db.Where("COALESCE(assignee_id, user_id) = ?", *query.WorkerID)
+	}
+	if query.ExcludeID != 0 {
+		db = db.Where("id <> ?", query.ExcludeID)
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		logger.Log.WithField("status", query.Status).Error("Failed to count tasks against WIP limit")
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindCompletedEstimates returns the estimated tasks userID can see that entered
+// their current, done status in [from, to), with their labels and status entries
+func (r *GormTaskRepository) FindCompletedEstimates(userID uint, workspaceID, projectID *uint, from, to time.Time) (*[]model.Task, error) {
+	var tasks []model.Task
+	query := r.db.Scopes(model.VisibleTo(userID)).
+		Preload("Labels").
+		Preload("StatusEntries").
+		Where("tasks.status_category = ?", model.CategoryDone).
+		Where("(tasks.estimate_minutes IS NOT NULL OR tasks.estimate_points IS NOT NULL)").
+		Where(`EXISTS (SELECT 1 FROM task_status_entries e
+			WHERE e.task_id = tasks.id AND e.status = tasks.status AND e.entered_at >= ? AND e.entered_at < ?)`, from, to)
+	if workspaceID != nil {
+		query = query.Where("tasks.workspace_id = ?", *workspaceID)
+	}
+	if projectID != nil {
+		query = query.Where("tasks.project_id = ?", *projectID)
+	}
+	if err := query.Order("tasks.id").Find(&tasks).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find completed estimated tasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// FindLoggedTime sums the stopped time entries of each task by anyone, as the
+// time entry repository keeps them
+func (r *GormTaskRepository) FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error) {
+	return timeEntryRepo.NewGormTimeEntryRepository(r.db).FindLoggedTime(taskIDs)
+}
+
+// FindForExport returns up to limit tasks userID can see with an ID above afterID,
+// in ID order with their labels, so an export can page through them by key
+func (r *GormTaskRepository) FindForExport(userID uint, workspaceID *uint, afterID uint, limit int) (*[]model.Task, error) {
+	var tasks []model.Task
+	query := r.db.Scopes(model.VisibleTo(userID)).Preload("Labels").Where("tasks.id > ?", afterID)
+	if workspaceID != nil {
+		query = query.Where("tasks.workspace_id = ?", *workspaceID)
+	}
+	if err := query.Order("tasks.id").Limit(limit).Find(&tasks).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find tasks for export")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// Delete soft deletes a task. Its attachments stay behind until the attachment
+// collector removes them together with any blob left unreferenced.
 func (r *GormTaskRepository) Delete(taskID uint) error {
 	if err := r.db.Delete(&model.Task{}, taskID).Error; err != nil {
 		logger.Log.WithField("taskID", taskID).Error("Failed to delete task")
@@ -74,10 +670,475 @@ func (r *GormTaskRepository) Delete(taskID uint) error {
 	return nil
 }
 
+// FindTrash lists the soft deleted tasks userID can see that can be restored on
+// their own, those whose parent is not in the trash as well. Most recently
+// deleted first.
+func (r *GormTaskRepository) FindTrash(userID uint, workspaceID *uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	query := r.db.Unscoped().Scopes(model.VisibleTo(userID)).Preload("Labels").
+		Where("tasks.deleted_at IS NOT NULL").
+		Where("(tasks.parent_id IS NULL OR tasks.parent_id NOT IN (SELECT id FROM tasks WHERE deleted_at IS NOT NULL))")
+	if workspaceID != nil {
+		query = query.Where("tasks.workspace_id = ?", *workspaceID)
+	}
+	if err := query.Order("tasks.deleted_at DESC, tasks.id").Find(&tasks).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find trashed tasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// FindTrashed returns a soft deleted task userID can see
+func (r *GormTaskRepository) FindTrashed(taskID, userID uint) (*model.Task, error) {
+	var task model.Task
+	if err := r.db.Unscoped().Scopes(model.VisibleTo(userID)).
+		Where("id = ? AND deleted_at IS NOT NULL", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Trashed task not found")
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindTrashedDescendants returns every soft deleted subtask below taskID, at any depth
+func (r *GormTaskRepository) FindTrashedDescendants(taskID uint) (*[]model.Task, error) {
+	var tasks []model.Task
+	tree := r.db.Raw(`WITH RECURSIVE tree AS (
+			SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NOT NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN tree ON t.parent_id = tree.id WHERE t.deleted_at IS NOT NULL
+		) SELECT id FROM tree`, taskID)
+	if err := r.db.Unscoped().Where("id IN (?)", tree).Order("id").Find(&tasks).Error; err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to find trashed subtasks")
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+// Restore takes the tasks out of the trash
+func (r *GormTaskRepository) Restore(taskIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var tasks []model.Task
+		if err := tx.Unscoped().Select("id", "project_id", "status").Where("id IN ?", taskIDs).Order("id").Find(&tasks).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Task{}).Where("id IN ?", taskIDs).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		// The column went on without them, their old ranks may be taken by now
+		return rankLast(tx, tasks)
+	})
+	if err != nil {
+		logger.Log.WithField("taskIDs", taskIDs).Error("Failed to restore tasks")
+		return err
+	}
+	logger.Log.WithField("taskIDs", taskIDs).Info("Tasks restored")
+	return nil
+}
+
+// Purge deletes trashed tasks for good, the rows that hang off them go along
+// through their foreign keys. Tasks that are not in the trash are left alone.
+func (r *GormTaskRepository) Purge(taskIDs []uint) error {
+	if err := r.db.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", taskIDs).Delete(&model.Task{}).Error; err != nil {
+		logger.Log.WithField("taskIDs", taskIDs).Error("Failed to purge tasks")
+		return err
+	}
+	logger.Log.WithField("taskIDs", taskIDs).Info("Tasks purged")
+	return nil
+}
+
+// FindExpiredTrash returns the IDs of up to limit tasks that went to the trash before before
+func (r *GormTaskRepository) FindExpiredTrash(before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Unscoped().Model(&model.Task{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before.UTC()).
+		Order("deleted_at").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		logger.Log.Error("Failed to find expired trash: ", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AddHistory appends entries with their field changes to the task history
+func (r *GormTaskRepository) AddHistory(entries []model.TaskHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range entries {
+			if err := tx.Create(&entries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Log.WithField("taskID", entries[0].TaskID).Error("Failed to record task history")
+		return err
+	}
+	return nil
+}
+
+// FindHistory returns the timeline of a task userID can see, oldest first. Deleted
+// tasks keep theirs.
+func (r *GormTaskRepository) FindHistory(taskID, userID uint) (*[]model.TaskHistory, error) {
+	var task model.Task
+	if err := r.db.Unscoped().Scopes(model.VisibleTo(userID)).Select("id").Where("id = ?", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for history")
+		return nil, err
+	}
+
+	var entries []model.TaskHistory
+	if err := r.db.Preload("Changes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("task_id = ?", taskID).Order("created_at, id").Find(&entries).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task history")
+		return nil, err
+	}
+	return &entries, nil
+}
+
+// enteredAgain overwrites the time of a status a task has been in before
+var enteredAgain = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "task_id"}, {Name: "status"}},
+	DoUpdates: clause.AssignmentColumns([]string{"entered_at"}),
+}
+
+// EnterStatus records that a task entered status at the given time, replacing
+// the time it entered it before
+func (r *GormTaskRepository) EnterStatus(taskID uint, status string, at time.Time) error {
+	entry := model.TaskStatusEntry{TaskID: taskID, Status: status, EnteredAt: at.UTC()}
+	err := r.db.Clauses(enteredAgain).Create(&entry).Error
+	if err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to record status entry")
+		return err
+	}
+	return nil
+}
+
+// UpdateOverdueTasks moves the tasks userID can see that are not done and whose
+// due date has passed to overdue, at the bottom of its board column, recording
+// when they entered it and the status change in their history
 func (r *GormTaskRepository) UpdateOverdueTasks(userID uint) error {
 	now := time.Now().UTC()
-	return r.db.Model(&model.Task{}).
-         Where("user_id = ? AND due_date <= ? AND status NOT IN ?", userID, now, []string{"completed","overdue"}).
-		Update("status", "overdue").Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var due []model.Task
+		if err := tx.Model(&model.Task{}).
+			Scopes(model.VisibleTo(userID)).
+			Select("id", "project_id", "status").
+			Where("due_date <= ? AND status_category <> ? AND status <> ?", now, model.CategoryDone, model.StatusOverdue).
+			Order("id").
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(due))
+		moved := make([]model.Task, len(due))
+		for i, task := range due {
+			ids[i] = task.ID
+			moved[i] = task
+			moved[i].Status = model.StatusOverdue
+		}
+		if err := tx.Model(&model.Task{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":          model.StatusOverdue,
+			"status_category": model.CategoryTodo,
+		}).Error; err != nil {
+			return err
+		}
+		entries := make([]model.TaskStatusEntry, len(ids))
+		for i, id := range ids {
+			entries[i] = model.TaskStatusEntry{TaskID: id, Status: model.StatusOverdue, EnteredAt: now}
+		}
+		if err := tx.Clauses(enteredAgain).Create(&entries).Error; err != nil {
+			return err
+		}
+		if err := rankLast(tx, moved); err != nil {
+			return err
+		}
+		overdue := model.StatusOverdue
+		for _, task := range due {
+			old := task.Status
+			entry := model.TaskHistory{
+				TaskID:    task.ID,
+				ActorID:   model.SystemActorID,
+				Action:    model.ActionUpdated,
+				Changes:   []model.TaskFieldChange{{Field: "status", OldValue: &old, NewValue: &overdue}},
+				CreatedAt: now,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// rankLast ranks tasks at the bottom of their board columns in the order given,
+// tasks outside any project have no board
+func rankLast(tx *gorm.DB, tasks []model.Task) error {
+	last := make(map[model.ColumnKey]string)
+	for _, task := range tasks {
+		if task.ProjectID == nil {
+			continue
+		}
+		column := model.ColumnKey{ProjectID: *task.ProjectID, Status: task.Status}
+		above, ok := last[column]
+		if !ok {
+			var err error
+			if above, err = (&GormTaskRepository{db: tx}).LastRank(column.ProjectID, column.Status); err != nil {
+				return err
+			}
+		}
+		next, err := rank.Between(above, "")
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Task{}).Where("id = ?", task.ID).UpdateColumn("board_rank", next).Error; err != nil {
+			return err
+		}
+		last[column] = next
+	}
+	return nil
+}
 
-}
\ No newline at end of file
+// Sortable columns for task listings, guarded so user input never reaches ORDER BY directly
+var taskSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"due_date":   "due_date",
+	"title":      "title",
+	"status":     "status",
+}
+
+func applyTaskFilter(query *gorm.DB, filter model.TaskFilter) *gorm.DB {
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("due_date >= ?", filter.DueFrom.UTC())
+	}
+	if filter.DueTo != nil {
+		query = query.Where("due_date <= ?", filter.DueTo.UTC())
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", filter.CreatedFrom.UTC())
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", filter.CreatedTo.UTC())
+	}
+	if filter.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", filter.UpdatedFrom.UTC())
+	}
+	if filter.UpdatedTo != nil {
+		query = query.Where("updated_at <= ?", filter.UpdatedTo.UTC())
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if len(filter.LabelIDs) > 0 {
+		labelled := query.Session(&gorm.Session{NewDB: true}).Table("task_labels").
+			Select("task_id").Where("label_id IN ?", filter.LabelIDs)
+		if filter.LabelMatch == "all" {
+			labelled = labelled.Group("task_id").Having("COUNT(DISTINCT label_id) = ?", len(uniqueIDs(filter.LabelIDs)))
+		}
+		query = query.Where("id IN (?)", labelled)
+	}
+	return query
+}
+
+func applyTaskOrder(query *gorm.DB, sortBy, order string, reverse bool) *gorm.DB {
+	if sortBy == "smart" {
+		return applySmartOrder(query, order, reverse)
+	}
+	column, ok := taskSortColumns[sortBy]
+	if !ok {
+		column = "created_at"
+	}
+	direction, nulls := "DESC", "ASC"
+	if (order == "asc") != reverse {
+		direction = "ASC"
+	}
+	if reverse {
+		nulls = "DESC"
+	}
+
+	// Tasks without a due date always go last, on Postgres and SQLite alike
+	if column == "due_date" {
+		query = query.Order("due_date IS NULL " + nulls)
+	}
+	return query.Order(column + " " + direction).Order("id " + direction)
+}
+
+// smartRankSQL mirrors model.SmartRank so the database can order by it
+var smartRankSQL = func() string {
+	expr := "(CASE WHEN status = 'overdue' THEN " + strconv.Itoa(model.OverdueRank) + " ELSE 0 END + CASE priority"
+	for _, p := range []string{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityUrgent} {
+		expr += " WHEN '" + p + "' THEN " + strconv.Itoa(model.PriorityRanks[p])
+	}
+	return expr + " ELSE 0 END)"
+}()
+
+// applySmartOrder puts the most urgent tasks first: overdue before the rest, then
+// by priority, then the soonest due date with undated tasks last. order=asc lists
+// the exact reverse.
+func applySmartOrder(query *gorm.DB, order string, reverse bool) *gorm.DB {
+	if (order == "asc") != reverse {
+		return query.Order(smartRankSQL + " ASC").Order("due_date IS NULL DESC").
+			Order("due_date DESC").Order("id DESC")
+	}
+	return query.Order(smartRankSQL + " DESC").Order("due_date IS NULL ASC").
+		Order("due_date ASC").Order("id ASC")
+}
+
+// applyTaskCursor keeps only the rows after (or before, when Backward) the cursor
+// position, mirroring the ordering of applyTaskOrder
+func applyTaskCursor(query *gorm.DB, cursor model.TaskCursor) *gorm.DB {
+	if cursor.SortBy == "smart" {
+		return applySmartCursor(query, cursor)
+	}
+	column, ok := taskSortColumns[cursor.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	op := "<"
+	if (cursor.Order == "asc") != cursor.Backward {
+		op = ">"
+	}
+
+	var value interface{} = cursor.Text
+	if cursor.Time != nil {
+		value = *cursor.Time
+	}
+	after := "(" + column + " " + op + " ? OR (" + column + " = ? AND id " + op + " ?))"
+
+	if column != "due_date" {
+		return query.Where(after, value, value, cursor.ID)
+	}
+	return query.Where(dueDateAfter(cursor, op))
+}
+
+// dueDateAfter is the keyset condition on (due_date, id) with undated tasks last
+func dueDateAfter(cursor model.TaskCursor, op string) clause.Expr {
+	if cursor.Time == nil {
+		if !cursor.Backward {
+			return gorm.Expr("due_date IS NULL AND id "+op+" ?", cursor.ID)
+		}
+		return gorm.Expr("(due_date IS NOT NULL OR id "+op+" ?)", cursor.ID)
+	}
+	value := *cursor.Time
+	after := "(due_date " + op + " ? OR (due_date = ? AND id " + op + " ?))"
+	if !cursor.Backward {
+		return gorm.Expr("((due_date IS NOT NULL AND "+after+") OR due_date IS NULL)", value, value, cursor.ID)
+	}
+	return gorm.Expr("due_date IS NOT NULL AND "+after, value, value, cursor.ID)
+}
+
+// applySmartCursor is applyTaskCursor for the smart ordering of applySmartOrder
+func applySmartCursor(query *gorm.DB, cursor model.TaskCursor) *gorm.DB {
+	rank := 0
+	if cursor.Rank != nil {
+		rank = *cursor.Rank
+	}
+	// Within one rank due dates run ascending, flipped when listing in reverse
+	rankOp, dueOp := "<", ">"
+	if cursor.Order == "asc" {
+		rankOp, dueOp = ">", "<"
+	}
+	within := cursor
+	within.Backward = cursor.Order == "asc"
+	if cursor.Backward {
+		rankOp, dueOp = flipOp(rankOp), flipOp(dueOp)
+		within.Backward = !within.Backward
+	}
+	return query.Where("("+smartRankSQL+" "+rankOp+" ? OR ("+smartRankSQL+" = ? AND ?))",
+		rank, rank, dueDateAfter(within, dueOp))
+}
+
+func flipOp(op string) string {
+	if op == "<" {
+		return ">"
+	}
+	return "<"
+}
+
+// relatedTo limits a tasks query to the rows that can be linked with taskID: the
+// tasks of its workspace, or for a task in no workspace the other such tasks of
+// its creator userID
+func relatedTo(taskID, userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`(tasks.workspace_id = (SELECT workspace_id FROM tasks WHERE id = ?)
+			OR (tasks.workspace_id IS NULL AND tasks.user_id = ?))`, taskID, userID)
+	}
+}
+
+// Highlight markers the database wraps around matched terms. They are control
+// characters so that task text can be HTML-escaped before they become <mark> tags.
+const (
+	markStart = "\x02"
+	markEnd   = "\x03"
+)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>")
+
+func highlightHTML(s string) string {
+	return markReplacer.Replace(html.EscapeString(s))
+}
+
+func (r *GormTaskRepository) searchPostgres(scope func(*gorm.DB) *gorm.DB, query string, limit int, results *[]model.TaskSearchResult) error {
+	titleOpts := "HighlightAll=true, StartSel=" + markStart + ", StopSel=" + markEnd
+	descOpts := "MaxFragments=2, MaxWords=20, MinWords=5, StartSel=" + markStart + ", StopSel=" + markEnd
+	return r.db.Table("tasks, websearch_to_tsquery('english', ?) AS q", query).
+		Select(`tasks.id, tasks.title, tasks.status, tasks.due_date,
+			ts_headline('english', tasks.title, q, ?) AS title_highlight,
+			ts_headline('english', coalesce(tasks.description, ''), q, ?) AS description_highlight,
+			ts_rank(tasks.search_vector, q) AS rank`, titleOpts, descOpts).
+		Scopes(scope).
+		Where("tasks.deleted_at IS NULL AND tasks.search_vector @@ q").
+		Order("rank DESC, tasks.id DESC").
+		Limit(limit).
+		Scan(results).Error
+}
+
+func (r *GormTaskRepository) searchSQLite(scope func(*gorm.DB) *gorm.DB, query string, limit int, results *[]model.TaskSearchResult) error {
+	match := ftsMatchExpr(query)
+	if match == "" {
+		return nil
+	}
+	// bm25 scores lower for better matches, negate it to rank like Postgres
+	return r.db.Table("tasks_fts").
+		Select(`tasks.id, tasks.title, tasks.status, tasks.due_date,
+			highlight(tasks_fts, 0, ?, ?) AS title_highlight,
+			snippet(tasks_fts, 1, ?, ?, '...', 20) AS description_highlight,
+			-bm25(tasks_fts, 10.0, 1.0) AS rank`, markStart, markEnd, markStart, markEnd).
+		Joins("JOIN tasks ON tasks.id = tasks_fts.rowid").
+		Scopes(scope).
+		Where("tasks_fts MATCH ? AND tasks.deleted_at IS NULL", match).
+		Order("rank DESC, tasks.id DESC").
+		Limit(limit).
+		Scan(results).Error
+}
+
+// ftsMatchExpr turns free text into an FTS5 query where every word must match,
+// quoting each word so user input cannot inject FTS5 syntax
+func ftsMatchExpr(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = `"` + w + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	res := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
@@ -1,13 +1,23 @@
 package repository_test
 
 import (
+	"errors"
+	"fmt"
 	"log"
-	// "mymodule/config"
+	"mymodule/config"
+	labelModel "mymodule/internal/label/model"
+	projectModel "mymodule/internal/project/model"
+	reminderModel "mymodule/internal/reminder/model"
+	timeEntryModel "mymodule/internal/timeentry/model"
 	"mymodule/internal/task/model"
 	"mymodule/internal/task/repository"
+	"mymodule/internal/task/usecase"
+	workspaceModel "mymodule/internal/workspace/model"
 	"mymodule/pkg/logger"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -35,22 +45,25 @@ func WithRollback(db *gorm.DB, t *testing.T, testFunc func(tx *gorm.DB)) {
 	testFunc(tx)
 }
 
-// PostgreSQL
-// func setupTestDB() *gorm.DB {
-// 	db := config.InitDB(".env.test")
-// 	err := db.AutoMigrate(&model.Task{})
-// 	if err != nil {
-// 		log.Fatalf("failed to migrate: %v", err)
-// 	}
-// 	return db
-// }
+// PostgreSQL, only runs when a .env.test is present
+func setupPostgresTestDB(t *testing.T) *gorm.DB {
+	if _, err := os.Stat(".env.test"); err != nil {
+		t.Skip("no .env.test found, skipping PostgreSQL tests")
+	}
+	db := config.InitDB(".env.test")
+	err := db.AutoMigrate(&model.Task{}, &model.TaskDependency{}, &workspaceModel.Member{})
+	if err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
 
 func setupTestDB() *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
-	err = db.AutoMigrate(&model.Task{})
+	err = db.AutoMigrate(&model.Task{}, &model.TaskDependency{}, &projectModel.Project{}, &model.TaskSeries{}, &reminderModel.Reminder{}, &workspaceModel.Member{}, &model.TaskHistory{}, &model.TaskFieldChange{}, &model.TaskStatusEntry{}, &projectModel.Workflow{}, &projectModel.WorkflowStatus{}, &model.WipLimit{}, &timeEntryModel.TimeEntry{})
 	if err != nil {
 		log.Fatalf("failed to migrate: %v", err)
 	}
@@ -68,7 +81,7 @@ func TestSaveTask(t *testing.T) {
 			Description: "Test description",
 			UserID:      1,
 		}
-		err := repo.Save(task)
+		err := repo.Save(&task)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
 		}
@@ -89,7 +102,7 @@ func TestSaveTask_DBError(t *testing.T) {
 	repo := repository.NewGormTaskRepository(db)
 	task := model.Task{Title: "Should Fail", UserID: 1}
 
-	err := repo.Save(task)
+	err := repo.Save(&task)
 	if err == nil {
 		t.Errorf("expected error due to closed DB, got nil")
 	}
@@ -192,3 +205,1435 @@ func TestDeleteTask_NotExist(t *testing.T) {
 		t.Errorf("expected no error or ErrRecordNotFound, got: %v", err)
 	}
 }
+
+func TestFindByUser_SQLite(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testFindByUser(t, tx)
+	})
+}
+
+func TestFindByUser_Postgres(t *testing.T) {
+	db := setupPostgresTestDB(t)
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testFindByUser(t, tx)
+	})
+}
+
+func testFindByUser(t *testing.T, tx *gorm.DB) {
+	repo := repository.NewGormTaskRepository(tx)
+	userID := uint(501)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) *time.Time {
+		d := base.AddDate(0, 0, n)
+		return &d
+	}
+
+	tasks := []model.Task{
+		{Title: "A", Status: "pending", DueDate: day(3), CreatedAt: *day(0), UserID: userID},
+		{Title: "B", Status: "completed", DueDate: day(1), CreatedAt: *day(1), UserID: userID},
+		{Title: "C", Status: "in_progress", DueDate: nil, CreatedAt: *day(2), UserID: userID},
+		{Title: "D", Status: "pending", DueDate: day(2), CreatedAt: *day(3), UserID: userID},
+		{Title: "Other user", Status: "pending", CreatedAt: *day(0), UserID: userID + 1},
+	}
+	for i := range tasks {
+		tx.Create(&tasks[i])
+	}
+
+	titles := func(list *[]model.Task) []string {
+		res := []string{}
+		for _, task := range *list {
+			res = append(res, task.Title)
+		}
+		return res
+	}
+	assertTitles := func(t *testing.T, got *[]model.Task, want ...string) {
+		t.Helper()
+		g := titles(got)
+		if len(g) != len(want) {
+			t.Fatalf("expected %v, got: %v", want, g)
+		}
+		for i := range want {
+			if g[i] != want[i] {
+				t.Fatalf("expected %v, got: %v", want, g)
+			}
+		}
+	}
+
+	t.Run("DefaultOrderNewestFirst", func(t *testing.T) {
+		found, total, err := repo.FindByUser(userID, model.TaskFilter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 4 {
+			t.Errorf("expected total 4, got: %v", total)
+		}
+		assertTitles(t, found, "D", "C", "B", "A")
+	})
+
+	t.Run("StatusFilter", func(t *testing.T) {
+		found, total, err := repo.FindByUser(userID, model.TaskFilter{Status: []string{"pending"}, SortBy: "title", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 2 {
+			t.Errorf("expected total 2, got: %v", total)
+		}
+		assertTitles(t, found, "A", "D")
+	})
+
+	t.Run("DueRange", func(t *testing.T) {
+		found, _, err := repo.FindByUser(userID, model.TaskFilter{DueFrom: day(2), DueTo: day(3), SortBy: "due_date", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertTitles(t, found, "D", "A")
+	})
+
+	t.Run("CreatedRange", func(t *testing.T) {
+		found, _, err := repo.FindByUser(userID, model.TaskFilter{CreatedFrom: day(1), CreatedTo: day(2), SortBy: "created_at", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertTitles(t, found, "B", "C")
+	})
+
+	t.Run("DueDateSortNullsLast", func(t *testing.T) {
+		found, _, err := repo.FindByUser(userID, model.TaskFilter{SortBy: "due_date", Order: "desc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertTitles(t, found, "A", "D", "B", "C")
+	})
+
+	t.Run("LimitOffset", func(t *testing.T) {
+		found, total, err := repo.FindByUser(userID, model.TaskFilter{SortBy: "title", Order: "asc", Limit: 2, Offset: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 4 {
+			t.Errorf("expected total 4 regardless of paging, got: %v", total)
+		}
+		assertTitles(t, found, "B", "C")
+	})
+}
+
+func TestFindByUser_Keyset_SQLite(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testFindByUserKeyset(t, tx)
+	})
+}
+
+func TestFindByUser_Keyset_Postgres(t *testing.T) {
+	db := setupPostgresTestDB(t)
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testFindByUserKeyset(t, tx)
+	})
+}
+
+// testFindByUserKeyset walks every sort order page by page in both directions
+// and expects the same rows as one unpaged listing, with ties and NULL due dates
+func testFindByUserKeyset(t *testing.T, tx *gorm.DB) {
+	repo := repository.NewGormTaskRepository(tx)
+	userID := uint(601)
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 9; i++ {
+		created := base.AddDate(0, 0, i/2)
+		task := model.Task{
+			Title:     []string{"alpha", "beta", "alpha", "gamma"}[i%4],
+			Status:    []string{"pending", "completed", "pending", "overdue"}[i%4],
+			Priority:  []string{"none", "high", "urgent", "high", "none"}[i%5],
+			CreatedAt: created,
+			UpdatedAt: created,
+			UserID:    userID,
+		}
+		if i%3 != 0 {
+			due := base.AddDate(0, 0, i%2)
+			task.DueDate = &due
+		}
+		tx.Create(&task)
+	}
+
+	ids := func(tasks []model.Task) []uint {
+		res := []uint{}
+		for _, task := range tasks {
+			res = append(res, task.ID)
+		}
+		return res
+	}
+
+	for _, sortBy := range []string{"smart", "created_at", "updated_at", "due_date", "title", "status"} {
+		for _, order := range []string{"asc", "desc"} {
+			t.Run(sortBy+"_"+order, func(t *testing.T) {
+				all, _, err := repo.FindByUser(userID, model.TaskFilter{SortBy: sortBy, Order: order})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				want := ids(*all)
+
+				var forward []model.Task
+				filter := model.TaskFilter{SortBy: sortBy, Order: order, Limit: 3}
+				for {
+					page, _, err := repo.FindByUser(userID, filter)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if len(*page) == 0 {
+						break
+					}
+					forward = append(forward, *page...)
+					filter.Cursor = model.ToTaskCursor((*page)[len(*page)-1], sortBy, order, false)
+				}
+				if got := ids(forward); !equalIDs(got, want) {
+					t.Fatalf("forward paging: expected %v, got: %v", want, got)
+				}
+
+				var backward []model.Task
+				filter.Cursor = model.ToTaskCursor(forward[len(forward)-1], sortBy, order, true)
+				backward = append(backward, forward[len(forward)-1])
+				for {
+					page, _, err := repo.FindByUser(userID, filter)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if len(*page) == 0 {
+						break
+					}
+					backward = append(append([]model.Task{}, *page...), backward...)
+					filter.Cursor = model.ToTaskCursor((*page)[0], sortBy, order, true)
+				}
+				if got := ids(backward); !equalIDs(got, want) {
+					t.Fatalf("backward paging: expected %v, got: %v", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestFindByUser_SmartOrder(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(651)
+		soon := time.Now().Add(24 * time.Hour)
+		later := time.Now().Add(72 * time.Hour)
+		past := time.Now().Add(-24 * time.Hour)
+
+		for _, task := range []model.Task{
+			{Title: "low undated", Priority: "low"},
+			{Title: "high later", Priority: "high", DueDate: &later},
+			{Title: "none overdue", Priority: "none", Status: "overdue", DueDate: &past},
+			{Title: "high soon", Priority: "high", DueDate: &soon},
+			{Title: "urgent undated", Priority: "urgent"},
+			{Title: "high undated", Priority: "high"},
+		} {
+			task.UserID = userID
+			if task.Status == "" {
+				task.Status = "pending"
+			}
+			if err := tx.Create(&task).Error; err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+		}
+
+		titles := func(order string) []string {
+			found, _, err := repo.FindByUser(userID, model.TaskFilter{SortBy: "smart", Order: order})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res := []string{}
+			for _, task := range *found {
+				res = append(res, task.Title)
+			}
+			return res
+		}
+
+		want := []string{"none overdue", "urgent undated", "high soon", "high later", "high undated", "low undated"}
+		if got := titles("desc"); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected %v, got: %v", want, got)
+		}
+		for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+			want[i], want[j] = want[j], want[i]
+		}
+		if got := titles("asc"); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected reverse %v, got: %v", want, got)
+		}
+	})
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearch_SQLite(t *testing.T) {
+	db := setupTestDB()
+	if err := repository.MigrateSQLiteSearch(db); err != nil {
+		if strings.Contains(err.Error(), "fts5") {
+			t.Skip("go-sqlite3 built without FTS5, run with -tags sqlite_fts5")
+		}
+		t.Fatalf("failed to migrate search index: %v", err)
+	}
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testSearch(t, tx)
+	})
+}
+
+func TestSearch_Postgres(t *testing.T) {
+	db := setupPostgresTestDB(t)
+	migration, err := os.ReadFile("../../../migrations/000003_add_task_search.up.sql")
+	if err != nil {
+		t.Fatalf("failed to read migration: %v", err)
+	}
+	if err := db.Exec(string(migration)).Error; err != nil {
+		t.Fatalf("failed to migrate search index: %v", err)
+	}
+	WithRollback(db, t, func(tx *gorm.DB) {
+		testSearch(t, tx)
+	})
+}
+
+func testSearch(t *testing.T, tx *gorm.DB) {
+	repo := repository.NewGormTaskRepository(tx)
+	userID := uint(701)
+
+	tasks := []model.Task{
+		{Title: "Plan garden layout", Description: "Decide where the tomatoes go", UserID: userID},
+		{Title: "Buy seeds", Description: "Tomatoes, basil and a <b>garden</b> hose", UserID: userID},
+		{Title: "Garden party", Description: "Someone else's garden", UserID: userID + 1},
+		{Title: "Old garden notes", Description: "Deleted already", UserID: userID},
+	}
+	for i := range tasks {
+		tx.Create(&tasks[i])
+	}
+	tx.Delete(&tasks[3])
+
+	t.Run("RankedAndScopedToUser", func(t *testing.T) {
+		found, err := repo.Search(userID, "garden", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*found) != 2 {
+			t.Fatalf("expected 2 results, got: %v", *found)
+		}
+		// A title match outranks a description match
+		if (*found)[0].ID != tasks[0].ID || (*found)[1].ID != tasks[1].ID {
+			t.Errorf("expected title match first, got: %v", *found)
+		}
+		if (*found)[0].Rank < (*found)[1].Rank {
+			t.Errorf("expected descending rank, got: %v", *found)
+		}
+	})
+
+	t.Run("Highlights", func(t *testing.T) {
+		found, err := repo.Search(userID, "garden", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := (*found)[0].TitleHighlight; got != "Plan <mark>garden</mark> layout" {
+			t.Errorf("unexpected title highlight: %q", got)
+		}
+		if got := (*found)[1].DescriptionHighlight; !strings.Contains(got, "&lt;b&gt;<mark>garden</mark>&lt;/b&gt;") {
+			t.Errorf("expected escaped description highlight, got: %q", got)
+		}
+	})
+
+	t.Run("AllWordsMustMatch", func(t *testing.T) {
+		found, err := repo.Search(userID, "tomatoes basil", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*found) != 1 || (*found)[0].ID != tasks[1].ID {
+			t.Errorf("expected only 'Buy seeds', got: %v", *found)
+		}
+	})
+
+	t.Run("SyntaxIsNotInterpreted", func(t *testing.T) {
+		_, err := repo.Search(userID, `garden" OR "*`, 10)
+		if err != nil {
+			t.Errorf("expected query to be sanitised, got: %v", err)
+		}
+	})
+}
+
+func TestFindDescendants(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(801)
+
+		root := model.Task{Title: "Root", UserID: userID}
+		tx.Create(&root)
+		child := model.Task{Title: "Child", UserID: userID, ParentID: &root.ID}
+		tx.Create(&child)
+		grandchild := model.Task{Title: "Grandchild", UserID: userID, ParentID: &child.ID}
+		tx.Create(&grandchild)
+		deleted := model.Task{Title: "Deleted child", UserID: userID, ParentID: &root.ID}
+		tx.Create(&deleted)
+		tx.Delete(&deleted)
+
+		children, err := repo.FindChildren(root.ID, userID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*children) != 1 || (*children)[0].ID != child.ID {
+			t.Errorf("expected only the direct child, got: %v", *children)
+		}
+
+		descendants, err := repo.FindDescendants(root.ID, userID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*descendants) != 2 || (*descendants)[0].ID != child.ID || (*descendants)[1].ID != grandchild.ID {
+			t.Errorf("expected child and grandchild, got: %v", *descendants)
+		}
+
+		other, err := repo.FindDescendants(root.ID, userID+1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*other) != 0 {
+			t.Errorf("expected no descendants for another user, got: %v", *other)
+		}
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(901)
+
+		tasks := make([]model.Task, 4)
+		for i := range tasks {
+			tasks[i] = model.Task{Title: "Chain", UserID: userID}
+			tx.Create(&tasks[i])
+		}
+		a, b, c, d := tasks[0].ID, tasks[1].ID, tasks[2].ID, tasks[3].ID
+
+		// a waits on b, b waits on c
+		for _, edge := range [][2]uint{{a, b}, {b, c}, {a, b}} {
+			if err := repo.AddDependency(edge[0], edge[1]); err != nil {
+				t.Fatalf("unexpected error adding %v: %v", edge, err)
+			}
+		}
+
+		blockers, err := repo.FindBlockers(a, userID)
+		if err != nil || len(*blockers) != 1 || (*blockers)[0].ID != b {
+			t.Errorf("expected a to be blocked by b only, got: %v, %v", blockers, err)
+		}
+		blocked, err := repo.FindBlocked(c, userID)
+		if err != nil || len(*blocked) != 1 || (*blocked)[0].ID != b {
+			t.Errorf("expected c to block b only, got: %v, %v", blocked, err)
+		}
+
+		for _, tc := range []struct {
+			task, blocker uint
+			want          bool
+		}{
+			{a, c, true},
+			{a, b, true},
+			{c, a, false},
+			{d, a, false},
+		} {
+			got, err := repo.DependsOn(tc.task, tc.blocker)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("DependsOn(%d, %d): expected %v, got %v", tc.task, tc.blocker, tc.want, got)
+			}
+		}
+
+		if err := repo.RemoveDependency(a, b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := repo.RemoveDependency(a, b); err != gorm.ErrRecordNotFound {
+			t.Errorf("expected ErrRecordNotFound removing twice, got: %v", err)
+		}
+	})
+}
+
+func TestLabels(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(1001)
+
+		work := labelModel.Label{Name: "work", Color: "#ff0000", UserID: userID}
+		home := labelModel.Label{Name: "home", Color: "#00ff00", UserID: userID}
+		foreign := labelModel.Label{Name: "work", Color: "#0000ff", UserID: userID + 1}
+		for _, l := range []*labelModel.Label{&work, &home, &foreign} {
+			tx.Create(l)
+		}
+
+		both := model.Task{Title: "Both", UserID: userID, Labels: []labelModel.Label{work, home}}
+		onlyWork := model.Task{Title: "Work", UserID: userID, Labels: []labelModel.Label{{ID: work.ID}}}
+		none := model.Task{Title: "None", UserID: userID}
+		for _, task := range []model.Task{both, onlyWork, none} {
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		var check labelModel.Label
+		tx.First(&check, work.ID)
+		if check.Name != "work" || check.Color != "#ff0000" {
+			t.Errorf("expected saving a task to leave label rows alone, got: %v", check)
+		}
+
+		labels, err := repo.FindLabels(model.Owner{UserID: userID}, []uint{work.ID, foreign.ID})
+		if err != nil || len(*labels) != 1 || (*labels)[0].ID != work.ID {
+			t.Errorf("expected only the user's own label, got: %v, %v", labels, err)
+		}
+		team := uint(42)
+		if err := tx.Create(&workspaceModel.Member{WorkspaceID: team, UserID: userID + 1, Role: workspaceModel.RoleMember}).Error; err != nil {
+			t.Fatalf("failed to seed member: %v", err)
+		}
+		labels, err = repo.FindLabels(model.Owner{WorkspaceID: &team, UserID: userID}, []uint{work.ID, foreign.ID})
+		if err != nil || len(*labels) != 1 || (*labels)[0].ID != foreign.ID {
+			t.Errorf("expected only the labels of the workspace's members, got: %v, %v", labels, err)
+		}
+
+		titles := func(filter model.TaskFilter) []string {
+			filter.SortBy, filter.Order = "title", "asc"
+			found, _, err := repo.FindByUser(userID, filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res := []string{}
+			for _, task := range *found {
+				res = append(res, task.Title)
+			}
+			return res
+		}
+
+		if got := titles(model.TaskFilter{LabelIDs: []uint{work.ID, home.ID}, LabelMatch: "any"}); strings.Join(got, ",") != "Both,Work" {
+			t.Errorf("any-of: expected [Both Work], got: %v", got)
+		}
+		if got := titles(model.TaskFilter{LabelIDs: []uint{work.ID, home.ID, home.ID}, LabelMatch: "all"}); strings.Join(got, ",") != "Both" {
+			t.Errorf("all-of: expected [Both], got: %v", got)
+		}
+
+		var task model.Task
+		tx.Where("title = ? AND user_id = ?", "Work", userID).First(&task)
+		if err := repo.ReplaceLabels(&task, []labelModel.Label{home}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		found, err := repo.FindByIDAndUser(task.ID, userID)
+		if err != nil || len(found.Labels) != 1 || found.Labels[0].ID != home.ID {
+			t.Errorf("expected labels to be replaced by home, got: %v, %v", found, err)
+		}
+	})
+}
+
+func TestFindByUser_LabelsWithoutNPlusOne(t *testing.T) {
+	db := setupTestDB()
+	var queries int
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) { queries++ })
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		label := labelModel.Label{Name: "n+1", UserID: 1101}
+		tx.Create(&label)
+
+		countFor := func(userID uint, n int) int {
+			for i := 0; i < n; i++ {
+				repo.Save(&model.Task{Title: "Labelled", UserID: userID, Labels: []labelModel.Label{label}})
+			}
+			queries = 0
+			found, _, err := repo.FindByUser(userID, model.TaskFilter{})
+			if err != nil || len(*found) != n || len((*found)[0].Labels) != 1 {
+				t.Fatalf("expected %d labelled tasks, got: %v, %v", n, found, err)
+			}
+			return queries
+		}
+
+		one, many := countFor(1101, 1), countFor(1102, 5)
+		if one != many {
+			t.Errorf("expected a constant number of queries, got %d for 1 task and %d for 5", one, many)
+		}
+	})
+}
+
+func TestProjects(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(1201)
+
+		website := projectModel.Project{Name: "Website", UserID: userID}
+		foreign := projectModel.Project{Name: "Website", UserID: userID + 1}
+		tx.Create(&website)
+		tx.Create(&foreign)
+
+		if _, err := repo.FindProject(foreign.ID, model.Owner{UserID: userID}); err == nil {
+			t.Errorf("expected another user's project to be hidden")
+		}
+		if found, err := repo.FindProject(website.ID, model.Owner{UserID: userID}); err != nil || found.Name != "Website" {
+			t.Errorf("expected the user's project, got: %v, %v", found, err)
+		}
+
+		// In a workspace the projects of all its members can be used
+		team := uint(41)
+		if err := tx.Create(&workspaceModel.Member{WorkspaceID: team, UserID: userID + 1, Role: workspaceModel.RoleMember}).Error; err != nil {
+			t.Fatalf("failed to seed member: %v", err)
+		}
+		inTeam := model.Owner{WorkspaceID: &team, UserID: userID}
+		if _, err := repo.FindProject(foreign.ID, inTeam); err != nil {
+			t.Errorf("expected a member's project to be usable in the workspace, got: %v", err)
+		}
+		if _, err := repo.FindProject(website.ID, inTeam); err == nil {
+			t.Errorf("expected the project of a non-member to be hidden in the workspace")
+		}
+
+		for _, task := range []model.Task{
+			{Title: "Design", UserID: userID, ProjectID: &website.ID},
+			{Title: "Groceries", UserID: userID},
+		} {
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		found, total, err := repo.FindByUser(userID, model.TaskFilter{ProjectID: &website.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 1 || len(*found) != 1 || (*found)[0].Title != "Design" {
+			t.Errorf("expected only the project's task, got: %v", *found)
+		}
+	})
+}
+
+func TestSeries(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(1301)
+		due := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+
+		label := labelModel.Label{Name: "chores", Color: "#00ff00", UserID: userID}
+		tx.Create(&label)
+
+		first := model.Task{Title: "Bins", UserID: userID, DueDate: &due, Occurrence: 1, Labels: []labelModel.Label{label}}
+		series := model.ToTaskSeries(first, "FREQ=WEEKLY")
+		if err := repo.SaveWithSeries(series, &first); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if series.ID == 0 {
+			t.Fatalf("expected the series to be created")
+		}
+
+		var saved model.Task
+		tx.Where("series_id = ?", series.ID).First(&saved)
+		found, err := repo.FindByIDAndUser(saved.ID, userID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if found.Series == nil || found.Series.RRule != "FREQ=WEEKLY" || len(found.Labels) != 1 {
+			t.Fatalf("expected the task with its series and labels, got: %+v", found)
+		}
+
+		next := due.AddDate(0, 0, 7)
+		series.Generated, series.LastDueAt = 2, next
+		occurrence := model.ToNextOccurrence(*series, *found, next)
+		if err := repo.SaveOccurrence(series, &occurrence); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var occurrences []model.Task
+		tx.Preload("Labels").Where("series_id = ?", series.ID).Order("occurrence").Find(&occurrences)
+		if len(occurrences) != 2 || occurrences[1].Occurrence != 2 || !occurrences[1].DueDate.Equal(next) || len(occurrences[1].Labels) != 1 {
+			t.Fatalf("expected the second occurrence with its labels, got: %+v", occurrences)
+		}
+		var stored model.TaskSeries
+		tx.First(&stored, series.ID)
+		if stored.Generated != 2 {
+			t.Errorf("expected the series to be advanced, got: %+v", stored)
+		}
+	})
+}
+
+func TestShiftReminders(t *testing.T) {
+	db := setupTestDB()
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		due := time.Now().Add(-2 * time.Hour).UTC()
+		task := model.Task{Title: "Dentist", UserID: 1401, DueDate: &due}
+		tx.Create(&task)
+
+		offset := 60
+		sentAt := due.Add(-time.Hour)
+		fixed := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+		relative := reminderModel.Reminder{TaskID: task.ID, UserID: 1401, RemindAt: sentAt, OffsetMinutes: &offset, SentAt: &sentAt}
+		absolute := reminderModel.Reminder{TaskID: task.ID, UserID: 1401, RemindAt: fixed}
+		tx.Create(&relative)
+		tx.Create(&absolute)
+
+		newDue := time.Now().Add(48 * time.Hour)
+		if err := repo.ShiftReminders(task.ID, newDue); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var shifted, kept reminderModel.Reminder
+		tx.First(&shifted, relative.ID)
+		if !shifted.RemindAt.Equal(newDue.Add(-time.Hour)) || shifted.SentAt != nil {
+			t.Errorf("expected the relative reminder to follow the due date and re-arm, got: %+v", shifted)
+		}
+		tx.First(&kept, absolute.ID)
+		if !kept.RemindAt.Equal(fixed) {
+			t.Errorf("expected the absolute reminder to stay put, got: %+v", kept)
+		}
+	})
+}
+
+func TestAssignees(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		creator, assignee := uint(61), uint(62)
+
+		mine := model.Task{Title: "Mine", UserID: assignee, Status: "pending", Priority: model.PriorityNone}
+		handed := model.Task{Title: "Handed", UserID: creator, AssigneeID: &assignee, Status: "pending", Priority: model.PriorityNone}
+		private := model.Task{Title: "Private", UserID: creator, Status: "pending", Priority: model.PriorityNone}
+		for _, task := range []*model.Task{&mine, &handed, &private} {
+			if err := tx.Create(task).Error; err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+		}
+
+		titles := func(mineFilter string) []string {
+			tasks, _, err := repo.FindByUser(assignee, model.TaskFilter{SortBy: "created_at", Order: "asc", Mine: mineFilter})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res := []string{}
+			for _, task := range *tasks {
+				res = append(res, task.Title)
+			}
+			return res
+		}
+		if got := titles(""); strings.Join(got, ",") != "Mine,Handed" {
+			t.Errorf("expected own and assigned tasks, got: %v", got)
+		}
+		if got := titles(model.MineAssigned); strings.Join(got, ",") != "Handed" {
+			t.Errorf("expected only the assigned task, got: %v", got)
+		}
+		if got := titles(model.MineCreated); strings.Join(got, ",") != "Mine" {
+			t.Errorf("expected only the created task, got: %v", got)
+		}
+
+		if _, err := repo.FindByIDAndUser(handed.ID, assignee); err != nil {
+			t.Errorf("expected the assignee to see the task, got: %v", err)
+		}
+		if _, err := repo.FindByIDAndUser(private.ID, assignee); err != gorm.ErrRecordNotFound {
+			t.Errorf("expected another user's unassigned task to be hidden, got: %v", err)
+		}
+	})
+}
+
+func TestWorkspaces(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		alice, bob := uint(71), uint(72)
+		team, otherTeam := uint(31), uint(32)
+
+		for _, m := range []workspaceModel.Member{
+			{WorkspaceID: team, UserID: alice, Role: workspaceModel.RoleOwner},
+			{WorkspaceID: team, UserID: bob, Role: workspaceModel.RoleViewer},
+			{WorkspaceID: otherTeam, UserID: alice, Role: workspaceModel.RoleOwner},
+		} {
+			if err := tx.Create(&m).Error; err != nil {
+				t.Fatalf("failed to seed member: %v", err)
+			}
+		}
+
+		shared := model.Task{Title: "Shared", UserID: alice, WorkspaceID: &team, Status: "pending", Priority: model.PriorityNone}
+		hidden := model.Task{Title: "Hidden", UserID: alice, WorkspaceID: &otherTeam, Status: "pending", Priority: model.PriorityNone}
+		for _, task := range []*model.Task{&shared, &hidden} {
+			if err := tx.Create(task).Error; err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+		}
+		// Created by a member in the same workspace, under another member's task
+		child := model.Task{Title: "Child", UserID: bob, WorkspaceID: &team, ParentID: &shared.ID, Status: "pending", Priority: model.PriorityNone}
+		if err := tx.Create(&child).Error; err != nil {
+			t.Fatalf("failed to seed task: %v", err)
+		}
+
+		if _, err := repo.FindByIDAndUser(shared.ID, bob); err != nil {
+			t.Errorf("expected a member to see the workspace's task, got: %v", err)
+		}
+		if _, err := repo.FindByIDAndUser(hidden.ID, bob); err != gorm.ErrRecordNotFound {
+			t.Errorf("expected a task of another workspace to be hidden, got: %v", err)
+		}
+
+		tasks, total, err := repo.FindByUser(alice, model.TaskFilter{WorkspaceID: &team, SortBy: "created_at", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 2 || (*tasks)[0].Title != "Shared" || (*tasks)[1].Title != "Child" {
+			t.Errorf("expected only the active workspace's tasks, got: %d %v", total, *tasks)
+		}
+
+		children, err := repo.FindDescendants(shared.ID, alice)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*children) != 1 || (*children)[0].ID != child.ID {
+			t.Errorf("expected the subtask of another member, got: %v", *children)
+		}
+
+		role, err := repo.FindRole(team, bob)
+		if err != nil || role != workspaceModel.RoleViewer {
+			t.Errorf("expected viewer, got: %q %v", role, err)
+		}
+		if role, err := repo.FindRole(otherTeam, bob); err != nil || role != "" {
+			t.Errorf("expected no role outside the workspace, got: %q %v", role, err)
+		}
+	})
+}
+
+func TestFindAll(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		alice, bob := uint(81), uint(82)
+
+		for _, task := range []model.Task{
+			{Title: "Alice's", UserID: alice, Status: "pending", Priority: model.PriorityNone},
+			{Title: "Bob's", UserID: bob, Status: "pending", Priority: model.PriorityNone},
+		} {
+			if err := tx.Create(&task).Error; err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+		}
+
+		// No workspace membership is needed to see them
+		tasks, total, err := repo.FindAll(model.TaskFilter{SortBy: "created_at", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total < 2 {
+			t.Errorf("expected every user's tasks, got: %d", total)
+		}
+
+		tasks, total, err = repo.FindAll(model.TaskFilter{UserID: &bob, SortBy: "created_at", Order: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 1 || (*tasks)[0].Title != "Bob's" {
+			t.Errorf("expected only Bob's task, got: %d %v", total, *tasks)
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner, stranger := uint(1401), uint(1402)
+
+		task := model.Task{Title: "Audited", UserID: owner, Status: "pending", Priority: model.PriorityNone}
+		if err := repo.Save(&task); err != nil {
+			t.Fatalf("failed to seed task: %v", err)
+		}
+
+		done := "completed"
+		pending := "pending"
+		err := repo.AddHistory([]model.TaskHistory{
+			{TaskID: task.ID, ActorID: owner, Action: model.ActionCreated},
+			{TaskID: task.ID, ActorID: owner, Action: model.ActionUpdated, Changes: []model.TaskFieldChange{
+				{Field: "status", OldValue: &pending, NewValue: &done},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.Delete(task.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The timeline outlives the soft deleted task
+		entries, err := repo.FindHistory(task.ID, owner)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*entries) != 2 || (*entries)[1].Action != model.ActionUpdated || len((*entries)[1].Changes) != 1 {
+			t.Fatalf("expected both entries with the status change, got: %+v", *entries)
+		}
+		change := (*entries)[1].Changes[0]
+		if change.Field != "status" || *change.OldValue != "pending" || *change.NewValue != "completed" {
+			t.Errorf("expected pending -> completed, got: %+v", change)
+		}
+
+		if _, err := repo.FindHistory(task.ID, stranger); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("expected other users not to see the history, got: %v", err)
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner, stranger := uint(1501), uint(1502)
+
+		parent := model.Task{Title: "Parent", UserID: owner, Status: "pending", Priority: model.PriorityNone}
+		if err := repo.Save(&parent); err != nil {
+			t.Fatalf("failed to seed task: %v", err)
+		}
+		child := model.Task{Title: "Child", UserID: owner, ParentID: &parent.ID, Status: "pending", Priority: model.PriorityNone}
+		loose := model.Task{Title: "Loose", UserID: owner, Status: "pending", Priority: model.PriorityNone}
+		for _, task := range []*model.Task{&child, &loose} {
+			if err := repo.Save(task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+		}
+		for _, id := range []uint{child.ID, parent.ID, loose.ID} {
+			if err := repo.Delete(id); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		// The child comes back with its parent, only the roots are listed
+		trash, err := repo.FindTrash(owner, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*trash) != 2 {
+			t.Fatalf("expected the parent and the loose task, got: %v", *trash)
+		}
+		if empty, _ := repo.FindTrash(stranger, nil); len(*empty) != 0 {
+			t.Errorf("expected other users' trash to stay hidden, got: %v", *empty)
+		}
+
+		descendants, err := repo.FindTrashedDescendants(parent.ID)
+		if err != nil || len(*descendants) != 1 || (*descendants)[0].ID != child.ID {
+			t.Fatalf("expected the trashed child, got: %v, %v", descendants, err)
+		}
+
+		if err := repo.Restore([]uint{parent.ID, child.ID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.FindByIDAndUser(child.ID, owner); err != nil {
+			t.Errorf("expected the child to be back, got: %v", err)
+		}
+		if _, err := repo.FindTrashed(parent.ID, owner); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("expected the parent to be out of the trash, got: %v", err)
+		}
+
+		// Only trashed tasks are purged
+		expired, err := repo.FindExpiredTrash(time.Now().Add(time.Minute), 10)
+		if err != nil || len(expired) != 1 || expired[0] != loose.ID {
+			t.Fatalf("expected only the loose task to be expired, got: %v, %v", expired, err)
+		}
+		if err := repo.Purge([]uint{parent.ID, loose.ID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var count int64
+		tx.Unscoped().Model(&model.Task{}).Where("id IN ?", []uint{parent.ID, loose.ID}).Count(&count)
+		if count != 1 {
+			t.Errorf("expected the live parent to survive the purge, got %d rows", count)
+		}
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		userID := uint(1601)
+
+		err := repo.Transaction(func(outer usecase.TaskRepository) error {
+			if err := outer.Save(&model.Task{Title: "Kept", UserID: userID}); err != nil {
+				return err
+			}
+			// A failing nested call only rolls back to its savepoint
+			nested := outer.Transaction(func(inner usecase.TaskRepository) error {
+				if err := inner.Save(&model.Task{Title: "Dropped", UserID: userID}); err != nil {
+					return err
+				}
+				return errors.New("item failed")
+			})
+			if nested == nil {
+				t.Errorf("expected the nested error to come back")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var titles []string
+		tx.Model(&model.Task{}).Where("user_id = ?", userID).Pluck("title", &titles)
+		if len(titles) != 1 || titles[0] != "Kept" {
+			t.Errorf("expected only the outer task to be saved, got: %v", titles)
+		}
+	})
+}
+
+func TestStatusEntries(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(1601)
+		past := time.Now().Add(-time.Hour)
+
+		task := model.Task{Title: "Late", UserID: owner, Status: model.StatusPending, Priority: model.PriorityNone, DueDate: &past}
+		if err := repo.Save(&task); err != nil {
+			t.Fatalf("failed to seed task: %v", err)
+		}
+		first := time.Now().Add(-2 * time.Hour)
+		if err := repo.EnterStatus(task.ID, model.StatusPending, first); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Entering a status again moves its time instead of adding a row
+		if err := repo.EnterStatus(task.ID, model.StatusPending, first.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := repo.UpdateOverdueTasks(owner); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		found, err := repo.FindByIDAndUser(task.ID, owner)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if found.Status != model.StatusOverdue {
+			t.Errorf("expected the task to be overdue, got %s", found.Status)
+		}
+		entered := map[string]time.Time{}
+		for _, e := range found.StatusEntries {
+			entered[e.Status] = e.EnteredAt
+		}
+		if len(entered) != 2 {
+			t.Fatalf("expected pending and overdue entries, got: %+v", found.StatusEntries)
+		}
+		if !entered[model.StatusPending].After(first) {
+			t.Errorf("expected pending to have been entered again, got %v", entered[model.StatusPending])
+		}
+		if _, ok := entered[model.StatusOverdue]; !ok {
+			t.Errorf("expected the overdue transition to be recorded")
+		}
+
+		history, err := repo.FindHistory(task.ID, owner)
+		if err != nil || len(*history) != 1 {
+			t.Fatalf("expected one history entry for the overdue move, got %v, %v", history, err)
+		}
+		entry := (*history)[0]
+		if entry.ActorID != model.SystemActorID || entry.Action != model.ActionUpdated || len(entry.Changes) != 1 ||
+			*entry.Changes[0].OldValue != model.StatusPending || *entry.Changes[0].NewValue != model.StatusOverdue {
+			t.Errorf("expected a system status change from pending to overdue, got %+v", entry)
+		}
+	})
+}
+
+func TestOverdueByCategory(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(1701)
+		past := time.Now().Add(-time.Hour)
+
+		project := projectModel.Project{Name: "Board", UserID: owner}
+		tx.Create(&project)
+		workflow := projectModel.Workflow{ProjectID: project.ID, Statuses: []projectModel.WorkflowStatus{
+			{Key: "review", Name: "Review", Category: model.CategoryInProgress, Position: 1},
+			{Key: "shipped", Name: "Shipped", Category: model.CategoryDone, Position: 2},
+		}}
+		tx.Create(&workflow)
+
+		reviewing := model.Task{Title: "Reviewing", UserID: owner, ProjectID: &project.ID, Status: "review", StatusCategory: model.CategoryInProgress, Priority: model.PriorityNone, DueDate: &past}
+		shipped := model.Task{Title: "Shipped", UserID: owner, ProjectID: &project.ID, Status: "shipped", StatusCategory: model.CategoryDone, Priority: model.PriorityNone, DueDate: &past}
+		for _, task := range []*model.Task{&reviewing, &shipped} {
+			if err := repo.Save(task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+		}
+
+		if err := repo.UpdateOverdueTasks(owner); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		found, _ := repo.FindByIDAndUser(reviewing.ID, owner)
+		if found.Status != model.StatusOverdue || found.StatusCategory != model.CategoryTodo {
+			t.Errorf("expected the open task to be overdue, got %s (%s)", found.Status, found.StatusCategory)
+		}
+		found, _ = repo.FindByIDAndUser(shipped.ID, owner)
+		if found.Status != "shipped" {
+			t.Errorf("expected the done task to stay shipped, got %s", found.Status)
+		}
+
+		flow, err := repo.FindWorkflow(project.ID)
+		if err != nil || len(flow.Statuses) != 2 || flow.Statuses[0].Key != "review" {
+			t.Fatalf("expected the project's workflow in order, got %+v, %v", flow, err)
+		}
+	})
+}
+
+func TestBoardRanks(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(1801)
+
+		project := projectModel.Project{Name: "Board", UserID: owner}
+		tx.Create(&project)
+		seed := func(title, status, boardRank string) model.Task {
+			task := model.Task{Title: title, UserID: owner, ProjectID: &project.ID, Status: status, StatusCategory: model.CategoryTodo, Priority: model.PriorityNone, BoardRank: boardRank}
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+			return task
+		}
+		second := seed("Second", model.StatusPending, "i0000000000001")
+		first := seed("First", model.StatusPending, "i")
+		unranked := seed("Unranked", model.StatusPending, "")
+		other := seed("Other column", model.StatusInProgress, "z")
+
+		last, err := repo.LastRank(project.ID, model.StatusPending)
+		if err != nil || last != "i0000000000001" {
+			t.Errorf("expected the highest rank of the column, got %q, %v", last, err)
+		}
+		pending := model.ColumnKey{ProjectID: project.ID, Status: model.StatusPending}
+		if next, err := repo.RankAfter(pending, "i", 0); err != nil || next != "i0000000000001" {
+			t.Errorf("expected the rank after the first task, got %q, %v", next, err)
+		}
+		if next, _ := repo.RankAfter(pending, "i", second.ID); next != "" {
+			t.Errorf("expected the excluded task to be skipped, got %q", next)
+		}
+		if previous, err := repo.RankBefore(pending, "i0000000000001", 0); err != nil || previous != "i" {
+			t.Errorf("expected the rank before the second task, got %q, %v", previous, err)
+		}
+		if previous, _ := repo.RankBefore(pending, "i", 0); previous != "" {
+			t.Errorf("expected nothing before the first task, got %q", previous)
+		}
+
+		tasks, err := repo.FindBoard(project.ID, owner)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var order []uint
+		for _, task := range *tasks {
+			order = append(order, task.ID)
+		}
+		want := []uint{first.ID, second.ID, other.ID, unranked.ID}
+		if fmt.Sprint(order) != fmt.Sprint(want) {
+			t.Errorf("expected board order %v, got %v", want, order)
+		}
+
+		crowded, err := repo.FindCrowdedColumns(12, 10)
+		if err != nil || len(crowded) != 1 || crowded[0] != (model.ColumnKey{ProjectID: project.ID, Status: model.StatusPending}) {
+			t.Fatalf("expected the pending column to be crowded, got %+v, %v", crowded, err)
+		}
+
+		if err := repo.RebalanceColumn(crowded[0]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ranks := map[uint]string{}
+		for _, id := range []uint{first.ID, second.ID, unranked.ID, other.ID} {
+			found, _ := repo.FindByIDAndUser(id, owner)
+			ranks[id] = found.BoardRank
+		}
+		if !(ranks[first.ID] < ranks[second.ID] && ranks[second.ID] < ranks[unranked.ID]) || len(ranks[second.ID]) > 2 {
+			t.Errorf("expected short ranks in the same order, got %v", ranks)
+		}
+		if ranks[other.ID] != "z" {
+			t.Errorf("expected other columns to keep their ranks, got %q", ranks[other.ID])
+		}
+		if crowded, _ := repo.FindCrowdedColumns(12, 10); len(crowded) != 0 {
+			t.Errorf("expected no crowded column after rebalancing, got %+v", crowded)
+		}
+	})
+}
+
+func TestReRankedOnReturn(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(1851)
+		past := time.Now().Add(-time.Hour)
+
+		project := projectModel.Project{Name: "Board", UserID: owner}
+		tx.Create(&project)
+		seed := func(title, status, boardRank string, due *time.Time) model.Task {
+			task := model.Task{Title: title, UserID: owner, ProjectID: &project.ID, Status: status, StatusCategory: model.CategoryTodo, Priority: model.PriorityNone, BoardRank: boardRank, DueDate: due}
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+			return task
+		}
+		rankOf := func(id uint) string {
+			var task model.Task
+			tx.Unscoped().Select("board_rank").First(&task, id)
+			return task.BoardRank
+		}
+		seed("Overdue", model.StatusOverdue, "m", nil)
+		late := seed("Late", model.StatusPending, "m", &past)
+		also := seed("Also late", model.StatusPending, "p", &past)
+
+		if err := repo.UpdateOverdueTasks(owner); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !("m" < rankOf(late.ID) && rankOf(late.ID) < rankOf(also.ID)) {
+			t.Errorf("expected the overdue tasks at the bottom of the column in turn, got %q and %q", rankOf(late.ID), rankOf(also.ID))
+		}
+
+		trashed := seed("Trashed", model.StatusPending, "x", nil)
+		if err := repo.Delete(trashed.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seed("Took its place", model.StatusPending, "x", nil)
+		if err := repo.Restore([]uint{trashed.ID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rankOf(trashed.ID) <= "x" {
+			t.Errorf("expected the restored task at the bottom of its column, got %q", rankOf(trashed.ID))
+		}
+	})
+}
+
+func TestWipLimits(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(1901)
+		assignee := uint(1902)
+		workspaceID := uint(19)
+
+		project := projectModel.Project{Name: "Limited", UserID: owner}
+		tx.Create(&project)
+
+		limit := model.WipLimit{WorkspaceID: &workspaceID, Status: model.StatusInProgress, Scope: model.WipPerUser, Max: 2}
+		if err := repo.SaveWipLimit(&limit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		again := model.WipLimit{WorkspaceID: &workspaceID, Status: model.StatusInProgress, Scope: model.WipPerProject, Max: 4}
+		if err := repo.SaveWipLimit(&again); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if again.ID != limit.ID {
+			t.Errorf("expected the limit on the same status to be replaced, got IDs %d and %d", limit.ID, again.ID)
+		}
+		projectLimit := model.WipLimit{ProjectID: &project.ID, Status: model.StatusInProgress, Scope: model.WipPerProject, Max: 1}
+		if err := repo.SaveWipLimit(&projectLimit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		limits, err := repo.FindWipLimits(&workspaceID, nil)
+		if err != nil || len(limits) != 1 || limits[0].Max != 4 || limits[0].Scope != model.WipPerProject {
+			t.Errorf("expected the replaced workspace limit, got %+v, %v", limits, err)
+		}
+		limits, _ = repo.FindWipLimits(&workspaceID, &project.ID)
+		if len(limits) != 2 {
+			t.Errorf("expected the workspace and project limits, got %+v", limits)
+		}
+
+		seed := func(title string, assigneeID *uint) model.Task {
+			task := model.Task{Title: title, UserID: owner, AssigneeID: assigneeID, WorkspaceID: &workspaceID, ProjectID: &project.ID, Status: model.StatusInProgress, StatusCategory: model.CategoryInProgress, Priority: model.PriorityNone}
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+			return task
+		}
+		mine := seed("Mine", nil)
+		seed("Assigned", &assignee)
+		trashed := seed("Trashed", nil)
+		if err := repo.Delete(trashed.ID); err != nil {
+			t.Fatalf("failed to trash task: %v", err)
+		}
+
+		if err := repo.Transaction(func(repo usecase.TaskRepository) error {
+			return repo.LockWipLimit(limit.ID)
+		}); err != nil {
+			t.Errorf("expected the limit to be locked, got %v", err)
+		}
+		if err := repo.LockWipLimit(999999); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("expected a missing limit not to be locked, got %v", err)
+		}
+
+		count := func(query model.WipQuery) int64 {
+			n, err := repo.CountWip(query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return n
+		}
+		if n := count(model.WipQuery{Status: model.StatusInProgress, ProjectID: &project.ID}); n != 2 {
+			t.Errorf("expected 2 tasks in the project, got %d", n)
+		}
+		if n := count(model.WipQuery{Status: model.StatusInProgress, WorkspaceID: &workspaceID, WorkerID: &owner}); n != 1 {
+			t.Errorf("expected the assigned task to count for its assignee only, got %d for the owner", n)
+		}
+		if n := count(model.WipQuery{Status: model.StatusInProgress, WorkspaceID: &workspaceID, WorkerID: &owner, ExcludeID: mine.ID}); n != 0 {
+			t.Errorf("expected the moved task not to count against itself, got %d", n)
+		}
+
+		if err := repo.DeleteWipLimit(projectLimit.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.FindWipLimit(projectLimit.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("expected the limit to be gone, got %v", err)
+		}
+	})
+}
+
+func TestEstimateQueries(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(2001)
+		march := func(day, hour int) time.Time {
+			return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+		}
+		minutes := 60
+
+		seed := func(title, status, category string, completed time.Time, estimated bool) model.Task {
+			task := model.Task{Title: title, UserID: owner, Status: status, StatusCategory: category, Priority: model.PriorityNone}
+			if estimated {
+				task.EstimateMinutes = &minutes
+			}
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+			if err := repo.EnterStatus(task.ID, status, completed); err != nil {
+				t.Fatalf("failed to enter status: %v", err)
+			}
+			return task
+		}
+		inRange := seed("Done", model.StatusCompleted, model.CategoryDone, march(5, 12), true)
+		seed("Unestimated", model.StatusCompleted, model.CategoryDone, march(5, 12), false)
+		seed("Open", model.StatusInProgress, model.CategoryInProgress, march(5, 12), true)
+		seed("Earlier", model.StatusCompleted, model.CategoryDone, march(1, 12), true)
+
+		tasks, err := repo.FindCompletedEstimates(owner, nil, nil, march(3, 0), march(10, 0))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*tasks) != 1 || (*tasks)[0].ID != inRange.ID {
+			t.Fatalf("expected only the estimated task completed in range, got %+v", *tasks)
+		}
+		if len((*tasks)[0].StatusEntries) != 1 || *(*tasks)[0].EstimateMinutes != 60 {
+			t.Errorf("expected the estimate and status entries loaded, got %+v", (*tasks)[0])
+		}
+		if tasks, _ := repo.FindCompletedEstimates(owner+1, nil, nil, march(3, 0), march(10, 0)); len(*tasks) != 0 {
+			t.Errorf("expected other users not to see the task, got %+v", *tasks)
+		}
+
+		project := projectModel.Project{Name: "Private", UserID: owner}
+		tx.Create(&project)
+		tx.Model(&inRange).Update("project_id", project.ID)
+		if tasks, _ := repo.FindCompletedEstimates(owner+1, nil, &project.ID, march(3, 0), march(10, 0)); len(*tasks) != 0 {
+			t.Errorf("expected the project of another user to show nothing, got %+v", *tasks)
+		}
+		if tasks, _ := repo.FindCompletedEstimates(owner, nil, &project.ID, march(3, 0), march(10, 0)); len(*tasks) != 1 {
+			t.Errorf("expected the owner to see the project's task, got %+v", *tasks)
+		}
+
+		stopped := march(5, 10)
+		entries := []timeEntryModel.TimeEntry{
+			{TaskID: inRange.ID, UserID: owner, StartedAt: march(5, 9), EndedAt: &stopped},
+			{TaskID: inRange.ID, UserID: owner + 1, StartedAt: march(5, 9), EndedAt: &stopped},
+			{TaskID: inRange.ID, UserID: owner, StartedAt: march(5, 11)},
+		}
+		for i := range entries {
+			if err := tx.Create(&entries[i]).Error; err != nil {
+				t.Fatalf("failed to seed time entry: %v", err)
+			}
+		}
+		logged, err := repo.FindLoggedTime([]uint{inRange.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if logged[inRange.ID] != 2*time.Hour {
+			t.Errorf("expected the stopped entries of everyone to add up to 2h, got %v", logged[inRange.ID])
+		}
+	})
+}
+
+func TestCSVQueries(t *testing.T) {
+	db := setupTestDB()
+
+	WithRollback(db, t, func(tx *gorm.DB) {
+		repo := repository.NewGormTaskRepository(tx)
+		owner := uint(2101)
+		workspaceID := uint(31)
+		if err := tx.Create(&workspaceModel.Member{WorkspaceID: workspaceID, UserID: owner, Role: workspaceModel.RoleMember}).Error; err != nil {
+			t.Fatalf("failed to seed member: %v", err)
+		}
+
+		work := labelModel.Label{Name: "Work", Color: "#ff0000", UserID: owner}
+		foreign := labelModel.Label{Name: "home", Color: "#00ff00", UserID: owner + 1}
+		for _, l := range []*labelModel.Label{&work, &foreign} {
+			tx.Create(l)
+		}
+		labels, err := repo.FindLabelsByName(model.Owner{UserID: owner}, []string{"work", "home"})
+		if err != nil || len(*labels) != 1 || (*labels)[0].ID != work.ID {
+			t.Errorf("expected only the user's own label matched without regard to case, got: %v, %v", labels, err)
+		}
+
+		var ids []uint
+		for i := 0; i < 5; i++ {
+			task := model.Task{Title: fmt.Sprintf("Task %d", i), UserID: owner, Priority: model.PriorityNone, Labels: []labelModel.Label{{ID: work.ID}}}
+			if i == 4 {
+				task.WorkspaceID = &workspaceID
+			}
+			if err := repo.Save(&task); err != nil {
+				t.Fatalf("failed to seed task: %v", err)
+			}
+			ids = append(ids, task.ID)
+		}
+
+		var seen []uint
+		var afterID uint
+		for {
+			page, err := repo.FindForExport(owner, nil, afterID, 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, task := range *page {
+				if len(task.Labels) != 1 {
+					t.Errorf("expected the labels of task %d loaded, got %v", task.ID, task.Labels)
+				}
+				seen = append(seen, task.ID)
+			}
+			if len(*page) < 2 {
+				break
+			}
+			afterID = (*page)[len(*page)-1].ID
+		}
+		if fmt.Sprint(seen) != fmt.Sprint(ids) {
+			t.Errorf("expected every task once in ID order %v, got %v", ids, seen)
+		}
+
+		inWorkspace, err := repo.FindForExport(owner, &workspaceID, 0, 10)
+		if err != nil || len(*inWorkspace) != 1 || (*inWorkspace)[0].ID != ids[4] {
+			t.Errorf("expected only the workspace task, got: %v, %v", inWorkspace, err)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "gorm.io/gorm"
+
+// MigrateSQLiteSearch creates the FTS5 index over task title and description
+// and the triggers that keep it in step with the tasks table. Postgres gets its
+// tsvector column from migrations/ instead. go-sqlite3 only ships FTS5 when
+// built with the sqlite_fts5 tag.
+func MigrateSQLiteSearch(db *gorm.DB) error {
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(
+			title, description, content='tasks', content_rowid='id', tokenize='porter unicode61'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+			INSERT INTO tasks_fts(rowid, title, description) VALUES (new.id, new.title, new.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+			INSERT INTO tasks_fts(tasks_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE ON tasks BEGIN
+			INSERT INTO tasks_fts(tasks_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+			INSERT INTO tasks_fts(rowid, title, description) VALUES (new.id, new.title, new.description);
+		END`,
+		`INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild')`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/rank"
+
+	"gorm.io/gorm"
+)
+
+// GetBoard returns the board of a project: one column per status of its workflow
+// and one for overdue tasks, each holding the tasks userID can see in rank order
+// and the load against the WIP limits of the project and the active workspace
+func (uc *TaskusecaseImpl) GetBoard(projectID, userID uint, workspaceID *uint) (*model.Board, error) {
+	tasks, err := uc.repo.FindBoard(projectID, userID)
+	if err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to get board")
+		return nil, err
+	}
+	// Without a task to see the project may still be one the user files tasks under
+	if len(*tasks) == 0 {
+		if _, err := uc.repo.FindProject(projectID, model.Owner{WorkspaceID: workspaceID, UserID: userID}); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Log.WithField("projectID", projectID).Warn("Board not found for this user")
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+	}
+
+	wf, err := uc.workflowOf(&projectID)
+	if err != nil {
+		return nil, err
+	}
+	board := &model.Board{ProjectID: projectID}
+	column := make(map[string]int, len(wf.statuses)+1)
+	for _, s := range wf.statuses {
+		column[s.Key] = len(board.Columns)
+		board.Columns = append(board.Columns, model.BoardColumn{Status: s.Key, Name: s.Name, Category: s.Category, Tasks: []model.Task{}})
+	}
+	column[model.StatusOverdue] = len(board.Columns)
+	board.Columns = append(board.Columns, model.BoardColumn{Status: model.StatusOverdue, Name: "Overdue", Category: model.CategoryTodo, Tasks: []model.Task{}})
+
+	for _, task := range *tasks {
+		i, ok := column[task.Status]
+		if !ok {
+			i = column[wf.Place(task.Status, task.StatusCategory)]
+		}
+		board.Columns[i].Tasks = append(board.Columns[i].Tasks, task)
+	}
+	if err := uc.boardLoads(board, userID, workspaceID); err != nil {
+		logger.Log.WithField("projectID", projectID).Error("Failed to count board WIP loads")
+		return nil, err
+	}
+	return board, nil
+}
+
+// MoveOnBoard places a task between two neighbours of its board column, moving it
+// to another column first when req names a status, as far as its WIP limits allow.
+// Only the moved task gets a new rank. Whoever may change the status of a task may
+// move it.
+func (uc *TaskusecaseImpl) MoveOnBoard(taskID, userID uint, req model.BoardMoveRequest) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.moveOnBoard(taskID, userID, req)
+	})
+}
+
+func (uc *TaskusecaseImpl) moveOnBoard(taskID, userID uint, req model.BoardMoveRequest) error {
+	task, err := uc.repo.FindByIDAndUser(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("taskID", taskID).Warn("Board move failed: task not found")
+			return ErrTaskNotFound
+		}
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task to move on the board")
+		return err
+	}
+	if err := uc.authorize(task, userID, true); err != nil {
+		return err
+	}
+	if task.ProjectID == nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Board move failed: task is not in a project")
+		return ErrNotOnBoard
+	}
+
+	wf, err := uc.workflowOf(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	from := currentStatus(task.Status)
+	current := wf.Place(from, task.StatusCategory)
+	to := current
+	if req.Status != nil {
+		to = *req.Status
+		transition := Transition{Task: task, From: current, To: to, ActorID: userID}
+		if err := uc.machine.Check(uc, wf, transition); err != nil {
+			return err
+		}
+	}
+
+	var above, below string
+	if req.AfterID != nil {
+		if above, err = uc.neighbourRank(task, *req.AfterID, to, userID); err != nil {
+			return err
+		}
+	}
+	if req.BeforeID != nil {
+		if below, err = uc.neighbourRank(task, *req.BeforeID, to, userID); err != nil {
+			return err
+		}
+	}
+	// A single neighbour is bounded on its other side by the task next to it
+	column := model.ColumnKey{ProjectID: *task.ProjectID, Status: to}
+	switch {
+	case req.AfterID == nil && req.BeforeID == nil:
+		if above, err = uc.repo.LastRank(*task.ProjectID, to); err != nil {
+			return err
+		}
+	case req.BeforeID == nil:
+		if below, err = uc.repo.RankAfter(column, above, task.ID); err != nil {
+			return err
+		}
+	case req.AfterID == nil:
+		if above, err = uc.repo.RankBefore(column, below, task.ID); err != nil {
+			return err
+		}
+	}
+	next, err := rank.Between(above, below)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Board move failed: neighbours out of order")
+		return ErrInvalidNeighbour
+	}
+
+	before := model.Snapshot(*task)
+	task.Status = to
+	task.BoardRank = next
+	uc.SetStatusBasedOnDueDate(task, wf)
+	// The due date sent it to another column than the one it was ranked in
+	if task.Status != to {
+		if err := uc.placeLast(task); err != nil {
+			return err
+		}
+	}
+	if task.Status != from {
+		if err := uc.checkWip(task, userID, req.OverrideWip); err != nil {
+			return err
+		}
+	}
+	if err := uc.repo.Update(task); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to move task on the board")
+		return err
+	}
+	if err := uc.keepRanksShort(task); err != nil {
+		return err
+	}
+	if err := uc.record(task.ID, userID, model.ActionUpdated, before.Diff(model.Snapshot(*task))); err != nil {
+		return err
+	}
+	if task.Status != from {
+		transition := Transition{Task: task, From: from, To: task.Status, ActorID: userID}
+		if err := uc.machine.Enter(uc, wf, transition, task.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Task moved on the board")
+	return nil
+}
+
+// neighbourRank is the rank of the task a moved task lands next to, which has to
+// be another task in the column it lands in
+func (uc *TaskusecaseImpl) neighbourRank(task *model.Task, neighbourID uint, status string, userID uint) (string, error) {
+	if neighbourID == task.ID {
+		return "", ErrInvalidNeighbour
+	}
+	neighbour, err := uc.repo.FindByIDAndUser(neighbourID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("taskID", neighbourID).Warn("Board move failed: neighbour not found")
+			return "", ErrInvalidNeighbour
+		}
+		return "", err
+	}
+	if !sameProject(neighbour.ProjectID, task.ProjectID) || neighbour.Status != status {
+		logger.Log.WithField("taskID", neighbourID).Warn("Board move failed: neighbour is in another column")
+		return "", ErrInvalidNeighbour
+	}
+	return neighbour.BoardRank, nil
+}
+
+// placeLast ranks task at the bottom of its board column, tasks outside any
+// project have no board and no rank
+func (uc *TaskusecaseImpl) placeLast(task *model.Task) error {
+	if task.ProjectID == nil {
+		task.BoardRank = ""
+		return nil
+	}
+	last, err := uc.repo.LastRank(*task.ProjectID, task.Status)
+	if err != nil {
+		return err
+	}
+	if task.BoardRank, err = rank.Between(last, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// keepRanksShort rebalances the column of task right away once its rank grew past
+// MaxRankLength, so no rank outgrows its column before the rebalancer comes round
+func (uc *TaskusecaseImpl) keepRanksShort(task *model.Task) error {
+	if task.ProjectID == nil || len(task.BoardRank) <= MaxRankLength {
+		return nil
+	}
+	column := model.ColumnKey{ProjectID: *task.ProjectID, Status: task.Status}
+	if err := uc.repo.RebalanceColumn(column); err != nil {
+		return err
+	}
+	logger.Log.WithField("projectID", column.ProjectID).Info("Board column rebalanced on ", column.Status)
+	return nil
+}
+
+func sameProject(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
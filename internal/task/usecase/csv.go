@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"strings"
+)
+
+// exportBatch is how many tasks an export reads at a time
+const exportBatch = 500
+
+// errImportRolledBack undoes an import that is a dry run or has errors
+var errImportRolledBack = errors.New("import rolled back")
+
+// Export hands each task userID can see, in the workspace when one is given, to
+// each in ID order. Tasks are read in batches, never all at once.
+func (uc *TaskusecaseImpl) Export(userID uint, workspaceID *uint, each func(task model.Task) error) error {
+	var afterID uint
+	for {
+		tasks, err := uc.repo.FindForExport(userID, workspaceID, afterID, exportBatch)
+		if err != nil {
+			logger.Log.WithField("userID", userID).Error("Failed to read tasks for export")
+			return err
+		}
+		for _, task := range *tasks {
+			if err := each(task); err != nil {
+				return err
+			}
+		}
+		if len(*tasks) < exportBatch {
+			return nil
+		}
+		afterID = (*tasks)[len(*tasks)-1].ID
+	}
+}
+
+// Import creates a task for each row, in the project when one is given, the way
+// Create does. Every row is tried in one transaction that only commits when
+// commit is set and no row failed; rows that failed while being read are reported
+// as they are.
+func (uc *TaskusecaseImpl) Import(rows []model.ImportRow, userID uint, workspaceID, projectID *uint, commit bool) (*model.ImportResult, error) {
+	result := &model.ImportResult{DryRun: !commit, Rows: len(rows), Errors: []model.ImportError{}}
+	owner := model.Owner{WorkspaceID: workspaceID, UserID: userID}
+	// A viewer would fail every row, so refuse the import as a whole
+	if err := uc.checkContributor(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if projectID != nil {
+		if err := uc.checkProject(*projectID, owner); err != nil {
+			return nil, err
+		}
+	}
+	wf, err := uc.workflowOf(projectID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := uc.labelsByName(owner, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.atomically(func(tx *TaskusecaseImpl) error {
+		for _, row := range rows {
+			if len(row.Errors) > 0 {
+				result.Errors = append(result.Errors, row.Errors...)
+				continue
+			}
+			task, rowErr := importTask(row, labels, wf, userID)
+			if rowErr != nil {
+				result.Errors = append(result.Errors, *rowErr)
+				continue
+			}
+			task.WorkspaceID, task.ProjectID = workspaceID, projectID
+
+			// Create runs in a savepoint of its own, a failed row leaves the others be
+			err := tx.Create(task)
+			if err != nil {
+				field, ok := importField(err)
+				if !ok {
+					return err
+				}
+				result.Errors = append(result.Errors, model.ImportError{Line: row.Line, Field: field, Message: err.Error()})
+				continue
+			}
+			result.Valid++
+		}
+		if !commit || len(result.Errors) > 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		logger.Log.WithField("userID", userID).Error("Failed to import tasks")
+		return nil, err
+	}
+	if err == nil {
+		result.Imported = result.Valid
+	}
+
+	logger.Log.WithField("userID", userID).Infof("Imported %d of %d task rows, dry run %t", result.Imported, result.Rows, result.DryRun)
+	return result, nil
+}
+
+// importTask turns a row into the task it creates, or into why it cannot
+func importTask(row model.ImportRow, labels map[string]uint, wf Workflow, userID uint) (model.Task, *model.ImportError) {
+	for _, name := range row.Labels {
+		id, ok := labels[strings.ToLower(name)]
+		if !ok {
+			return model.Task{}, &model.ImportError{Line: row.Line, Field: "labels", Message: fmt.Sprintf("label %q not found", name)}
+		}
+		row.Request.LabelIDs = append(row.Request.LabelIDs, id)
+	}
+	task := model.ToTask(row.Request, userID)
+	if row.Status != "" {
+		category, ok := wf.Category(row.Status)
+		if !ok || row.Status == model.StatusOverdue {
+			return model.Task{}, &model.ImportError{Line: row.Line, Field: "status", Message: ErrUnknownStatus.Error()}
+		}
+		// A task only gets done through the state machine, which an import skips
+		if category == model.CategoryDone {
+			return model.Task{}, &model.ImportError{Line: row.Line, Field: "status", Message: ErrImportedDone.Error()}
+		}
+		task.Status = row.Status
+	}
+	return task, nil
+}
+
+// labelsByName maps the lower-cased names of the labels of owner named in rows to
+// their IDs
+func (uc *TaskusecaseImpl) labelsByName(owner model.Owner, rows []model.ImportRow) (map[string]uint, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range rows {
+		for _, name := range row.Labels {
+			if name = strings.ToLower(name); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	ids := make(map[string]uint, len(names))
+	if len(names) == 0 {
+		return ids, nil
+	}
+	labels, err := uc.repo.FindLabelsByName(owner, names)
+	if err != nil {
+		logger.Log.WithField("userID", owner.UserID).Error("Failed to load labels for import")
+		return nil, err
+	}
+	for _, l := range *labels {
+		ids[strings.ToLower(l.Name)] = l.ID
+	}
+	return ids, nil
+}
+
+// importField tells the errors of Create that are down to a row, and the column
+// at fault, from those that fail the whole import
+func importField(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrLabelNotFound):
+		return "labels", true
+	case errors.Is(err, ErrWipLimit):
+		return "status", true
+	case errors.Is(err, ErrEstimatePrecision):
+		return "estimate_points", true
+	default:
+		return "", false
+	}
+}
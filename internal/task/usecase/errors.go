@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/period"
+)
+
+var (
+	ErrTaskNotFound  = errors.New("task not found")
+	ErrNotInTrash    = errors.New("task is not in the trash")
+	ErrParentInTrash = errors.New("the parent task is in the trash, restore it first")
+
+	ErrParentNotFound = errors.New("parent task not found")
+	ErrTaskCycle      = errors.New("a task cannot be moved under itself or one of its subtasks")
+	ErrOpenSubtasks   = errors.New("task still has open subtasks")
+
+	ErrBlockerNotFound    = errors.New("blocking task not found")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrTaskBlocked        = errors.New("task is blocked by unfinished tasks")
+
+	ErrIllegalTransition = errors.New("status transition not allowed")
+	ErrUnknownStatus     = errors.New("status is not part of the task's workflow")
+
+	ErrLabelNotFound = errors.New("label not found")
+
+	ErrNotOnBoard       = errors.New("only tasks in a project are on a board")
+	ErrInvalidNeighbour = errors.New("neighbours must be other tasks of the target column, in board order")
+
+	ErrWipLimit         = errors.New("the column is at its WIP limit")
+	ErrWipLimitNotFound = errors.New("WIP limit not found")
+	ErrWipNoOwner       = errors.New("a WIP limit belongs to a project or to the active workspace")
+	ErrNotWipManager    = errors.New("only workspace admins and project owners can manage or override WIP limits")
+
+	ErrInvalidTimezone = period.ErrInvalidTimezone
+	ErrInvalidPeriod   = period.ErrInvalidPeriod
+
+	ErrProjectNotFound = errors.New("project not found")
+	ErrProjectArchived = errors.New("project is archived")
+
+	ErrInvalidRecurrence      = errors.New("invalid recurrence rule")
+	ErrRecurrenceNeedsDueDate = errors.New("a recurring task needs a due date")
+	ErrNotRecurring           = errors.New("task is not part of a recurring series")
+
+	ErrEstimatePrecision = errors.New("estimate_points takes at most one decimal place")
+
+	ErrImportedDone = errors.New("an imported task cannot start in a done status")
+
+	ErrNotCreator        = errors.New("only the task's creator or a workspace admin can do this")
+	ErrAssigneeNotFound  = errors.New("assignee not found")
+	ErrAssigneeNotMember = errors.New("assignee must be a member of the task's workspace")
+	ErrReadOnly          = errors.New("your role in this workspace only allows viewing tasks")
+)
+
+// TransitionError refuses a status change, either because the state machine has
+// no transition between the two statuses (Err is ErrIllegalTransition) or because
+// one of its guards failed (Err is what the guard returned)
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string // the statuses the task may move to instead
+	Err     error
+}
+
+func (e *TransitionError) Error() string {
+	if errors.Is(e.Err, ErrIllegalTransition) {
+		return fmt.Sprintf("a task cannot move from %s to %s", e.From, e.To)
+	}
+	return e.Err.Error()
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// WipLimitError refuses moving a task into a column that is at one of its WIP
+// limits, Load being how many tasks already count against it
+type WipLimitError struct {
+	Limit model.WipLimit
+	Load  int64
+}
+
+func (e *WipLimitError) Error() string {
+	return fmt.Sprintf("the %s column is at its WIP limit of %d tasks per %s", e.Limit.Status, e.Limit.Max, e.Limit.Scope)
+}
+
+func (e *WipLimitError) Unwrap() error {
+	return ErrWipLimit
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/period"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// estimatePeriodDays is the span of an estimate report naming no first day, four
+// weeks back from today
+const estimatePeriodDays = 28
+
+// maxOutliers caps the outliers listed in an estimate report
+const maxOutliers = 10
+
+// checkPoints refuses point estimates finer than the tenths estimate_points keeps
+func checkPoints(points *float64) error {
+	if points == nil {
+		return nil
+	}
+	tenths := *points * 10
+	if math.Abs(tenths-math.Round(tenths)) > 1e-9 {
+		return ErrEstimatePrecision
+	}
+	return nil
+}
+
+// GetEstimateReport compares the estimates of the tasks userID can see that were
+// completed in the period of query with the effort they took: the time logged on
+// them, or else the time from when they were first started until done. Point
+// estimates count at the minutes per point the period's point tasks took on
+// average, so they show how evenly points were handed out. A project has to be
+// one userID reaches in the active workspace.
+func (uc *TaskusecaseImpl) GetEstimateReport(userID uint, query model.EstimateQuery) (*model.EstimateReport, error) {
+	days, err := period.Days(query.From, query.To, query.TZ, time.Now(), estimatePeriodDays)
+	if err != nil {
+		return nil, err
+	}
+	if query.ProjectID != nil {
+		if err := uc.reachProject(*query.ProjectID, userID, query.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+	tasks, err := uc.repo.FindCompletedEstimates(userID, query.WorkspaceID, query.ProjectID, days.From.UTC(), days.To.UTC())
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find estimated tasks")
+		return nil, err
+	}
+	ids := make([]uint, 0, len(*tasks))
+	for _, t := range *tasks {
+		ids = append(ids, t.ID)
+	}
+	logged, err := uc.repo.FindLoggedTime(ids)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find logged time of estimated tasks")
+		return nil, err
+	}
+
+	report := &model.EstimateReport{From: days.From, To: days.To, TZ: days.Loc.String()}
+	workflows := make(map[uint]Workflow)
+	var samples []model.EstimateSample
+	var points, pointMinutes float64
+	for _, task := range *tasks {
+		wf, err := uc.cachedWorkflow(workflows, task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		sample, ok := measure(task, logged[task.ID], wf)
+		if !ok {
+			report.Unmeasured++
+			continue
+		}
+		if sample.Unit == model.UnitPoints {
+			points += sample.Estimate
+			pointMinutes += sample.ActualMinutes
+		}
+		samples = append(samples, sample)
+	}
+	if points > 0 {
+		report.MinutesPerPoint = pointMinutes / points
+	}
+	for i := range samples {
+		if samples[i].Unit == model.UnitPoints {
+			samples[i].EstimatedMinutes = samples[i].Estimate * report.MinutesPerPoint
+		}
+		samples[i].Ratio = samples[i].ActualMinutes / samples[i].EstimatedMinutes
+	}
+
+	byUser := newEstimateGroups()
+	byLabel := newEstimateGroups()
+	byWeek := newEstimateGroups()
+	labels := make(map[uint][]labelKey, len(*tasks))
+	for _, task := range *tasks {
+		for _, l := range task.Labels {
+			labels[task.ID] = append(labels[task.ID], labelKey{strconv.FormatUint(uint64(l.ID), 10), l.Name})
+		}
+	}
+	var overall model.EstimateStats
+	for _, s := range samples {
+		add(&overall, s)
+		byUser.add(strconv.FormatUint(uint64(s.WorkerID), 10), "", s)
+		if len(labels[s.TaskID]) == 0 {
+			byLabel.add("none", "", s)
+		}
+		for _, l := range labels[s.TaskID] {
+			byLabel.add(l.key, l.name, s)
+		}
+		year, week := s.CompletedAt.In(days.Loc).ISOWeek()
+		byWeek.add(fmt.Sprintf("%d-W%02d", year, week), mondayOf(s.CompletedAt.In(days.Loc)), s)
+	}
+	report.Overall = finish(overall)
+	report.ByUser = byUser.list(false)
+	report.ByLabel = byLabel.list(false)
+	report.ByWeek = byWeek.list(true)
+	report.Outliers = outliers(samples)
+	return report, nil
+}
+
+// measure pairs the estimate of a completed task with the effort it took, false
+// when there is no effort to go by
+func measure(task model.Task, logged time.Duration, wf Workflow) (model.EstimateSample, bool) {
+	sample := model.EstimateSample{TaskID: task.ID, Title: task.Title, WorkerID: task.Worker()}
+	switch {
+	case task.EstimateMinutes != nil:
+		sample.Unit = model.UnitMinutes
+		sample.Estimate = float64(*task.EstimateMinutes)
+		sample.EstimatedMinutes = sample.Estimate
+	case task.EstimatePoints != nil:
+		sample.Unit = model.UnitPoints
+		sample.Estimate = *task.EstimatePoints
+	default:
+		return sample, false
+	}
+
+	var started time.Time
+	for _, e := range task.StatusEntries {
+		if e.Status == task.Status {
+			sample.CompletedAt = e.EnteredAt
+		}
+		if category, _ := wf.Category(e.Status); category == model.CategoryInProgress && (started.IsZero() || e.EnteredAt.Before(started)) {
+			started = e.EnteredAt
+		}
+	}
+	switch {
+	case logged > 0:
+		sample.Source = model.ActualLogged
+		sample.ActualMinutes = logged.Minutes()
+	case !started.IsZero() && sample.CompletedAt.After(started):
+		sample.Source = model.ActualDerived
+		sample.ActualMinutes = sample.CompletedAt.Sub(started).Minutes()
+	default:
+		return sample, false
+	}
+	return sample, sample.Estimate > 0
+}
+
+// outliers are the samples off by OutlierFactor or more either way, the worst first
+func outliers(samples []model.EstimateSample) []model.EstimateSample {
+	res := []model.EstimateSample{}
+	for _, s := range samples {
+		if s.Ratio >= model.OutlierFactor || s.Ratio <= 1/model.OutlierFactor {
+			res = append(res, s)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return math.Abs(math.Log(res[i].Ratio)) > math.Abs(math.Log(res[j].Ratio))
+	})
+	if len(res) > maxOutliers {
+		res = res[:maxOutliers]
+	}
+	return res
+}
+
+func (uc *TaskusecaseImpl) cachedWorkflow(cache map[uint]Workflow, projectID *uint) (Workflow, error) {
+	if projectID == nil {
+		return BuiltinWorkflow(), nil
+	}
+	if wf, ok := cache[*projectID]; ok {
+		return wf, nil
+	}
+	wf, err := uc.workflowOf(projectID)
+	if err != nil {
+		return Workflow{}, err
+	}
+	cache[*projectID] = wf
+	return wf, nil
+}
+
+type labelKey struct {
+	key, name string
+}
+
+// estimateGroups collects the stats of a report breakdown in the order groups appear
+type estimateGroups struct {
+	index  map[string]int
+	groups []model.EstimateGroup
+}
+
+func newEstimateGroups() *estimateGroups {
+	return &estimateGroups{index: make(map[string]int)}
+}
+
+func (g *estimateGroups) add(key, name string, s model.EstimateSample) {
+	i, ok := g.index[key]
+	if !ok {
+		i = len(g.groups)
+		g.index[key] = i
+		g.groups = append(g.groups, model.EstimateGroup{Key: key, Name: name})
+	}
+	add(&g.groups[i].EstimateStats, s)
+}
+
+// list finishes the groups, sorted by key or else by the most tasks first
+func (g *estimateGroups) list(byKey bool) []model.EstimateGroup {
+	res := make([]model.EstimateGroup, 0, len(g.groups))
+	for _, group := range g.groups {
+		group.EstimateStats = finish(group.EstimateStats)
+		res = append(res, group)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if byKey || res[i].Tasks == res[j].Tasks {
+			return res[i].Key < res[j].Key
+		}
+		return res[i].Tasks > res[j].Tasks
+	})
+	return res
+}
+
+// add counts s into stats, OnTarget holding the number of tasks on target until
+// finish turns it into a share
+func add(stats *model.EstimateStats, s model.EstimateSample) {
+	stats.Tasks++
+	stats.EstimatedMinutes += s.EstimatedMinutes
+	stats.ActualMinutes += s.ActualMinutes
+	if math.Abs(s.Ratio-1) <= model.EstimateMargin {
+		stats.OnTarget++
+	}
+}
+
+func finish(stats model.EstimateStats) model.EstimateStats {
+	if stats.EstimatedMinutes > 0 {
+		stats.Ratio = stats.ActualMinutes / stats.EstimatedMinutes
+	}
+	if stats.Tasks > 0 {
+		stats.OnTarget /= float64(stats.Tasks)
+	}
+	return stats
+}
+
+// mondayOf is the Monday starting the ISO week of t, written as a day
+func mondayOf(t time.Time) string {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.Date()
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location()).Format(period.DayLayout)
+}
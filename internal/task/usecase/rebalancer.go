@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"mymodule/pkg/logger"
+	"time"
+)
+
+const (
+	// MaxRankLength is how long a board rank may grow before its column is rebalanced
+	MaxRankLength = 12
+	// rebalancerBatch caps the columns rebalanced per query
+	rebalancerBatch = 50
+)
+
+// Rebalancer keeps board ranks short. Every move takes a rank between two others,
+// so moving tasks into the same gap again and again makes ranks grow a digit at a
+// time; columns holding a rank past MaxRankLength get fresh, evenly spaced ranks.
+type Rebalancer struct {
+	repo     TaskRepository
+	interval time.Duration
+}
+
+func NewRebalancer(repo TaskRepository, interval time.Duration) *Rebalancer {
+	return &Rebalancer{
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// Start runs the rebalancer until ctx is cancelled, rebalancing once right away
+func (r *Rebalancer) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil {
+			logger.Log.Error("Rank rebalancer run failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce rebalances every crowded column and returns how many there were
+func (r *Rebalancer) RunOnce(ctx context.Context) (int, error) {
+	rebalanced := 0
+	for {
+		if ctx.Err() != nil {
+			return rebalanced, ctx.Err()
+		}
+		columns, err := r.repo.FindCrowdedColumns(MaxRankLength, rebalancerBatch)
+		if err != nil {
+			return rebalanced, err
+		}
+		for _, column := range columns {
+			if err := r.repo.RebalanceColumn(column); err != nil {
+				return rebalanced, err
+			}
+			rebalanced++
+			logger.Log.WithField("projectID", column.ProjectID).Info("Rebalanced board column ", column.Status)
+		}
+
+		if len(columns) < rebalancerBatch {
+			return rebalanced, nil
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"fmt"
+	projectModel "mymodule/internal/project/model"
+	"mymodule/internal/task/model"
+	"mymodule/pkg/logger"
+	"time"
+)
+
+// Workflow is the set of statuses a task moves through: those of its project's
+// workflow, or the built-in ones. Overdue belongs to every workflow.
+type Workflow struct {
+	statuses []projectModel.WorkflowStatus
+}
+
+// NewWorkflow wraps statuses given in board order
+func NewWorkflow(statuses []projectModel.WorkflowStatus) Workflow {
+	return Workflow{statuses: statuses}
+}
+
+// BuiltinWorkflow holds the built-in statuses
+func BuiltinWorkflow() Workflow {
+	return NewWorkflow(projectModel.DefaultStatuses())
+}
+
+// Category is the category of status, false when the workflow has no such status
+func (w Workflow) Category(status string) (string, bool) {
+	if status == model.StatusOverdue {
+		return model.CategoryTodo, true
+	}
+	for _, s := range w.statuses {
+		if s.Key == status {
+			return s.Category, true
+		}
+	}
+	return "", false
+}
+
+// Initial is the status new tasks start in, the first of the todo category
+func (w Workflow) Initial() string {
+	return w.First(model.CategoryTodo)
+}
+
+// First is the first status of category, the initial status when there is none
+func (w Workflow) First(category string) string {
+	for _, s := range w.statuses {
+		if s.Category == category {
+			return s.Key
+		}
+	}
+	if category != model.CategoryTodo {
+		return w.Initial()
+	}
+	return w.statuses[0].Key
+}
+
+// Place is where a task in status of the given category belongs in the workflow:
+// that status when the workflow has it, otherwise the first of the same category
+func (w Workflow) Place(status, category string) string {
+	if _, ok := w.Category(status); ok {
+		return status
+	}
+	return w.First(category)
+}
+
+// Allowed lists the statuses a user may move a task in status from to: any other
+// status of the workflow, though an overdue task only to those of the done category
+func (w Workflow) Allowed(from string) []string {
+	allowed := []string{}
+	for _, s := range w.statuses {
+		if s.Key == from || from == model.StatusOverdue && s.Category != model.CategoryDone {
+			continue
+		}
+		allowed = append(allowed, s.Key)
+	}
+	return allowed
+}
+
+// Transition is a task moving from one status to another
+type Transition struct {
+	Task    *model.Task
+	From    string
+	To      string
+	ActorID uint
+}
+
+// Guard vets a transition before the task changes, an error refuses it
+type Guard func(uc *TaskusecaseImpl, t Transition) error
+
+// Hook runs once a task has entered its new status and been saved
+type Hook func(uc *TaskusecaseImpl, t Transition) error
+
+// StatusMachine moves tasks through their workflow. Users may take the transitions
+// the workflow allows, provided every guard on the category of the new status
+// passes. The system moves open tasks in and out of overdue on its own as their
+// due dates pass or change, see SetStatusBasedOnDueDate.
+type StatusMachine struct {
+	guards map[string][]Guard
+	hooks  map[string][]Hook
+}
+
+// NewStatusMachine builds a machine without guards or hooks
+func NewStatusMachine() *StatusMachine {
+	return &StatusMachine{
+		guards: map[string][]Guard{},
+		hooks:  map[string][]Hook{},
+	}
+}
+
+// DefaultStatusMachine holds the rules every task follows: a blocked task can not
+// be started or finished, nor a task with open subtasks finished, and finishing a
+// recurring task schedules its next occurrence
+func DefaultStatusMachine() *StatusMachine {
+	return NewStatusMachine().
+		Guard(model.CategoryDone, noOpenSubtasks).
+		Guard(model.CategoryInProgress, notBlocked).
+		Guard(model.CategoryDone, notBlocked).
+		OnEnter(model.CategoryDone, scheduleNextOccurrence)
+}
+
+// Guard adds a check every transition into a status of category has to pass, in
+// the order added
+func (m *StatusMachine) Guard(category string, guard Guard) *StatusMachine {
+	m.guards[category] = append(m.guards[category], guard)
+	return m
+}
+
+// OnEnter adds a hook that runs whenever a task enters a status of category, in
+// the order added
+func (m *StatusMachine) OnEnter(category string, hook Hook) *StatusMachine {
+	m.hooks[category] = append(m.hooks[category], hook)
+	return m
+}
+
+// Check refuses moving t.Task to t.To unless it is a status of wf, wf allows the
+// transition and the guards pass. Staying in the same status always does.
+func (m *StatusMachine) Check(uc *TaskusecaseImpl, wf Workflow, t Transition) error {
+	if t.To == t.From {
+		return nil
+	}
+	category, ok := wf.Category(t.To)
+	if !ok {
+		logger.Log.WithFields(logger.LogFields(t.Task.ID, t.ActorID)).Warn("Update failed: status not in workflow")
+		return fmt.Errorf("%w: %s", ErrUnknownStatus, t.To)
+	}
+
+	allowed := wf.Allowed(t.From)
+	legal := false
+	for _, to := range allowed {
+		legal = legal || to == t.To
+	}
+	if !legal {
+		logger.Log.WithFields(logger.LogFields(t.Task.ID, t.ActorID)).Warnf("Illegal status transition from %s to %s", t.From, t.To)
+		return &TransitionError{From: t.From, To: t.To, Allowed: allowed, Err: ErrIllegalTransition}
+	}
+
+	for _, guard := range m.guards[category] {
+		if err := guard(uc, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enter records that t.Task entered t.To at the given time and runs the hooks of
+// its category
+func (m *StatusMachine) Enter(uc *TaskusecaseImpl, wf Workflow, t Transition, at time.Time) error {
+	if err := uc.repo.EnterStatus(t.Task.ID, t.To, at); err != nil {
+		logger.Log.WithField("taskID", t.Task.ID).Error("Failed to record status entry")
+		return err
+	}
+	category, _ := wf.Category(t.To)
+	for _, hook := range m.hooks[category] {
+		if err := hook(uc, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentStatus reads an unset status as pending, the default of new tasks
+func currentStatus(status string) string {
+	if status == "" {
+		return model.StatusPending
+	}
+	return status
+}
+
+// noOpenSubtasks keeps a task from being finished before all of its subtasks are
+func noOpenSubtasks(uc *TaskusecaseImpl, t Transition) error {
+	descendants, err := uc.repo.FindDescendants(t.Task.ID, t.Task.UserID)
+	if err != nil {
+		logger.Log.WithField("taskID", t.Task.ID).Error("Failed to check subtasks before completing")
+		return err
+	}
+	for _, child := range *descendants {
+		if !child.Done() {
+			logger.Log.WithField("taskID", t.Task.ID).Warn("Update failed: task has open subtasks")
+			return &TransitionError{From: t.From, To: t.To, Err: ErrOpenSubtasks}
+		}
+	}
+	return nil
+}
+
+// notBlocked keeps a blocked task from being started or finished
+func notBlocked(uc *TaskusecaseImpl, t Transition) error {
+	blockers, err := uc.repo.FindBlockers(t.Task.ID, t.Task.UserID)
+	if err != nil {
+		logger.Log.WithField("taskID", t.Task.ID).Error("Failed to check blockers before update")
+		return err
+	}
+	for _, blocker := range *blockers {
+		if !blocker.Done() {
+			logger.Log.WithField("taskID", t.Task.ID).Warn("Update failed: task is blocked")
+			return &TransitionError{From: t.From, To: t.To, Err: ErrTaskBlocked}
+		}
+	}
+	return nil
+}
+
+// scheduleNextOccurrence generates the next occurrence of a finished recurring task
+func scheduleNextOccurrence(uc *TaskusecaseImpl, t Transition) error {
+	if t.Task.Series == nil {
+		return nil
+	}
+	if err := uc.nextOccurrence(t.Task, t.Task.Series, t.ActorID); err != nil {
+		logger.Log.WithField("taskID", t.Task.ID).Error("Failed to generate next occurrence")
+		return err
+	}
+	return nil
+}
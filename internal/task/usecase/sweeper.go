@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"mymodule/pkg/logger"
+	"time"
+)
+
+const (
+	// DefaultTrashRetentionDays is how long deleted tasks stay restorable
+	DefaultTrashRetentionDays = 30
+	// sweeperBatch caps the tasks purged per query
+	sweeperBatch = 100
+)
+
+// Sweeper empties the trash: tasks deleted longer ago than the retention period
+// are purged for good, with their subtasks, history and everything else that
+// hangs off them. Their attachment files are left to the attachment collector.
+type Sweeper struct {
+	repo      TaskRepository
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewSweeper(repo TaskRepository, retention, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		repo:      repo,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the sweeper until ctx is cancelled, sweeping once right away
+func (s *Sweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			logger.Log.Error("Trash sweeper run failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges every task past the retention period and returns how many went
+func (s *Sweeper) RunOnce(ctx context.Context) (int, error) {
+	purged := 0
+	before := s.now().Add(-s.retention)
+	for {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		ids, err := s.repo.FindExpiredTrash(before, sweeperBatch)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) > 0 {
+			if err := s.repo.Purge(ids); err != nil {
+				return purged, err
+			}
+			purged += len(ids)
+			logger.Log.WithField("tasks", len(ids)).Info("Purged expired tasks from the trash")
+		}
+
+		if len(ids) < sweeperBatch {
+			return purged, nil
+		}
+	}
+}
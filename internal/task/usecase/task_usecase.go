@@ -3,100 +3,363 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"math"
+	labelModel "mymodule/internal/label/model"
+	projectModel "mymodule/internal/project/model"
 	"mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
 	"mymodule/pkg/logger"
+	"mymodule/pkg/recurrence"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type TaskRepository interface {
-	Save(task model.Task) error
+	Transaction(fn func(repo TaskRepository) error) error
+	Save(task *model.Task) error
 	FindByID(taskID uint) (*model.Task, error)
-	FindByUser(userID uint) (*[]model.Task, error)
+	FindByUser(userID uint, filter model.TaskFilter) (*[]model.Task, int64, error)
+	FindAll(filter model.TaskFilter) (*[]model.Task, int64, error)
 	FindByIDAndUser(taskID, userID uint) (*model.Task, error)
+	FindChildren(taskID, userID uint) (*[]model.Task, error)
+	FindDescendants(taskID, userID uint) (*[]model.Task, error)
+	AddDependency(taskID, blockedByID uint) error
+	RemoveDependency(taskID, blockedByID uint) error
+	FindBlockers(taskID, userID uint) (*[]model.Task, error)
+	FindBlocked(taskID, userID uint) (*[]model.Task, error)
+	DependsOn(taskID, blockerID uint) (bool, error)
+	Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error)
+	SearchAll(query string, limit int) (*[]model.TaskSearchResult, error)
 	Update(task *model.Task) error
+	FindLabels(owner model.Owner, labelIDs []uint) (*[]labelModel.Label, error)
+	ReplaceLabels(task *model.Task, labels []labelModel.Label) error
+	FindProject(projectID uint, owner model.Owner) (*projectModel.Project, error)
+	FindWorkflow(projectID uint) (*projectModel.Workflow, error)
+	FindUser(userID uint) (*userModel.User, error)
+	FindRole(workspaceID, userID uint) (string, error)
+	SaveWithSeries(series *model.TaskSeries, task *model.Task) error
+	SaveSeries(series *model.TaskSeries) error
+	SaveOccurrence(series *model.TaskSeries, task *model.Task) error
+	ShiftReminders(taskID uint, dueDate time.Time) error
 	Delete(taskID uint) error
+	FindTrash(userID uint, workspaceID *uint) (*[]model.Task, error)
+	FindTrashed(taskID, userID uint) (*model.Task, error)
+	FindTrashedDescendants(taskID uint) (*[]model.Task, error)
+	Restore(taskIDs []uint) error
+	Purge(taskIDs []uint) error
+	FindExpiredTrash(before time.Time, limit int) ([]uint, error)
+	AddHistory(entries []model.TaskHistory) error
+	EnterStatus(taskID uint, status string, at time.Time) error
+	FindHistory(taskID, userID uint) (*[]model.TaskHistory, error)
 	UpdateOverdueTasks(userID uint) error
+	LastRank(projectID uint, status string) (string, error)
+	RankAfter(column model.ColumnKey, boardRank string, excludeID uint) (string, error)
+	RankBefore(column model.ColumnKey, boardRank string, excludeID uint) (string, error)
+	FindBoard(projectID, userID uint) (*[]model.Task, error)
+	FindCrowdedColumns(maxLength, limit int) ([]model.ColumnKey, error)
+	RebalanceColumn(column model.ColumnKey) error
+	FindWipLimits(workspaceID, projectID *uint) ([]model.WipLimit, error)
+	FindWipLimit(limitID uint) (*model.WipLimit, error)
+	SaveWipLimit(limit *model.WipLimit) error
+	DeleteWipLimit(limitID uint) error
+	LockWipLimit(limitID uint) error
+	CountWip(query model.WipQuery) (int64, error)
+	FindCompletedEstimates(userID uint, workspaceID, projectID *uint, from, to time.Time) (*[]model.Task, error)
+	FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error)
+	FindLabelsByName(owner model.Owner, names []string) (*[]labelModel.Label, error)
+	FindForExport(userID uint, workspaceID *uint, afterID uint, limit int) (*[]model.Task, error)
 }
 
 type TaskUsecase interface {
 	Create(task model.Task) error
 	GetByID(taskID uint) (*model.Task, error)
-	GetByUser(userID uint) (*[]model.Task, error)
+	GetByUser(userID uint, filter model.TaskFilter) (*model.TaskPage, error)
 	GetByIDAndUser(taskID, userID uint) (*model.Task, error)
+	GetDetail(taskID, userID uint) (*model.TaskDetail, error)
+	GetSubtasks(taskID, userID uint) (*[]model.Task, error)
+	Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error)
 	UpdateTask(task *model.UpdateTaskInput, taskID, userID uint) error
+	MoveTask(taskID, userID uint, parentID *uint) error
+	AddDependency(taskID, blockedByID, userID uint) error
+	RemoveDependency(taskID, blockedByID, userID uint) error
+	EndSeries(taskID, userID uint) error
 	DeleteTask(taskID, userID uint) error
+	GetTrash(userID uint, workspaceID *uint) (*[]model.Task, error)
+	Restore(taskID, userID uint) error
+	Purge(taskID, userID uint) error
+	GetHistory(taskID, userID uint) (*[]model.TaskHistory, error)
+	Bulk(req model.BulkRequest, userID uint) (*model.BulkResult, error)
+	GetBoard(projectID, userID uint, workspaceID *uint) (*model.Board, error)
+	MoveOnBoard(taskID, userID uint, req model.BoardMoveRequest) error
+	GetWipLimits(userID uint, workspaceID, projectID *uint) ([]model.WipLimit, error)
+	SetWipLimit(limit model.WipLimit, userID uint, workspaceID *uint) (*model.WipLimit, error)
+	DeleteWipLimit(limitID, userID uint, workspaceID *uint) error
+	GetEstimateReport(userID uint, query model.EstimateQuery) (*model.EstimateReport, error)
+	Export(userID uint, workspaceID *uint, each func(task model.Task) error) error
+	Import(rows []model.ImportRow, userID uint, workspaceID, projectID *uint, commit bool) (*model.ImportResult, error)
+
+	// Admin only, regardless of who may see the task
+	GetAll(filter model.TaskFilter) (*model.TaskPage, error)
+	SearchAll(query string, limit int) (*[]model.TaskSearchResult, error)
+	ForceDelete(taskID, adminID uint) error
 }
 
 type TaskusecaseImpl struct {
-	repo TaskRepository
+	repo    TaskRepository
+	machine *StatusMachine
 }
 
 func NewTaskUsecase(repo TaskRepository) TaskUsecase {
 	return &TaskusecaseImpl{
-		repo: repo,
+		repo:    repo,
+		machine: DefaultStatusMachine(),
+	}
+}
+
+// SetStatusBasedOnDueDate places task in a status of wf with its category and
+// makes the transitions the system takes on its own: an open task past its due
+// date is overdue, an overdue task given a due date in the future starts over
+func (uc *TaskusecaseImpl) SetStatusBasedOnDueDate(task *model.Task, wf Workflow) {
+	task.Status = wf.Place(currentStatus(task.Status), task.StatusCategory)
+	task.StatusCategory, _ = wf.Category(task.Status)
+	overdue := task.DueDate != nil && task.DueDate.Before(time.Now())
+	switch {
+	case overdue && !task.Done():
+		task.Status = model.StatusOverdue
+	case !overdue && task.Status == model.StatusOverdue:
+		task.Status = wf.Initial()
 	}
+	task.StatusCategory, _ = wf.Category(task.Status)
 }
 
-func (uc *TaskusecaseImpl) SetStatusBasedOnDueDate(task *model.Task) {
-	if task.DueDate != nil && task.DueDate.Before(time.Now()) {
-		task.Status = "overdue"
-	} else if task.Status == "" {
-		task.Status = "pending"
+// workflowOf returns the workflow of the project, the built-in one for tasks
+// outside any project or in a project without a workflow of its own
+func (uc *TaskusecaseImpl) workflowOf(projectID *uint) (Workflow, error) {
+	if projectID == nil {
+		return BuiltinWorkflow(), nil
 	}
+	flow, err := uc.repo.FindWorkflow(*projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return BuiltinWorkflow(), nil
+	}
+	if err != nil {
+		logger.Log.WithField("projectID", *projectID).Error("Failed to find project workflow")
+		return Workflow{}, err
+	}
+	return NewWorkflow(flow.Statuses), nil
 }
 
+// Create adds a task in the first status of its workflow, overdue right away when
+// its due date has already passed
 func (uc *TaskusecaseImpl) Create(task model.Task) error {
-	uc.SetStatusBasedOnDueDate(&task)
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.create(task)
+	})
+}
+
+func (uc *TaskusecaseImpl) create(task model.Task) error {
+	if err := checkPoints(task.EstimatePoints); err != nil {
+		return err
+	}
+
+	if len(task.Labels) > 0 {
+		ids := make([]uint, 0, len(task.Labels))
+		for _, l := range task.Labels {
+			ids = append(ids, l.ID)
+		}
+		labels, err := uc.resolveLabels(model.OwnerOf(task), ids)
+		if err != nil {
+			return err
+		}
+		task.Labels = labels
+	}
+
+	if task.ProjectID != nil {
+		if err := uc.checkProject(*task.ProjectID, model.OwnerOf(task)); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.checkContributor(task.WorkspaceID, task.UserID); err != nil {
+		return err
+	}
+
+	if task.AssigneeID != nil {
+		if err := uc.checkAssignee(*task.AssigneeID, task.WorkspaceID); err != nil {
+			return err
+		}
+	}
+
+	if task.ParentID != nil {
+		if _, err := uc.findRelated(*task.ParentID, task.UserID, task.WorkspaceID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Log.WithField("parentID", *task.ParentID).Warn("Create failed: parent task not found")
+				return ErrParentNotFound
+			}
+			return err
+		}
+	}
+
+	wf, err := uc.workflowOf(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if _, ok := wf.Category(task.Status); !ok || task.Status == model.StatusOverdue {
+		task.Status = wf.Initial()
+	}
+	uc.SetStatusBasedOnDueDate(&task, wf)
+	if err := uc.checkWip(&task, task.UserID, false); err != nil {
+		return err
+	}
+	if err := uc.placeLast(&task); err != nil {
+		return err
+	}
 
-	// Valid duedate
-	if task.DueDate != nil && task.DueDate.Before(time.Now()) {
-		return errors.New("invalid due date")
+	if task.Series != nil {
+		rule := task.Series.RRule
+		task.Series = nil
+		series, err := uc.scheduleSeries(&task, rule)
+		if err != nil {
+			return err
+		}
+		if err := uc.repo.SaveWithSeries(series, &task); err != nil {
+			logger.Log.WithField("userID", task.UserID).Error("Failed to create recurring task")
+			return err
+		}
+		if err := uc.keepRanksShort(&task); err != nil {
+			return err
+		}
+		task.Series = series
+		if err := uc.record(task.ID, task.UserID, model.ActionCreated, model.TaskSnapshot{}.Diff(model.Snapshot(task))); err != nil {
+			return err
+		}
+		if err := uc.repo.EnterStatus(task.ID, task.Status, task.CreatedAt); err != nil {
+			return err
+		}
+		logger.Log.WithField("userID", task.UserID).Info("Recurring task created successfully")
+		return nil
 	}
 
-	if err := uc.repo.Save(task); err != nil {
+	if err := uc.repo.Save(&task); err != nil {
 		logger.Log.WithField("userID", task.UserID).Error("Failed to create task")
 		return err
 	}
+	if err := uc.keepRanksShort(&task); err != nil {
+		return err
+	}
+	if err := uc.record(task.ID, task.UserID, model.ActionCreated, model.TaskSnapshot{}.Diff(model.Snapshot(task))); err != nil {
+		return err
+	}
+	if err := uc.repo.EnterStatus(task.ID, task.Status, task.CreatedAt); err != nil {
+		return err
+	}
 
 	logger.Log.WithField("userID", task.UserID).Info("Task created successfully")
 	return nil
 
 }
 
-// For admin
+// GetByID reads any task regardless of who may see it, for admins
 func (uc *TaskusecaseImpl) GetByID(taskID uint) (*model.Task, error) {
-	// task, err := uc.repo.FindByID(taskID)
-	// if err != nil {
-	// 	if errors.Is(err, gorm.ErrRecordNotFound) {
-	// 		logger.Log.WithField("taskID", taskID).Warn("Task not found")
-	// 		return nil, fmt.Errorf("task not found")
-	// 	}
-	// 	logger.Log.WithField("taskID", taskID).Error("Failed to get task by ID")
-	// 	return nil, err
-	// }
-	// logger.Log.WithField("taskID", taskID).Info("Task retrieved by ID")
-	// return task, nil
-	return &model.Task{}, nil
-}
-
-func (uc *TaskusecaseImpl) GetByUser(userID uint) (*[]model.Task, error) {
+	task, err := uc.repo.FindByID(taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("taskID", taskID).Warn("Task not found")
+			return nil, ErrTaskNotFound
+		}
+		logger.Log.WithField("taskID", taskID).Error("Failed to get task by ID")
+		return nil, err
+	}
+	logger.Log.WithField("taskID", taskID).Info("Task retrieved by ID")
+	return task, nil
+}
+
+func (uc *TaskusecaseImpl) GetByUser(userID uint, filter model.TaskFilter) (*model.TaskPage, error) {
 
 	if err := uc.repo.UpdateOverdueTasks(userID); err != nil {
 		logger.Log.WithField("userID", userID).Error("Failed to update overdue tasks")
 		return nil, err
 	}
 
-	tasks, err := uc.repo.FindByUser(userID)
+	filter, limit := pageFilter(filter)
+	tasks, total, err := uc.repo.FindByUser(userID, filter)
 
 	if err != nil {
 		logger.Log.WithField("userID", userID).Error("Failed to get tasks by user")
 		return nil, err
 	}
 
+	page := buildTaskPage(*tasks, total, limit, filter)
 	logger.Log.WithField("userID", userID).Info("Tasks retrieved for user")
-	return tasks, nil
+	return page, nil
+}
+
+// GetAll lists every user's tasks, for admins
+func (uc *TaskusecaseImpl) GetAll(filter model.TaskFilter) (*model.TaskPage, error) {
+	filter, limit := pageFilter(filter)
+	tasks, total, err := uc.repo.FindAll(filter)
+	if err != nil {
+		logger.Log.Error("Failed to get all tasks: ", err)
+		return nil, err
+	}
+
+	logger.Log.Info("All tasks retrieved")
+	return buildTaskPage(*tasks, total, limit, filter), nil
+}
+
+// pageFilter prepares a listing filter for the repository and returns the page
+// size asked for
+func pageFilter(filter model.TaskFilter) (model.TaskFilter, int) {
+	// A cursor always pages in the order it was issued for
+	if filter.Cursor != nil {
+		filter.SortBy, filter.Order = filter.Cursor.SortBy, filter.Cursor.Order
+	}
+
+	// Ask for one extra row to know whether another page follows
+	limit := filter.Limit
+	if limit > 0 {
+		filter.Limit = limit + 1
+	}
+	return filter, limit
+}
+
+func buildTaskPage(tasks []model.Task, total int64, limit int, filter model.TaskFilter) *model.TaskPage {
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	hasMore := limit > 0 && len(tasks) > limit
+	if hasMore {
+		if backward {
+			tasks = tasks[len(tasks)-limit:]
+		} else {
+			tasks = tasks[:limit]
+		}
+	}
+
+	page := &model.TaskPage{Tasks: tasks, Total: total}
+	if len(tasks) == 0 {
+		// Ran off the end, the only way is back to where the cursor pointed
+		if filter.Cursor != nil {
+			back := *filter.Cursor
+			back.Backward = !back.Backward
+			if backward {
+				page.Next = &back
+			} else {
+				page.Prev = &back
+			}
+		}
+		return page
+	}
+
+	first, last := tasks[0], tasks[len(tasks)-1]
+	if hasMore || backward {
+		page.Next = model.ToTaskCursor(last, filter.SortBy, filter.Order, false)
+	}
+	if (backward && hasMore) || (!backward && (filter.Cursor != nil || filter.Offset > 0)) {
+		page.Prev = model.ToTaskCursor(first, filter.SortBy, filter.Order, true)
+	}
+	return page
 }
 
 func (uc *TaskusecaseImpl) GetByIDAndUser(taskID, userID uint) (*model.Task, error) {
@@ -106,7 +369,7 @@ func (uc *TaskusecaseImpl) GetByIDAndUser(taskID, userID uint) (*model.Task, err
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 
 			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for this user")
-			return nil, fmt.Errorf("task not found for user")
+			return nil, ErrTaskNotFound
 		}
 		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get task by ID and user")
 		return nil, err
@@ -116,46 +379,968 @@ func (uc *TaskusecaseImpl) GetByIDAndUser(taskID, userID uint) (*model.Task, err
 	return task, nil
 }
 
+func (uc *TaskusecaseImpl) GetDetail(taskID, userID uint) (*model.TaskDetail, error) {
+	task, err := uc.GetByIDAndUser(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The subtasks and dependencies of a task are those of its creator
+	descendants, err := uc.repo.FindDescendants(taskID, task.UserID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get subtasks for progress")
+		return nil, err
+	}
+
+	blockedBy, err := uc.repo.FindBlockers(taskID, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := uc.repo.FindBlocked(taskID, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TaskDetail{
+		Task:      *task,
+		Progress:  completionPercent(*descendants),
+		BlockedBy: *blockedBy,
+		Blocks:    *blocks,
+	}, nil
+}
+
+func (uc *TaskusecaseImpl) GetSubtasks(taskID, userID uint) (*[]model.Task, error) {
+	task, err := uc.GetByIDAndUser(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := uc.repo.FindChildren(taskID, task.UserID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get subtasks")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Subtasks retrieved")
+	return children, nil
+}
+
+// completionPercent is the share of completed tasks, nil when there are none
+func completionPercent(tasks []model.Task) *float64 {
+	if len(tasks) == 0 {
+		return nil
+	}
+	completed := 0
+	for _, t := range tasks {
+		if t.Done() {
+			completed++
+		}
+	}
+	percent := math.Round(float64(completed)/float64(len(tasks))*10000) / 100
+	return &percent
+}
+
+func (uc *TaskusecaseImpl) Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+
+	results, err := uc.repo.Search(userID, query, limit)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to search tasks")
+		return nil, err
+	}
+
+	logger.Log.WithField("userID", userID).Info("Tasks searched for user")
+	return results, nil
+}
+
+// SearchAll searches every user's tasks, for admins
+func (uc *TaskusecaseImpl) SearchAll(query string, limit int) (*[]model.TaskSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+
+	results, err := uc.repo.SearchAll(query, limit)
+	if err != nil {
+		logger.Log.Error("Failed to search all tasks: ", err)
+		return nil, err
+	}
+	return results, nil
+}
+
 func (uc *TaskusecaseImpl) UpdateTask(input *model.UpdateTaskInput, taskID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.updateTask(input, taskID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) updateTask(input *model.UpdateTaskInput, taskID, userID uint) error {
     existingTask, err := uc.repo.FindByIDAndUser(taskID, userID)
     if err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
             logger.Log.WithField("taskID", taskID).Warn("Update failed: task not found")
-            return fmt.Errorf("task not found")
+            return ErrTaskNotFound
         }
         logger.Log.WithField("taskID", taskID).Error("Database error when checking task existence")
         return err
     }
 
+    // An assignee may move the task along, everything else is up to its managers
+    if err := uc.authorize(existingTask, userID, model.OnlyStatus(*input)); err != nil {
+        return err
+    }
+
+    if input.Scope == model.ScopeSeries && existingTask.Series == nil && input.Recurrence == nil {
+        return ErrNotRecurring
+    }
+    if err := checkPoints(input.EstimatePoints); err != nil {
+        return err
+    }
+
+    // Statuses come from the workflow of the project the task ends up in. Moved to
+    // another project it keeps its category, the status is checked from there.
+    projectID := existingTask.ProjectID
+    if input.ProjectID != nil {
+        projectID = input.ProjectID
+        if *input.ProjectID == 0 {
+            projectID = nil
+        }
+    }
+    wf, err := uc.workflowOf(projectID)
+    if err != nil {
+        return err
+    }
+    from := currentStatus(existingTask.Status)
+    if input.Status != nil {
+        current := wf.Place(from, existingTask.StatusCategory)
+        transition := Transition{Task: existingTask, From: current, To: *input.Status, ActorID: userID}
+        if err := uc.machine.Check(uc, wf, transition); err != nil {
+            return err
+        }
+    }
+
+    var labels []labelModel.Label
+    relabel := len(input.AddLabelIDs) > 0 || len(input.RemoveLabelIDs) > 0
+    if relabel {
+        labels, err = uc.resolveLabels(model.OwnerOf(*existingTask), relabelIDs(existingTask.Labels, input.AddLabelIDs, input.RemoveLabelIDs))
+        if err != nil {
+            return err
+        }
+    }
+
+    if input.ProjectID != nil && *input.ProjectID != 0 {
+        if err := uc.checkProject(*input.ProjectID, model.OwnerOf(*existingTask)); err != nil {
+            return err
+        }
+    }
+
+    if input.AssigneeID != nil && *input.AssigneeID != 0 {
+        if err := uc.checkAssignee(*input.AssigneeID, existingTask.WorkspaceID); err != nil {
+            return err
+        }
+    }
+
+    before := model.Snapshot(*existingTask)
+    projectBefore, workerBefore := existingTask.ProjectID, existingTask.Worker()
+    rescheduled := input.DueDate != nil && (existingTask.DueDate == nil || !existingTask.DueDate.Equal(*input.DueDate))
     model.ApplyUpdate(existingTask, *input)
-    uc.SetStatusBasedOnDueDate(existingTask)
+    uc.SetStatusBasedOnDueDate(existingTask, wf)
+    moved := existingTask.Status != from || !sameProject(projectBefore, existingTask.ProjectID)
+    if moved || existingTask.Worker() != workerBefore {
+        if err := uc.checkWip(existingTask, userID, input.OverrideWip); err != nil {
+            return err
+        }
+    }
+    if moved {
+        if err := uc.placeLast(existingTask); err != nil {
+            return err
+        }
+    }
+
+    // Series edits change what later occurrences are generated from
+    series := existingTask.Series
+    if input.Recurrence != nil || input.Scope == model.ScopeSeries {
+        if input.Recurrence != nil {
+            if series, err = uc.scheduleSeries(existingTask, *input.Recurrence); err != nil {
+                return err
+            }
+        }
+        if input.Scope == model.ScopeSeries {
+            model.ApplySeriesUpdate(series, *input)
+        }
+        if err := uc.repo.SaveSeries(series); err != nil {
+            logger.Log.WithField("taskID", existingTask.ID).Error("Failed to update task series")
+            return err
+        }
+        existingTask.SeriesID = &series.ID
+        existingTask.Series = series
+    }
 
     if err := uc.repo.Update(existingTask); err != nil {
         logger.Log.WithField("taskID", existingTask.ID).Error("Failed to update task")
         return err
     }
+    if moved {
+        if err := uc.keepRanksShort(existingTask); err != nil {
+            return err
+        }
+    }
+
+    if relabel {
+        if err := uc.repo.ReplaceLabels(existingTask, labels); err != nil {
+            logger.Log.WithField("taskID", existingTask.ID).Error("Failed to update task labels")
+            return err
+        }
+        existingTask.Labels = labels
+    }
+
+    if err := uc.record(existingTask.ID, userID, model.ActionUpdated, before.Diff(model.Snapshot(*existingTask))); err != nil {
+        return err
+    }
+
+    if rescheduled {
+        if err := uc.repo.ShiftReminders(existingTask.ID, *existingTask.DueDate); err != nil {
+            logger.Log.WithField("taskID", existingTask.ID).Error("Failed to shift reminders")
+            return err
+        }
+    }
+
+    if existingTask.Status != from {
+        transition := Transition{Task: existingTask, From: from, To: existingTask.Status, ActorID: userID}
+        if err := uc.machine.Enter(uc, wf, transition, existingTask.UpdatedAt); err != nil {
+            return err
+        }
+    }
 
     logger.Log.WithField("taskID", existingTask.ID).Info("Task updated successfully")
     return nil
 }
 
+// scheduleSeries (re)starts the series of task at its due date under rule, task
+// becoming the first occurrence. An existing series keeps its ID and template.
+func (uc *TaskusecaseImpl) scheduleSeries(task *model.Task, rule string) (*model.TaskSeries, error) {
+	if task.DueDate == nil {
+		return nil, ErrRecurrenceNeedsDueDate
+	}
+	parsed, err := recurrence.Parse(rule, *task.DueDate)
+	if err != nil {
+		logger.Log.WithField("userID", task.UserID).Warn("Invalid recurrence rule")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRecurrence, rule)
+	}
+
+	task.Occurrence = 1
+	if task.Series == nil {
+		return model.ToTaskSeries(*task, parsed.String()), nil
+	}
+	series := task.Series
+	series.RRule = parsed.String()
+	series.StartsAt, series.LastDueAt = *task.DueDate, *task.DueDate
+	series.Generated = 1
+	series.EndedAt = nil
+	return series, nil
+}
+
+// nextOccurrence generates the occurrence after task once the latest one is completed
+// by actorID, ending the series when its rule has run out
+func (uc *TaskusecaseImpl) nextOccurrence(task *model.Task, series *model.TaskSeries, actorID uint) error {
+	if series.EndedAt != nil || task.Occurrence != series.Generated {
+		return nil
+	}
+	rule, err := recurrence.Parse(series.RRule, series.StartsAt)
+	if err != nil {
+		return err
+	}
+
+	due, ok := rule.Next(series.LastDueAt, series.Generated)
+	if !ok {
+		now := time.Now()
+		series.EndedAt = &now
+		logger.Log.WithField("seriesID", series.ID).Info("Task series finished")
+		return uc.repo.SaveSeries(series)
+	}
+
+	series.Generated++
+	series.LastDueAt = due
+	next := model.ToNextOccurrence(*series, *task, due)
+	wf, err := uc.workflowOf(next.ProjectID)
+	if err != nil {
+		return err
+	}
+	next.Status = wf.Initial()
+	uc.SetStatusBasedOnDueDate(&next, wf)
+	if err := uc.placeLast(&next); err != nil {
+		return err
+	}
+	if err := uc.repo.SaveOccurrence(series, &next); err != nil {
+		return err
+	}
+	if err := uc.keepRanksShort(&next); err != nil {
+		return err
+	}
+	next.Series = series
+	if err := uc.record(next.ID, actorID, model.ActionCreated, model.TaskSnapshot{}.Diff(model.Snapshot(next))); err != nil {
+		return err
+	}
+	if err := uc.repo.EnterStatus(next.ID, next.Status, next.CreatedAt); err != nil {
+		return err
+	}
+	logger.Log.WithField("seriesID", series.ID).Info("Next occurrence generated")
+	return nil
+}
+
+// EndSeries stops a recurring task from generating further occurrences, the
+// occurrences already created stay as they are
+func (uc *TaskusecaseImpl) EndSeries(taskID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.endSeries(taskID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) endSeries(taskID, userID uint) error {
+	task, err := uc.findManaged(taskID, userID)
+	if err != nil {
+		return err
+	}
+	if task.Series == nil {
+		return ErrNotRecurring
+	}
+	if task.Series.EndedAt != nil {
+		return nil
+	}
+
+	before := model.Snapshot(*task)
+	now := time.Now()
+	task.Series.EndedAt = &now
+	if err := uc.repo.SaveSeries(task.Series); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to end task series")
+		return err
+	}
+	if err := uc.record(taskID, userID, model.ActionUpdated, before.Diff(model.Snapshot(*task))); err != nil {
+		return err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Task series ended")
+	return nil
+}
+
+
+func (uc *TaskusecaseImpl) MoveTask(taskID, userID uint, parentID *uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.moveTask(taskID, userID, parentID)
+	})
+}
+
+func (uc *TaskusecaseImpl) moveTask(taskID, userID uint, parentID *uint) error {
+	task, err := uc.repo.FindByIDAndUser(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("taskID", taskID).Warn("Move failed: task not found")
+			return ErrTaskNotFound
+		}
+		logger.Log.WithField("taskID", taskID).Error("Database error when checking task existence")
+		return err
+	}
+	if err := uc.authorize(task, userID, false); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if *parentID == taskID {
+			return ErrTaskCycle
+		}
+		if _, err := uc.findRelated(*parentID, userID, task.WorkspaceID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Log.WithField("parentID", *parentID).Warn("Move failed: parent task not found")
+				return ErrParentNotFound
+			}
+			return err
+		}
+		descendants, err := uc.repo.FindDescendants(taskID, task.UserID)
+		if err != nil {
+			logger.Log.WithField("taskID", taskID).Error("Failed to check subtasks before moving")
+			return err
+		}
+		for _, child := range *descendants {
+			if child.ID == *parentID {
+				logger.Log.WithField("taskID", taskID).Warn("Move failed: would create a cycle")
+				return ErrTaskCycle
+			}
+		}
+	}
+
+	before := model.Snapshot(*task)
+	task.ParentID = parentID
+	if err := uc.repo.Update(task); err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to move task")
+		return err
+	}
+	if err := uc.record(taskID, userID, model.ActionUpdated, before.Diff(model.Snapshot(*task))); err != nil {
+		return err
+	}
+
+	logger.Log.WithField("taskID", taskID).Info("Task moved successfully")
+	return nil
+}
+
+func (uc *TaskusecaseImpl) AddDependency(taskID, blockedByID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.addDependency(taskID, blockedByID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) addDependency(taskID, blockedByID, userID uint) error {
+	task, err := uc.findManaged(taskID, userID)
+	if err != nil {
+		return err
+	}
+	if taskID == blockedByID {
+		return ErrDependencyCycle
+	}
+	if _, err := uc.findRelated(blockedByID, userID, task.WorkspaceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("blockedByID", blockedByID).Warn("Add dependency failed: blocking task not found")
+			return ErrBlockerNotFound
+		}
+		return err
+	}
+
+	// taskID waiting on blockedByID closes a loop if blockedByID already waits on taskID
+	cycle, err := uc.repo.DependsOn(blockedByID, taskID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Add dependency failed: would create a cycle")
+		return ErrDependencyCycle
+	}
+
+	if err := uc.repo.AddDependency(taskID, blockedByID); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to add dependency")
+		return err
+	}
+	change := model.IDChange("blocked_by", nil, &blockedByID)
+	if err := uc.record(taskID, userID, model.ActionUpdated, []model.TaskFieldChange{change}); err != nil {
+		return err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Dependency added")
+	return nil
+}
+
+func (uc *TaskusecaseImpl) RemoveDependency(taskID, blockedByID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.removeDependency(taskID, blockedByID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) removeDependency(taskID, blockedByID, userID uint) error {
+	if _, err := uc.findManaged(taskID, userID); err != nil {
+		return err
+	}
+
+	if err := uc.repo.RemoveDependency(taskID, blockedByID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDependencyNotFound
+		}
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to remove dependency")
+		return err
+	}
+	change := model.IDChange("blocked_by", &blockedByID, nil)
+	if err := uc.record(taskID, userID, model.ActionUpdated, []model.TaskFieldChange{change}); err != nil {
+		return err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Dependency removed")
+	return nil
+}
+
+// resolveLabels loads the labels of owner by ID, failing if any of them is not theirs
+func (uc *TaskusecaseImpl) resolveLabels(owner model.Owner, ids []uint) ([]labelModel.Label, error) {
+	if len(ids) == 0 {
+		return []labelModel.Label{}, nil
+	}
+	wanted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	labels, err := uc.repo.FindLabels(owner, ids)
+	if err != nil {
+		logger.Log.WithField("userID", owner.UserID).Error("Failed to load labels")
+		return nil, err
+	}
+	if len(*labels) != len(wanted) {
+		logger.Log.WithField("userID", owner.UserID).Warn("Label not found for this task")
+		return nil, ErrLabelNotFound
+	}
+	return *labels, nil
+}
+
+// checkProject makes sure tasks can be filed under a project of owner
+func (uc *TaskusecaseImpl) checkProject(projectID uint, owner model.Owner) error {
+	project, err := uc.repo.FindProject(projectID, owner)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("projectID", projectID).Warn("Project not found for this task")
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	if project.ArchivedAt != nil {
+		logger.Log.WithField("projectID", projectID).Warn("Project is archived")
+		return ErrProjectArchived
+	}
+	return nil
+}
+
+// checkAssignee makes sure a task is handed to a user that exists, in a workspace
+// to one of its members who may work on tasks
+func (uc *TaskusecaseImpl) checkAssignee(assigneeID uint, workspaceID *uint) error {
+	if workspaceID != nil {
+		role, err := uc.repo.FindRole(*workspaceID, assigneeID)
+		if err != nil {
+			return err
+		}
+		if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+			logger.Log.WithField("assigneeID", assigneeID).Warn("Assignee is not a member of the workspace")
+			return ErrAssigneeNotMember
+		}
+		return nil
+	}
+
+	if _, err := uc.repo.FindUser(assigneeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("assigneeID", assigneeID).Warn("Assignee not found")
+			return ErrAssigneeNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkContributor fails unless userID may add tasks to the workspace, when there
+// is one: viewers only read
+func (uc *TaskusecaseImpl) checkContributor(workspaceID *uint, userID uint) error {
+	if workspaceID == nil {
+		return nil
+	}
+	role, err := uc.repo.FindRole(*workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+		logger.Log.WithField("userID", userID).Warn("Tasks refused: role is read-only")
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// authorize fails unless userID may change the task, or only its status when
+// statusOnly. In a workspace viewers only read, members manage the tasks they
+// created and admins every task; outside one only the creator does. The assignee
+// may always move a task through its statuses.
+func (uc *TaskusecaseImpl) authorize(task *model.Task, userID uint, statusOnly bool) error {
+	role := ""
+	if task.WorkspaceID != nil {
+		var err error
+		if role, err = uc.repo.FindRole(*task.WorkspaceID, userID); err != nil {
+			return err
+		}
+		if role == workspaceModel.RoleViewer {
+			logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Viewer tried to change a task")
+			return ErrReadOnly
+		}
+	}
+
+	switch {
+	case workspaceModel.AtLeast(role, workspaceModel.RoleAdmin):
+	case task.UserID == userID && (task.WorkspaceID == nil || role != ""):
+	case statusOnly && task.AssigneeID != nil && *task.AssigneeID == userID:
+	default:
+		logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Only the creator or an admin may change this task")
+		return ErrNotCreator
+	}
+	return nil
+}
+
+// findManaged loads a task userID may fully change
+func (uc *TaskusecaseImpl) findManaged(taskID, userID uint) (*model.Task, error) {
+	task, err := uc.GetByIDAndUser(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(task, userID, false); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// findRelated loads a task that may become the parent or blocker of a task in
+// workspaceID: it must be in the same workspace, outside workspaces it must have
+// been created by userID. Any other task counts as not found.
+func (uc *TaskusecaseImpl) findRelated(taskID, userID uint, workspaceID *uint) (*model.Task, error) {
+	task, err := uc.repo.FindByIDAndUser(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if workspaceID == nil {
+		if task.WorkspaceID != nil || task.UserID != userID {
+			return nil, gorm.ErrRecordNotFound
+		}
+	} else if task.WorkspaceID == nil || *task.WorkspaceID != *workspaceID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return task, nil
+}
+
+// relabelIDs is the label set of a task after adding and removing the given IDs
+func relabelIDs(current []labelModel.Label, add, remove []uint) []uint {
+	removed := make(map[uint]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	seen := make(map[uint]bool)
+	ids := []uint{}
+	for _, l := range current {
+		if !removed[l.ID] && !seen[l.ID] {
+			seen[l.ID] = true
+			ids = append(ids, l.ID)
+		}
+	}
+	for _, id := range add {
+		if !removed[id] && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
 
 func (uc *TaskusecaseImpl) DeleteTask(taskID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.deleteTask(taskID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) deleteTask(taskID, userID uint) error {
 	task, err := uc.repo.FindByIDAndUser(taskID, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Log.Warn("Delete failed: task not found or unauthorized")
-			return fmt.Errorf("task not found or unauthorized")
+			return ErrTaskNotFound
 		}
 		logger.Log.Error("DB error when finding task by ID and userID: ", err)
 		return err
 	}
+	if err := uc.authorize(task, userID, false); err != nil {
+		return err
+	}
+
+	if err := uc.deleteTree(task, userID); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Task deleted : ", taskID)
+	return nil
+}
+
+// ForceDelete deletes any task with its subtasks, for admins
+func (uc *TaskusecaseImpl) ForceDelete(taskID, adminID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.forceDelete(taskID, adminID)
+	})
+}
+
+func (uc *TaskusecaseImpl) forceDelete(taskID, adminID uint) error {
+	task, err := uc.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.deleteTree(task, adminID); err != nil {
+		return err
+	}
+
+	logger.Log.Warn("Task force deleted by an admin : ", taskID)
+	return nil
+}
+
+// deleteTree deletes the task on behalf of actorID, its subtasks go with their parent
+func (uc *TaskusecaseImpl) deleteTree(task *model.Task, actorID uint) error {
+	descendants, err := uc.repo.FindDescendants(task.ID, task.UserID)
+	if err != nil {
+		logger.Log.Error("DB error when finding subtasks: ", err)
+		return err
+	}
+	entries := make([]model.TaskHistory, 0, len(*descendants)+1)
+	for _, child := range *descendants {
+		if err := uc.repo.Delete(child.ID); err != nil {
+			logger.Log.Error("Delete failed : ", err)
+			return err
+		}
+		entries = append(entries, model.TaskHistory{TaskID: child.ID, ActorID: actorID, Action: model.ActionDeleted})
+	}
 
 	if err := uc.repo.Delete(task.ID); err != nil {
 		logger.Log.Error("Delete failed : ", err)
 		return err
 	}
+	entries = append(entries, model.TaskHistory{TaskID: task.ID, ActorID: actorID, Action: model.ActionDeleted})
 
-	logger.Log.Info("Task deleted : ", taskID)
+	if err := uc.repo.AddHistory(entries); err != nil {
+		logger.Log.WithField("taskID", task.ID).Error("Failed to record deletion")
+		return err
+	}
+	return nil
+}
+
+// GetTrash lists the deleted tasks the user can restore, in workspaceID when set
+func (uc *TaskusecaseImpl) GetTrash(userID uint, workspaceID *uint) (*[]model.Task, error) {
+	tasks, err := uc.repo.FindTrash(userID, workspaceID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get trash")
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Restore takes a deleted task out of the trash together with its deleted
+// subtasks. Whoever may delete the task may restore it.
+func (uc *TaskusecaseImpl) Restore(taskID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.restore(taskID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) restore(taskID, userID uint) error {
+	task, err := uc.findTrashed(taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	// A subtask can only come back under a parent that is back as well
+	if task.ParentID != nil {
+		if _, err := uc.repo.FindTrashed(*task.ParentID, userID); err == nil {
+			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Restore failed: parent is in the trash")
+			return ErrParentInTrash
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	ids, err := uc.trashedTree(taskID)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.Restore(ids); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to restore task")
+		return err
+	}
+
+	entries := make([]model.TaskHistory, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, model.TaskHistory{TaskID: id, ActorID: userID, Action: model.ActionRestored})
+	}
+	if err := uc.repo.AddHistory(entries); err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to record restore")
+		return err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Task restored")
+	return nil
+}
+
+// Purge deletes a task in the trash for good, with its subtasks and history
+func (uc *TaskusecaseImpl) Purge(taskID, userID uint) error {
+	return uc.atomically(func(tx *TaskusecaseImpl) error {
+		return tx.purge(taskID, userID)
+	})
+}
+
+func (uc *TaskusecaseImpl) purge(taskID, userID uint) error {
+	if _, err := uc.findTrashed(taskID, userID); err != nil {
+		return err
+	}
+
+	ids, err := uc.trashedTree(taskID)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.Purge(ids); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to purge task")
+		return err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task purged")
+	return nil
+}
+
+// findTrashed loads a task in the trash that userID may delete
+func (uc *TaskusecaseImpl) findTrashed(taskID, userID uint) (*model.Task, error) {
+	task, err := uc.repo.FindTrashed(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+	if err := uc.authorize(task, userID, false); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// trashedTree is the ID of a trashed task followed by those of its trashed subtasks
+func (uc *TaskusecaseImpl) trashedTree(taskID uint) ([]uint, error) {
+	descendants, err := uc.repo.FindTrashedDescendants(taskID)
+	if err != nil {
+		logger.Log.WithField("taskID", taskID).Error("Failed to find trashed subtasks")
+		return nil, err
+	}
+	ids := []uint{taskID}
+	for _, child := range *descendants {
+		ids = append(ids, child.ID)
+	}
+	return ids, nil
+}
+
+// errBulkFailed rolls an atomic bulk request back once any of its tasks failed
+var errBulkFailed = errors.New("bulk request failed")
+
+// Bulk applies one operation to many tasks in a single transaction, each task
+// under the same rules as on its own. A task that fails is rolled back to a
+// savepoint and reported, the others go through unless the request is atomic.
+// Only unexpected errors fail the request as a whole.
+func (uc *TaskusecaseImpl) Bulk(req model.BulkRequest, userID uint) (*model.BulkResult, error) {
+	result := &model.BulkResult{Results: []model.BulkItemResult{}}
+	seen := make(map[uint]bool, len(req.TaskIDs))
+
+	err := uc.repo.Transaction(func(repo TaskRepository) error {
+		failed := false
+		for _, taskID := range req.TaskIDs {
+			if seen[taskID] {
+				continue
+			}
+			seen[taskID] = true
+
+			err := repo.Transaction(func(item TaskRepository) error {
+				return (&TaskusecaseImpl{repo: item, machine: uc.machine}).applyBulk(req, taskID, userID)
+			})
+			status, err := bulkStatus(err)
+			if status == "" {
+				return err
+			}
+			item := model.BulkItemResult{TaskID: taskID, Status: status}
+			if err != nil {
+				item.Error = err.Error()
+				failed = true
+			}
+			result.Results = append(result.Results, item)
+		}
+		if failed && req.Atomic {
+			return errBulkFailed
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBulkFailed) {
+		for i := range result.Results {
+			if result.Results[i].Status == model.BulkOK {
+				result.Results[i].Status = model.BulkRolledBack
+			}
+		}
+		logger.Log.WithField("userID", userID).Warn("Atomic bulk request rolled back")
+		return result, nil
+	}
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Bulk request failed: ", err)
+		return nil, err
+	}
+
+	result.Committed = true
+	logger.Log.WithField("userID", userID).Info("Bulk request applied: ", req.Operation)
+	return result, nil
+}
+
+// applyBulk runs the operation of a bulk request on one task
+func (uc *TaskusecaseImpl) applyBulk(req model.BulkRequest, taskID, userID uint) error {
+	switch req.Operation {
+	case model.BulkSetStatus:
+		return uc.updateTask(&model.UpdateTaskInput{Status: req.Status}, taskID, userID)
+	case model.BulkSetDueDate:
+		return uc.updateTask(&model.UpdateTaskInput{DueDate: req.DueDate}, taskID, userID)
+	case model.BulkAddLabel:
+		return uc.updateTask(&model.UpdateTaskInput{AddLabelIDs: []uint{*req.LabelID}}, taskID, userID)
+	case model.BulkRemoveLabel:
+		return uc.updateTask(&model.UpdateTaskInput{RemoveLabelIDs: []uint{*req.LabelID}}, taskID, userID)
+	case model.BulkDelete:
+		return uc.deleteTask(taskID, userID)
+	case model.BulkRestore:
+		return uc.restore(taskID, userID)
+	default:
+		return fmt.Errorf("unknown bulk operation: %s", req.Operation)
+	}
+}
+
+// bulkStatus sorts the outcome of one task of a bulk request. Errors the task
+// itself is to blame for come back with their status, any other error with an
+// empty status.
+func bulkStatus(err error) (string, error) {
+	switch {
+	case err == nil:
+		return model.BulkOK, nil
+	case errors.Is(err, ErrTaskNotFound), errors.Is(err, ErrNotInTrash):
+		return model.BulkNotFound, err
+	case errors.Is(err, ErrNotCreator), errors.Is(err, ErrReadOnly):
+		return model.BulkForbidden, err
+	case errors.Is(err, ErrLabelNotFound),
+		errors.Is(err, ErrOpenSubtasks),
+		errors.Is(err, ErrTaskBlocked),
+		errors.Is(err, ErrIllegalTransition),
+		errors.Is(err, ErrUnknownStatus),
+		errors.Is(err, ErrWipLimit),
+		errors.Is(err, ErrParentInTrash):
+		return model.BulkInvalid, err
+	default:
+		return "", err
+	}
+}
+
+// atomically runs fn against the usecase working in one transaction, so a change
+// is saved together with its history and status entries or not at all
+func (uc *TaskusecaseImpl) atomically(fn func(tx *TaskusecaseImpl) error) error {
+	return uc.repo.Transaction(func(repo TaskRepository) error {
+		return fn(&TaskusecaseImpl{repo: repo, machine: uc.machine})
+	})
+}
+
+// GetHistory returns the activity timeline of a task the user can see, also once
+// it has been deleted
+func (uc *TaskusecaseImpl) GetHistory(taskID, userID uint) (*[]model.TaskHistory, error) {
+	entries, err := uc.repo.FindHistory(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to get task history")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// record appends an entry to a task's history. Updates that changed nothing are
+// left out, every other action always counts.
+func (uc *TaskusecaseImpl) record(taskID, actorID uint, action string, changes []model.TaskFieldChange) error {
+	if action == model.ActionUpdated && len(changes) == 0 {
+		return nil
+	}
+	entry := model.TaskHistory{TaskID: taskID, ActorID: actorID, Action: action, Changes: changes}
+	if err := uc.repo.AddHistory([]model.TaskHistory{entry}); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, actorID)).Error("Failed to record task history")
+		return err
+	}
 	return nil
 }
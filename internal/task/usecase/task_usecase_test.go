@@ -1,23 +1,58 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
+	labelModel "mymodule/internal/label/model"
+	projectModel "mymodule/internal/project/model"
 	"mymodule/internal/task/model"
 	"mymodule/internal/task/usecase"
+	userModel "mymodule/internal/user/model"
+	workspaceModel "mymodule/internal/workspace/model"
 	"mymodule/pkg/logger"
 	"os"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockTaskRepository struct {
 	mock.Mock
+	// history collects what AddHistory was given, so tests that do not look at it
+	// need not expect the call
+	history []model.TaskHistory
+	// untracked counts the history entries added outside of any transaction
+	untracked int
+	// depth is how many transactions are open
+	depth int
+	// entered collects the statuses EnterStatus was given, in order
+	entered []string
+	// workflows are the projects' own workflows, the others use the built-in one
+	workflows map[uint]*projectModel.Workflow
+	// lastRanks are the ranks at the bottom of board columns, others are empty
+	lastRanks map[model.ColumnKey]string
+	// ranks are the ranks of the tasks in board columns, in order
+	ranks map[model.ColumnKey][]string
+	// wipLimits are the WIP limits of every workspace and project
+	wipLimits []model.WipLimit
+	// locked collects the WIP limits locked, unlocked counts those locked outside
+	// of any transaction
+	locked   []uint
+	unlocked int
 }
 
-func (m *MockTaskRepository) Save(task model.Task) error {
+// Transaction runs fn straight against the mock, nothing is rolled back
+func (m *MockTaskRepository) Transaction(fn func(repo usecase.TaskRepository) error) error {
+	m.depth++
+	defer func() { m.depth-- }()
+	return fn(m)
+}
+
+func (m *MockTaskRepository) Save(task *model.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
@@ -27,9 +62,14 @@ func (m *MockTaskRepository) FindByID(taskID uint) (*model.Task, error) {
 	return args.Get(0).(*model.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) FindByUser(userID uint) (*[]model.Task, error) {
-	args := m.Called(userID)
-	return args.Get(0).(*[]model.Task), args.Error(1)
+func (m *MockTaskRepository) FindByUser(userID uint, filter model.TaskFilter) (*[]model.Task, int64, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).(*[]model.Task), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTaskRepository) FindAll(filter model.TaskFilter) (*[]model.Task, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]model.Task), args.Get(1).(int64), args.Error(2)
 }
 
 func (m *MockTaskRepository) FindByIDAndUser(taskID, userID uint) (*model.Task, error) {
@@ -37,20 +77,292 @@ func (m *MockTaskRepository) FindByIDAndUser(taskID, userID uint) (*model.Task,
 	return args.Get(0).(*model.Task), args.Error(1)
 }
 
+func (m *MockTaskRepository) FindChildren(taskID, userID uint) (*[]model.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindDescendants(taskID, userID uint) (*[]model.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) AddDependency(taskID, blockedByID uint) error {
+	args := m.Called(taskID, blockedByID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RemoveDependency(taskID, blockedByID uint) error {
+	args := m.Called(taskID, blockedByID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindBlockers(taskID, userID uint) (*[]model.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindBlocked(taskID, userID uint) (*[]model.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) DependsOn(taskID, blockerID uint) (bool, error) {
+	args := m.Called(taskID, blockerID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) Search(userID uint, query string, limit int) (*[]model.TaskSearchResult, error) {
+	args := m.Called(userID, query, limit)
+	return args.Get(0).(*[]model.TaskSearchResult), args.Error(1)
+}
+
+func (m *MockTaskRepository) SearchAll(query string, limit int) (*[]model.TaskSearchResult, error) {
+	args := m.Called(query, limit)
+	return args.Get(0).(*[]model.TaskSearchResult), args.Error(1)
+}
+
 func (m *MockTaskRepository) Update(task *model.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) FindLabels(owner model.Owner, labelIDs []uint) (*[]labelModel.Label, error) {
+	args := m.Called(owner, labelIDs)
+	return args.Get(0).(*[]labelModel.Label), args.Error(1)
+}
+
+func (m *MockTaskRepository) ReplaceLabels(task *model.Task, labels []labelModel.Label) error {
+	args := m.Called(task, labels)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindProject(projectID uint, owner model.Owner) (*projectModel.Project, error) {
+	args := m.Called(projectID, owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*projectModel.Project), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindUser(userID uint) (*userModel.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userModel.User), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) SaveWithSeries(series *model.TaskSeries, task *model.Task) error {
+	args := m.Called(series, task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SaveSeries(series *model.TaskSeries) error {
+	args := m.Called(series)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SaveOccurrence(series *model.TaskSeries, task *model.Task) error {
+	args := m.Called(series, task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ShiftReminders(taskID uint, dueDate time.Time) error {
+	args := m.Called(taskID, dueDate)
+	return args.Error(0)
+}
+
 func (m *MockTaskRepository) Delete(taskID uint) error {
 	args := m.Called(taskID)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) FindTrash(userID uint, workspaceID *uint) (*[]model.Task, error) {
+	args := m.Called(userID, workspaceID)
+	if tasks := args.Get(0); tasks != nil {
+		return tasks.(*[]model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) FindTrashed(taskID, userID uint) (*model.Task, error) {
+	args := m.Called(taskID, userID)
+	if task := args.Get(0); task != nil {
+		return task.(*model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) FindTrashedDescendants(taskID uint) (*[]model.Task, error) {
+	args := m.Called(taskID)
+	if tasks := args.Get(0); tasks != nil {
+		return tasks.(*[]model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) Restore(taskIDs []uint) error {
+	args := m.Called(taskIDs)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Purge(taskIDs []uint) error {
+	args := m.Called(taskIDs)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindExpiredTrash(before time.Time, limit int) ([]uint, error) {
+	args := m.Called(before, limit)
+	if ids := args.Get(0); ids != nil {
+		return ids.([]uint), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) AddHistory(entries []model.TaskHistory) error {
+	if m.depth == 0 {
+		m.untracked += len(entries)
+	}
+	m.history = append(m.history, entries...)
+	return nil
+}
+
+func (m *MockTaskRepository) FindWorkflow(projectID uint) (*projectModel.Workflow, error) {
+	if workflow, ok := m.workflows[projectID]; ok {
+		return workflow, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockTaskRepository) EnterStatus(taskID uint, status string, at time.Time) error {
+	m.entered = append(m.entered, status)
+	return nil
+}
+
+func (m *MockTaskRepository) FindHistory(taskID, userID uint) (*[]model.TaskHistory, error) {
+	args := m.Called(taskID, userID)
+	if entries := args.Get(0); entries != nil {
+		return entries.(*[]model.TaskHistory), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockTaskRepository) UpdateOverdueTasks(userID uint) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
+
+func (m *MockTaskRepository) LastRank(projectID uint, status string) (string, error) {
+	return m.lastRanks[model.ColumnKey{ProjectID: projectID, Status: status}], nil
+}
+
+func (m *MockTaskRepository) RankAfter(column model.ColumnKey, boardRank string, excludeID uint) (string, error) {
+	for _, r := range m.ranks[column] {
+		if r > boardRank {
+			return r, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *MockTaskRepository) RankBefore(column model.ColumnKey, boardRank string, excludeID uint) (string, error) {
+	ranks := m.ranks[column]
+	for i := len(ranks) - 1; i >= 0; i-- {
+		if ranks[i] < boardRank {
+			return ranks[i], nil
+		}
+	}
+	return "", nil
+}
+
+func (m *MockTaskRepository) FindBoard(projectID, userID uint) (*[]model.Task, error) {
+	args := m.Called(projectID, userID)
+	if tasks := args.Get(0); tasks != nil {
+		return tasks.(*[]model.Task), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) FindCrowdedColumns(maxLength, limit int) ([]model.ColumnKey, error) {
+	args := m.Called(maxLength, limit)
+	if columns := args.Get(0); columns != nil {
+		return columns.([]model.ColumnKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) RebalanceColumn(column model.ColumnKey) error {
+	args := m.Called(column)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindWipLimits(workspaceID, projectID *uint) ([]model.WipLimit, error) {
+	var limits []model.WipLimit
+	for _, l := range m.wipLimits {
+		if l.WorkspaceID != nil && workspaceID != nil && *l.WorkspaceID == *workspaceID ||
+			l.ProjectID != nil && projectID != nil && *l.ProjectID == *projectID {
+			limits = append(limits, l)
+		}
+	}
+	return limits, nil
+}
+
+func (m *MockTaskRepository) FindWipLimit(limitID uint) (*model.WipLimit, error) {
+	args := m.Called(limitID)
+	if limit := args.Get(0); limit != nil {
+		return limit.(*model.WipLimit), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) SaveWipLimit(limit *model.WipLimit) error {
+	args := m.Called(limit)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteWipLimit(limitID uint) error {
+	args := m.Called(limitID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) LockWipLimit(limitID uint) error {
+	if m.depth == 0 {
+		m.unlocked++
+	}
+	m.locked = append(m.locked, limitID)
+	return nil
+}
+
+func (m *MockTaskRepository) CountWip(query model.WipQuery) (int64, error) {
+	args := m.Called(query)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindCompletedEstimates(userID uint, workspaceID, projectID *uint, from, to time.Time) (*[]model.Task, error) {
+	args := m.Called(userID, workspaceID, projectID, from, to)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error) {
+	args := m.Called(taskIDs)
+	return args.Get(0).(map[uint]time.Duration), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindLabelsByName(owner model.Owner, names []string) (*[]labelModel.Label, error) {
+	args := m.Called(owner, names)
+	return args.Get(0).(*[]labelModel.Label), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindForExport(userID uint, workspaceID *uint, afterID uint, limit int) (*[]model.Task, error) {
+	args := m.Called(userID, workspaceID, afterID, limit)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
 func Testlog(m *testing.M) {
 	logger.InitLogger()
 	os.Exit(m.Run())
@@ -68,7 +380,7 @@ func TestCreateTask(t *testing.T) {
 		mockRepo := new(MockTaskRepository)
 		taskUC := usecase.NewTaskUsecase(mockRepo)
 
-		mockRepo.On("Save", mock.AnythingOfType("model.Task")).Return(nil)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(nil)
 
 		err := taskUC.Create(task)
 
@@ -80,7 +392,7 @@ func TestCreateTask(t *testing.T) {
 		mockRepo := new(MockTaskRepository)
 		taskUC := usecase.NewTaskUsecase(mockRepo)
 
-		mockRepo.On("Save", mock.AnythingOfType("model.Task")).Return(errors.New("db error"))
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(errors.New("db error"))
 		err := taskUC.Create(task)
 
 		assert.EqualError(t, err, "db error")
@@ -99,7 +411,7 @@ func TestCreateTask(t *testing.T) {
 			DueDate: &past,
 		}
 
-		mockRepo.On("Save", mock.MatchedBy(func(t model.Task) bool {
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
 			return t.Status == "overdue"
 		})).Return(nil)
 
@@ -112,6 +424,9 @@ func TestCreateTask(t *testing.T) {
 
 func TestGetByUser(t *testing.T) {
 	logger.InitLogger()
+	filter := model.TaskFilter{Status: []string{"pending"}, SortBy: "due_date", Order: "asc", Limit: 20}
+	repoFilter := filter
+	repoFilter.Limit = 21
 
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockTaskRepository)
@@ -122,12 +437,16 @@ func TestGetByUser(t *testing.T) {
 			{ID: 2, Title: "Task 2", UserID: 1},
 		}
 
-		mockRepo.On("FindByUser", uint(1)).Return(&tasks, nil)
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(nil)
+		mockRepo.On("FindByUser", uint(1), repoFilter).Return(&tasks, int64(2), nil)
 
-		result, err := taskUC.GetByUser(1)
+		result, err := taskUC.GetByUser(1, filter)
 
 		assert.NoError(t, err)
-		assert.Equal(t, &tasks, result)
+		assert.Equal(t, tasks, result.Tasks)
+		assert.Equal(t, int64(2), result.Total)
+		assert.Nil(t, result.Next)
+		assert.Nil(t, result.Prev)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -135,14 +454,89 @@ func TestGetByUser(t *testing.T) {
 		mockRepo := new(MockTaskRepository)
 		taskUC := usecase.NewTaskUsecase(mockRepo)
 
-		mockRepo.On("FindByUser", uint(1)).Return((*[]model.Task)(nil), errors.New("db error"))
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(nil)
+		mockRepo.On("FindByUser", uint(1), repoFilter).Return((*[]model.Task)(nil), int64(0), errors.New("db error"))
 
-		result, err := taskUC.GetByUser(1)
+		result, err := taskUC.GetByUser(1, filter)
 
 		assert.Nil(t, result)
 		assert.EqualError(t, err, "db error")
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("OverdueUpdateError", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(errors.New("db error"))
+
+		result, err := taskUC.GetByUser(1, filter)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "db error")
+		mockRepo.AssertNotCalled(t, "FindByUser", uint(1), repoFilter)
+	})
+}
+
+func TestGetByUser_Cursor(t *testing.T) {
+	logger.InitLogger()
+	due := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []model.Task{
+		{ID: 1, Title: "a", UserID: 1, DueDate: &due},
+		{ID: 2, Title: "b", UserID: 1},
+		{ID: 3, Title: "c", UserID: 1},
+	}
+
+	t.Run("FirstPageHasNextOnly", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(nil)
+		mockRepo.On("FindByUser", uint(1), mock.MatchedBy(func(f model.TaskFilter) bool {
+			return f.Limit == 3
+		})).Return(&tasks, int64(5), nil)
+
+		page, err := taskUC.GetByUser(1, model.TaskFilter{SortBy: "title", Order: "asc", Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 2)
+		assert.Nil(t, page.Prev)
+		assert.Equal(t, &model.TaskCursor{SortBy: "title", Order: "asc", Text: "b", ID: 2}, page.Next)
+	})
+
+	t.Run("CursorOverridesSort", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		cursor := &model.TaskCursor{SortBy: "due_date", Order: "desc", ID: 9}
+		last := tasks[2:]
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(nil)
+		mockRepo.On("FindByUser", uint(1), mock.MatchedBy(func(f model.TaskFilter) bool {
+			return f.SortBy == "due_date" && f.Order == "desc" && f.Cursor == cursor
+		})).Return(&last, int64(5), nil)
+
+		page, err := taskUC.GetByUser(1, model.TaskFilter{SortBy: "title", Order: "asc", Limit: 2, Cursor: cursor})
+
+		assert.NoError(t, err)
+		assert.Nil(t, page.Next)
+		assert.Equal(t, &model.TaskCursor{SortBy: "due_date", Order: "desc", ID: 3, Backward: true}, page.Prev)
+	})
+
+	t.Run("BackwardPageDropsFirstRow", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		cursor := &model.TaskCursor{SortBy: "title", Order: "asc", Text: "d", ID: 4, Backward: true}
+		mockRepo.On("UpdateOverdueTasks", uint(1)).Return(nil)
+		mockRepo.On("FindByUser", uint(1), mock.Anything).Return(&tasks, int64(5), nil)
+
+		page, err := taskUC.GetByUser(1, model.TaskFilter{Limit: 2, Cursor: cursor})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tasks[1:], page.Tasks)
+		assert.Equal(t, &model.TaskCursor{SortBy: "title", Order: "asc", Text: "b", ID: 2, Backward: true}, page.Prev)
+		assert.Equal(t, &model.TaskCursor{SortBy: "title", Order: "asc", Text: "c", ID: 3}, page.Next)
+	})
 }
 
 func TestGetByIDAndUser(t *testing.T) {
@@ -181,6 +575,31 @@ func TestGetByIDAndUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+func TestSearch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		results := []model.TaskSearchResult{{ID: 1, Title: "Write blog post", TitleHighlight: "Write <mark>blog</mark> post"}}
+		mockRepo.On("Search", uint(1), "blog", 20).Return(&results, nil)
+
+		found, err := taskUC.Search(1, "  blog ", 20)
+		assert.NoError(t, err)
+		assert.Equal(t, &results, found)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("EmptyQuery", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		found, err := taskUC.Search(1, "   ", 20)
+		assert.Nil(t, found)
+		assert.EqualError(t, err, "search query is required")
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestUpdateTask(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockTaskRepository)
@@ -206,7 +625,10 @@ func TestUpdateTask(t *testing.T) {
 		}
 		
 		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existingTask, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{{ID: 5, Status: "completed", StatusCategory: model.CategoryDone}}, nil)
 		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+		mockRepo.On("ShiftReminders", taskID, dueDate).Return(nil)
 		
 		
 		err := taskUC.UpdateTask(input,taskID, userID) 
@@ -262,6 +684,9 @@ func TestDeleteTask(t *testing.T) {
 			UserID: userID,
 		}
 		mockRepo.On("FindByIDAndUser", taskID, userID).Return(expectedTask, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2}, {ID: 3}}, nil)
+		mockRepo.On("Delete", uint(2)).Return(nil)
+		mockRepo.On("Delete", uint(3)).Return(nil)
 		mockRepo.On("Delete", taskID).Return(nil)
 
 		err := taskUC.DeleteTask(taskID, userID)
@@ -283,3 +708,2216 @@ func TestDeleteTask(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestSubtasks(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+
+	t.Run("CreateWithMissingParent", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		parentID := uint(7)
+		mockRepo.On("FindByIDAndUser", parentID, userID).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+		err := taskUC.Create(model.Task{Title: "Child", UserID: userID, ParentID: &parentID})
+		assert.ErrorIs(t, err, usecase.ErrParentNotFound)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("DetailProgress", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{
+			{ID: 2, Status: "completed", StatusCategory: model.CategoryDone},
+			{ID: 3, Status: "pending"},
+			{ID: 4, Status: "completed", StatusCategory: model.CategoryDone},
+		}, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlocked", taskID, userID).Return(&[]model.Task{}, nil)
+
+		detail, err := taskUC.GetDetail(taskID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 66.67, *detail.Progress)
+	})
+
+	t.Run("DetailWithoutSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlocked", taskID, userID).Return(&[]model.Task{}, nil)
+
+		detail, err := taskUC.GetDetail(taskID, userID)
+		assert.NoError(t, err)
+		assert.Nil(t, detail.Progress)
+	})
+
+	t.Run("CompleteWithOpenSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		status := "completed"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: "pending"}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2, Status: "completed", StatusCategory: model.CategoryDone}, {ID: 3, Status: "in_progress"}}, nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &status}, taskID, userID)
+		assert.ErrorIs(t, err, usecase.ErrOpenSubtasks)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("MoveUnderDescendant", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		grandchild := uint(3)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndUser", grandchild, userID).Return(&model.Task{ID: grandchild, UserID: userID}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2}, {ID: grandchild}}, nil)
+
+		err := taskUC.MoveTask(taskID, userID, &grandchild)
+		assert.ErrorIs(t, err, usecase.ErrTaskCycle)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("MoveUnderItself", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+
+		err := taskUC.MoveTask(taskID, userID, &taskID)
+		assert.ErrorIs(t, err, usecase.ErrTaskCycle)
+	})
+
+	t.Run("MoveToTopLevel", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		parent := uint(9)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, ParentID: &parent}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(t *model.Task) bool {
+			return t.ParentID == nil
+		})).Return(nil)
+
+		err := taskUC.MoveTask(taskID, userID, nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	taskID := uint(1)
+	blockerID := uint(2)
+	userID := uint(100)
+
+	t.Run("Add", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndUser", blockerID, userID).Return(&model.Task{ID: blockerID, UserID: userID}, nil)
+		mockRepo.On("DependsOn", blockerID, taskID).Return(false, nil)
+		mockRepo.On("AddDependency", taskID, blockerID).Return(nil)
+
+		err := taskUC.AddDependency(taskID, blockerID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AddCycle", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndUser", blockerID, userID).Return(&model.Task{ID: blockerID, UserID: userID}, nil)
+		mockRepo.On("DependsOn", blockerID, taskID).Return(true, nil)
+
+		err := taskUC.AddDependency(taskID, blockerID, userID)
+		assert.ErrorIs(t, err, usecase.ErrDependencyCycle)
+		mockRepo.AssertNotCalled(t, "AddDependency", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddSelf", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+
+		err := taskUC.AddDependency(taskID, taskID, userID)
+		assert.ErrorIs(t, err, usecase.ErrDependencyCycle)
+	})
+
+	t.Run("AddForeignBlocker", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("FindByIDAndUser", blockerID, userID).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+		err := taskUC.AddDependency(taskID, blockerID, userID)
+		assert.ErrorIs(t, err, usecase.ErrBlockerNotFound)
+	})
+
+	t.Run("RemoveMissing", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+		mockRepo.On("RemoveDependency", taskID, blockerID).Return(gorm.ErrRecordNotFound)
+
+		err := taskUC.RemoveDependency(taskID, blockerID, userID)
+		assert.ErrorIs(t, err, usecase.ErrDependencyNotFound)
+	})
+
+	for _, status := range []string{"in_progress", "completed"} {
+		t.Run("BlockedFrom_"+status, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			taskUC := usecase.NewTaskUsecase(mockRepo)
+
+			next := status
+			mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: "pending"}, nil)
+			mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{}, nil).Maybe()
+			mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{{ID: blockerID, Status: "in_progress"}}, nil)
+
+			err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+			assert.ErrorIs(t, err, usecase.ErrTaskBlocked)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+
+	t.Run("UnblockedCanStart", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		next := "in_progress"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: "pending"}, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{{ID: blockerID, Status: "completed", StatusCategory: model.CategoryDone}}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestLabels(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	work := labelModel.Label{ID: 10, Name: "work", UserID: userID}
+	home := labelModel.Label{ID: 11, Name: "home", UserID: userID}
+
+	t.Run("CreateWithLabels", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindLabels", model.Owner{UserID: userID}, []uint{10, 11}).Return(&[]labelModel.Label{work, home}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
+			return len(t.Labels) == 2 && t.Labels[0].Name == "work"
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Labelled", LabelIDs: []uint{10, 11}}, userID))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreateWithForeignLabel", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindLabels", model.Owner{UserID: userID}, []uint{10, 99}).Return(&[]labelModel.Label{work}, nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Labelled", LabelIDs: []uint{10, 99}}, userID))
+		assert.ErrorIs(t, err, usecase.ErrLabelNotFound)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("UpdateAddAndRemove", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		existing := &model.Task{ID: taskID, UserID: userID, Labels: []labelModel.Label{work}}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("FindLabels", model.Owner{UserID: userID}, []uint{11}).Return(&[]labelModel.Label{home}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+		mockRepo.On("ReplaceLabels", existing, []labelModel.Label{home}).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{AddLabelIDs: []uint{11}, RemoveLabelIDs: []uint{10}}, taskID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UpdateRemoveAll", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		existing := &model.Task{ID: taskID, UserID: userID, Labels: []labelModel.Label{work}}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+		mockRepo.On("ReplaceLabels", existing, []labelModel.Label{}).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{RemoveLabelIDs: []uint{10}}, taskID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "FindLabels", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjects(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	projectID := uint(7)
+
+	t.Run("CreateInProject", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
+			return t.ProjectID != nil && *t.ProjectID == projectID
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Filed", ProjectID: &projectID}, userID))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreateInForeignProject", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(nil, gorm.ErrRecordNotFound)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Filed", ProjectID: &projectID}, userID))
+		assert.ErrorIs(t, err, usecase.ErrProjectNotFound)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("CreateInArchivedProject", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		archivedAt := time.Now()
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID, ArchivedAt: &archivedAt}, nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Filed", ProjectID: &projectID}, userID))
+		assert.ErrorIs(t, err, usecase.ErrProjectArchived)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("UpdateClearsProject", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		none := uint(0)
+		existing := &model.Task{ID: taskID, UserID: userID, ProjectID: &projectID}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(t *model.Task) bool { return t.ProjectID == nil })).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{ProjectID: &none}, taskID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "FindProject", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPriority(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+
+	t.Run("CreateDefaultsToNone", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool { return t.Priority == model.PriorityNone })).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Plain"}, userID))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UpdatePriority", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		urgent := model.PriorityUrgent
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Priority: model.PriorityLow}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(t *model.Task) bool { return t.Priority == model.PriorityUrgent })).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Priority: &urgent}, taskID, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRecurrence(t *testing.T) {
+	taskID := uint(1)
+	userID := uint(100)
+	seriesID := uint(5)
+	// Monday 9:00
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	t.Run("CreateStartsSeries", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("SaveWithSeries", mock.MatchedBy(func(s *model.TaskSeries) bool {
+			return s.RRule == "FREQ=WEEKLY;BYDAY=MO" && s.StartsAt.Equal(start) && s.Generated == 1 && s.UserID == userID
+		}), mock.MatchedBy(func(t *model.Task) bool {
+			return t.Series == nil && t.Occurrence == 1
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Bins", DueDate: &start, Recurrence: "rrule:freq=weekly;byday=MO"}, userID))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreateRejectsBadRule", func(t *testing.T) {
+		for _, rule := range []string{"FREQ=SOMETIMES", "BYDAY=MO", "FREQ=HOURLY", "FREQ=DAILY;BYDAY=XX"} {
+			mockRepo := new(MockTaskRepository)
+			taskUC := usecase.NewTaskUsecase(mockRepo)
+
+			err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Bins", DueDate: &start, Recurrence: rule}, userID))
+			assert.ErrorIs(t, err, usecase.ErrInvalidRecurrence, rule)
+		}
+	})
+
+	t.Run("CreateNeedsDueDate", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Bins", Recurrence: "FREQ=DAILY"}, userID))
+		assert.ErrorIs(t, err, usecase.ErrRecurrenceNeedsDueDate)
+		mockRepo.AssertNotCalled(t, "SaveWithSeries", mock.Anything, mock.Anything)
+	})
+
+	// Completing the latest occurrence of each rule should schedule the next one at want
+	cases := []struct {
+		name      string
+		rule      string
+		last      time.Time
+		generated int
+		want      *time.Time
+	}{
+		{"Daily", "FREQ=DAILY", start, 1, ptrTime(start.AddDate(0, 0, 1))},
+		{"Interval", "FREQ=WEEKLY;INTERVAL=2", start, 1, ptrTime(start.AddDate(0, 0, 14))},
+		{"ByDay", "FREQ=WEEKLY;BYDAY=MO,TH", start, 1, ptrTime(start.AddDate(0, 0, 3))},
+		{"ByMonthDay", "FREQ=MONTHLY;BYMONTHDAY=31", start, 1, ptrTime(time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC))},
+		{"ByMonthDaySkipsShortMonths", "FREQ=MONTHLY;BYMONTHDAY=31", time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC), 2, ptrTime(time.Date(2030, 3, 31, 9, 0, 0, 0, time.UTC))},
+		{"CountLeft", "FREQ=DAILY;COUNT=3", start.AddDate(0, 0, 1), 2, ptrTime(start.AddDate(0, 0, 2))},
+		{"CountReached", "FREQ=DAILY;COUNT=3", start.AddDate(0, 0, 2), 3, nil},
+		{"UntilReached", "FREQ=WEEKLY;UNTIL=20300115T000000Z", start.AddDate(0, 0, 7), 2, nil},
+	}
+	for _, tc := range cases {
+		t.Run("Complete"+tc.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			taskUC := usecase.NewTaskUsecase(mockRepo)
+
+			series := &model.TaskSeries{ID: seriesID, UserID: userID, RRule: tc.rule, StartsAt: start, LastDueAt: tc.last,
+				Generated: tc.generated, Title: "Template", Priority: model.PriorityHigh}
+			due := tc.last
+			existing := &model.Task{ID: taskID, UserID: userID, Title: "Edited once", DueDate: &due, Status: "pending",
+				SeriesID: &seriesID, Occurrence: tc.generated, Series: series}
+			done := "completed"
+			mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+			mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{}, nil)
+			mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+			mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+			if tc.want != nil {
+				mockRepo.On("SaveOccurrence", series, mock.MatchedBy(func(next *model.Task) bool {
+					return next.DueDate.Equal(*tc.want) && next.Title == "Template" && next.Priority == model.PriorityHigh &&
+						next.Occurrence == tc.generated+1 && *next.SeriesID == seriesID && next.Status == "pending"
+				})).Return(nil)
+			} else {
+				mockRepo.On("SaveSeries", mock.MatchedBy(func(s *model.TaskSeries) bool { return s.EndedAt != nil })).Return(nil)
+			}
+
+			err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &done}, taskID, userID)
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+			if tc.want != nil {
+				assert.Equal(t, tc.generated+1, series.Generated)
+				assert.True(t, series.LastDueAt.Equal(*tc.want))
+			}
+		})
+	}
+
+	t.Run("CompletingOlderOccurrenceGeneratesNothing", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		series := &model.TaskSeries{ID: seriesID, RRule: "FREQ=DAILY", StartsAt: start, LastDueAt: start.AddDate(0, 0, 1), Generated: 2}
+		existing := &model.Task{ID: taskID, UserID: userID, DueDate: &start, Status: "pending", SeriesID: &seriesID, Occurrence: 1, Series: series}
+		done := "completed"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		assert.NoError(t, taskUC.UpdateTask(&model.UpdateTaskInput{Status: &done}, taskID, userID))
+		mockRepo.AssertNotCalled(t, "SaveOccurrence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EditSeries", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		series := &model.TaskSeries{ID: seriesID, RRule: "FREQ=DAILY", StartsAt: start, LastDueAt: start, Generated: 1, Title: "Old"}
+		existing := &model.Task{ID: taskID, UserID: userID, Title: "Old", DueDate: &start, SeriesID: &seriesID, Occurrence: 1, Series: series}
+		title := "New"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("SaveSeries", mock.MatchedBy(func(s *model.TaskSeries) bool { return s.Title == "New" })).Return(nil)
+		mockRepo.On("Update", mock.MatchedBy(func(t *model.Task) bool { return t.Title == "New" })).Return(nil)
+
+		assert.NoError(t, taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title, Scope: model.ScopeSeries}, taskID, userID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("EditOneOccurrence", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		series := &model.TaskSeries{ID: seriesID, RRule: "FREQ=DAILY", StartsAt: start, LastDueAt: start, Generated: 1, Title: "Old"}
+		existing := &model.Task{ID: taskID, UserID: userID, Title: "Old", DueDate: &start, SeriesID: &seriesID, Occurrence: 1, Series: series}
+		title := "Just this once"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		assert.NoError(t, taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, userID))
+		assert.Equal(t, "Old", series.Title)
+		mockRepo.AssertNotCalled(t, "SaveSeries", mock.Anything)
+	})
+
+	t.Run("ChangeRuleRestartsSeries", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		due := start.AddDate(0, 0, 14)
+		series := &model.TaskSeries{ID: seriesID, RRule: "FREQ=DAILY;COUNT=30", StartsAt: start, LastDueAt: due, Generated: 15}
+		existing := &model.Task{ID: taskID, UserID: userID, DueDate: &due, SeriesID: &seriesID, Occurrence: 15, Series: series}
+		rule := "FREQ=MONTHLY;BYMONTHDAY=1"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(existing, nil)
+		mockRepo.On("SaveSeries", mock.MatchedBy(func(s *model.TaskSeries) bool {
+			return s.ID == seriesID && s.RRule == rule && s.StartsAt.Equal(due) && s.Generated == 1
+		})).Return(nil)
+		mockRepo.On("Update", mock.MatchedBy(func(t *model.Task) bool { return t.Occurrence == 1 })).Return(nil)
+
+		assert.NoError(t, taskUC.UpdateTask(&model.UpdateTaskInput{Recurrence: &rule}, taskID, userID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SeriesScopeOnPlainTask", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		title := "New"
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID}, nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title, Scope: model.ScopeSeries}, taskID, userID)
+		assert.ErrorIs(t, err, usecase.ErrNotRecurring)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("EndSeries", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		series := &model.TaskSeries{ID: seriesID, RRule: "FREQ=DAILY", StartsAt: start, LastDueAt: start, Generated: 1}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, SeriesID: &seriesID, Series: series}, nil)
+		mockRepo.On("SaveSeries", series).Return(nil)
+
+		assert.NoError(t, taskUC.EndSeries(taskID, userID))
+		assert.NotNil(t, series.EndedAt)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestAssignees(t *testing.T) {
+	creatorID := uint(100)
+	assigneeID := uint(200)
+	taskID := uint(1)
+	assigned := func() *model.Task {
+		return &model.Task{ID: taskID, UserID: creatorID, AssigneeID: &assigneeID, Status: "pending"}
+	}
+
+	t.Run("CreateAssigned", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindUser", assigneeID).Return(&userModel.User{ID: assigneeID}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(task *model.Task) bool {
+			return task.UserID == creatorID && task.AssigneeID != nil && *task.AssigneeID == assigneeID
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Handed over", AssigneeID: &assigneeID}, creatorID))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnknownAssignee", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindUser", uint(999)).Return(nil, gorm.ErrRecordNotFound)
+
+		unknown := uint(999)
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Nobody", AssigneeID: &unknown}, creatorID))
+		assert.ErrorIs(t, err, usecase.ErrAssigneeNotFound)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("AssigneeUpdatesStatus", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		status := "in_progress"
+		mockRepo.On("FindByIDAndUser", taskID, assigneeID).Return(assigned(), nil)
+		// Blockers are looked up among the creator's tasks
+		mockRepo.On("FindBlockers", taskID, creatorID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(task *model.Task) bool {
+			return task.Status == "in_progress"
+		})).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &status}, taskID, assigneeID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AssigneeCannotEdit", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		title := "Renamed"
+		other := uint(300)
+		mockRepo.On("FindByIDAndUser", taskID, assigneeID).Return(assigned(), nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, assigneeID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		err = taskUC.UpdateTask(&model.UpdateTaskInput{AssigneeID: &other}, taskID, assigneeID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("AssigneeCannotDelete", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, assigneeID).Return(assigned(), nil)
+
+		err := taskUC.DeleteTask(taskID, assigneeID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("CreatorReassigns", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		other := uint(300)
+		mockRepo.On("FindByIDAndUser", taskID, creatorID).Return(assigned(), nil)
+		mockRepo.On("FindUser", other).Return(&userModel.User{ID: other}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(task *model.Task) bool {
+			return task.AssigneeID != nil && *task.AssigneeID == other
+		})).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{AssigneeID: &other}, taskID, creatorID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreatorUnassigns", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		none := uint(0)
+		mockRepo.On("FindByIDAndUser", taskID, creatorID).Return(assigned(), nil)
+		mockRepo.On("Update", mock.MatchedBy(func(task *model.Task) bool {
+			return task.AssigneeID == nil
+		})).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{AssigneeID: &none}, taskID, creatorID)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "FindUser", mock.Anything)
+	})
+}
+
+func TestWorkspaceRoles(t *testing.T) {
+	logger.InitLogger()
+	workspaceID := uint(7)
+	creatorID := uint(100)
+	memberID := uint(200)
+	taskID := uint(1)
+	shared := func() *model.Task {
+		return &model.Task{ID: taskID, UserID: creatorID, WorkspaceID: &workspaceID, Status: "pending"}
+	}
+	title := "Renamed"
+
+	t.Run("ViewerCannotCreate", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleViewer, nil)
+
+		task := model.ToTask(model.CreateTaskRequest{Title: "Not allowed"}, memberID)
+		task.WorkspaceID = &workspaceID
+		err := taskUC.Create(task)
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("ViewerCannotUpdate", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		status := "in_progress"
+		viewed := shared()
+		viewed.AssigneeID = &memberID
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(viewed, nil)
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleViewer, nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &status}, taskID, memberID)
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("MemberCannotEditOthersTask", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(shared(), nil)
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleMember, nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, memberID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		err = taskUC.DeleteTask(taskID, memberID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("AdminEditsAnyTask", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(shared(), nil)
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleAdmin, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(task *model.Task) bool {
+			return task.Title == title
+		})).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, memberID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminUsesWorkspaceLabelsAndProjects", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		projectID := uint(9)
+		owner := model.Owner{WorkspaceID: &workspaceID, UserID: creatorID}
+		task := shared()
+
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(task, nil)
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleAdmin, nil)
+		mockRepo.On("FindLabels", owner, []uint{5}).Return(&[]labelModel.Label{{ID: 5, UserID: creatorID}}, nil)
+		mockRepo.On("FindProject", projectID, owner).Return(&projectModel.Project{ID: projectID, UserID: creatorID}, nil)
+		mockRepo.On("Update", task).Return(nil)
+		mockRepo.On("ReplaceLabels", task, []labelModel.Label{{ID: 5, UserID: creatorID}}).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{AddLabelIDs: []uint{5}, ProjectID: &projectID}, taskID, memberID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminDeletesWithCreatorsSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(shared(), nil)
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleOwner, nil)
+		mockRepo.On("FindDescendants", taskID, creatorID).Return(&[]model.Task{{ID: 2}}, nil)
+		mockRepo.On("Delete", uint(2)).Return(nil)
+		mockRepo.On("Delete", taskID).Return(nil)
+
+		err := taskUC.DeleteTask(taskID, memberID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreatorWhoLeftCannotEdit", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		// Still sees the task as its assignee, but is no longer a member
+		left := shared()
+		left.AssigneeID = &creatorID
+		mockRepo.On("FindByIDAndUser", taskID, creatorID).Return(left, nil)
+		mockRepo.On("FindRole", workspaceID, creatorID).Return("", nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, creatorID)
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+	})
+
+	t.Run("AssigneeMustBeMember", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		outsider := uint(300)
+		mockRepo.On("FindRole", workspaceID, creatorID).Return(workspaceModel.RoleMember, nil)
+		mockRepo.On("FindRole", workspaceID, outsider).Return("", nil)
+
+		task := model.ToTask(model.CreateTaskRequest{Title: "Handed over", AssigneeID: &outsider}, creatorID)
+		task.WorkspaceID = &workspaceID
+		err := taskUC.Create(task)
+		assert.ErrorIs(t, err, usecase.ErrAssigneeNotMember)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("ParentFromOtherWorkspace", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		otherWorkspace := uint(8)
+		parentID := uint(5)
+		mockRepo.On("FindRole", workspaceID, creatorID).Return(workspaceModel.RoleMember, nil)
+		mockRepo.On("FindByIDAndUser", parentID, creatorID).Return(&model.Task{ID: parentID, UserID: creatorID, WorkspaceID: &otherWorkspace}, nil)
+
+		task := model.ToTask(model.CreateTaskRequest{Title: "Subtask", ParentID: &parentID}, creatorID)
+		task.WorkspaceID = &workspaceID
+		err := taskUC.Create(task)
+		assert.ErrorIs(t, err, usecase.ErrParentNotFound)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestAdmin(t *testing.T) {
+	logger.InitLogger()
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByID", uint(9)).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+		_, err := taskUC.GetByID(9)
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+	})
+
+	t.Run("GetAllPages", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		owner := uint(3)
+		mockRepo.On("FindAll", mock.MatchedBy(func(f model.TaskFilter) bool {
+			// One extra row tells whether another page follows
+			return f.Limit == 3 && f.UserID != nil && *f.UserID == owner
+		})).Return(&[]model.Task{{ID: 1}, {ID: 2}, {ID: 3}}, int64(5), nil)
+
+		page, err := taskUC.GetAll(model.TaskFilter{Limit: 2, UserID: &owner, SortBy: "created_at", Order: "desc"})
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 2)
+		assert.Equal(t, int64(5), page.Total)
+		assert.NotNil(t, page.Next)
+		mockRepo.AssertNotCalled(t, "UpdateOverdueTasks", mock.Anything)
+	})
+
+	t.Run("ForceDeleteWithSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		mockRepo.On("FindByID", uint(1)).Return(&model.Task{ID: 1, UserID: 42}, nil)
+		mockRepo.On("FindDescendants", uint(1), uint(42)).Return(&[]model.Task{{ID: 2}}, nil)
+		mockRepo.On("Delete", uint(2)).Return(nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+
+		err := taskUC.ForceDelete(1, 7)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		// Both deletions are put on the admin's account
+		if assert.Len(t, mockRepo.history, 2) {
+			assert.Equal(t, uint(7), mockRepo.history[1].ActorID)
+			assert.Equal(t, model.ActionDeleted, mockRepo.history[1].Action)
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	logger.InitLogger()
+	creator, assignee := uint(1), uint(2)
+
+	t.Run("CreateRecordsInitialValues", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Task).ID = 9
+		}).Return(nil)
+
+		err := taskUC.Create(model.Task{Title: "Audited", UserID: creator, Priority: model.PriorityHigh})
+
+		assert.NoError(t, err)
+		if assert.Len(t, mockRepo.history, 1) {
+			entry := mockRepo.history[0]
+			assert.Equal(t, uint(9), entry.TaskID)
+			assert.Equal(t, creator, entry.ActorID)
+			assert.Equal(t, model.ActionCreated, entry.Action)
+			fields := map[string]string{}
+			for _, c := range entry.Changes {
+				assert.Nil(t, c.OldValue)
+				fields[c.Field] = *c.NewValue
+			}
+			assert.Equal(t, map[string]string{"title": "Audited", "status": "pending", "priority": "high"}, fields)
+		}
+	})
+
+	t.Run("RecordedInTheTransactionOfTheChange", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		existing := &model.Task{ID: 9, Title: "Audited", Status: "pending", UserID: creator}
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(nil)
+		mockRepo.On("FindByIDAndUser", uint(9), creator).Return(existing, nil)
+		mockRepo.On("Update", existing).Return(nil)
+		mockRepo.On("FindDescendants", uint(9), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("Delete", uint(9)).Return(nil)
+
+		title := "Renamed"
+		assert.NoError(t, taskUC.Create(model.Task{Title: "Audited", UserID: creator}))
+		assert.NoError(t, taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, 9, creator))
+		assert.NoError(t, taskUC.MoveTask(9, creator, nil))
+		assert.NoError(t, taskUC.DeleteTask(9, creator))
+
+		assert.Len(t, mockRepo.history, 3)
+		assert.Zero(t, mockRepo.untracked)
+	})
+
+	t.Run("UpdateRecordsChangedFieldsOnly", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		existing := &model.Task{ID: 9, Title: "Audited", Status: "pending", UserID: creator, AssigneeID: &assignee}
+		mockRepo.On("FindByIDAndUser", uint(9), assignee).Return(existing, nil)
+		mockRepo.On("FindBlockers", uint(9), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", existing).Return(nil)
+
+		status := "in_progress"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &status}, 9, assignee)
+
+		assert.NoError(t, err)
+		if assert.Len(t, mockRepo.history, 1) {
+			entry := mockRepo.history[0]
+			assert.Equal(t, assignee, entry.ActorID)
+			assert.Equal(t, model.ActionUpdated, entry.Action)
+			if assert.Len(t, entry.Changes, 1) {
+				assert.Equal(t, "status", entry.Changes[0].Field)
+				assert.Equal(t, "pending", *entry.Changes[0].OldValue)
+				assert.Equal(t, "in_progress", *entry.Changes[0].NewValue)
+			}
+		}
+	})
+
+	t.Run("UnchangedUpdateNotRecorded", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		existing := &model.Task{ID: 9, Title: "Audited", Status: "pending", UserID: creator}
+		mockRepo.On("FindByIDAndUser", uint(9), creator).Return(existing, nil)
+		mockRepo.On("Update", existing).Return(nil)
+
+		title := "Audited"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, 9, creator)
+
+		assert.NoError(t, err)
+		assert.Empty(t, mockRepo.history)
+	})
+
+	t.Run("DependencyRecorded", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(9), creator).Return(&model.Task{ID: 9, UserID: creator}, nil)
+		mockRepo.On("RemoveDependency", uint(9), uint(4)).Return(nil)
+
+		err := taskUC.RemoveDependency(9, 4, creator)
+
+		assert.NoError(t, err)
+		if assert.Len(t, mockRepo.history, 1) && assert.Len(t, mockRepo.history[0].Changes, 1) {
+			change := mockRepo.history[0].Changes[0]
+			assert.Equal(t, "blocked_by", change.Field)
+			assert.Equal(t, "4", *change.OldValue)
+			assert.Nil(t, change.NewValue)
+		}
+	})
+
+	t.Run("DeleteRecordsSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(9), creator).Return(&model.Task{ID: 9, UserID: creator}, nil)
+		mockRepo.On("FindDescendants", uint(9), creator).Return(&[]model.Task{{ID: 10}}, nil)
+		mockRepo.On("Delete", uint(10)).Return(nil)
+		mockRepo.On("Delete", uint(9)).Return(nil)
+
+		err := taskUC.DeleteTask(9, creator)
+
+		assert.NoError(t, err)
+		if assert.Len(t, mockRepo.history, 2) {
+			assert.Equal(t, uint(10), mockRepo.history[0].TaskID)
+			assert.Equal(t, uint(9), mockRepo.history[1].TaskID)
+			assert.Equal(t, model.ActionDeleted, mockRepo.history[1].Action)
+		}
+	})
+
+	t.Run("GetHistoryNotFound", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindHistory", uint(9), assignee).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := taskUC.GetHistory(9, assignee)
+
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+	})
+}
+
+func TestTrash(t *testing.T) {
+	logger.InitLogger()
+	creator, other := uint(1), uint(2)
+	workspaceID := uint(3)
+
+	t.Run("RestoreWithSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindTrashed", uint(9), creator).Return(&model.Task{ID: 9, UserID: creator}, nil)
+		mockRepo.On("FindTrashedDescendants", uint(9)).Return(&[]model.Task{{ID: 10}, {ID: 11}}, nil)
+		mockRepo.On("Restore", []uint{9, 10, 11}).Return(nil)
+
+		err := taskUC.Restore(9, creator)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		if assert.Len(t, mockRepo.history, 3) {
+			assert.Equal(t, model.ActionRestored, mockRepo.history[0].Action)
+			assert.Equal(t, creator, mockRepo.history[0].ActorID)
+		}
+	})
+
+	t.Run("RestoreNotInTrash", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindTrashed", uint(9), creator).Return(nil, gorm.ErrRecordNotFound)
+
+		err := taskUC.Restore(9, creator)
+
+		assert.ErrorIs(t, err, usecase.ErrNotInTrash)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything)
+	})
+
+	t.Run("RestoreUnderTrashedParent", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		parentID := uint(8)
+		mockRepo.On("FindTrashed", uint(9), creator).Return(&model.Task{ID: 9, UserID: creator, ParentID: &parentID}, nil)
+		mockRepo.On("FindTrashed", parentID, creator).Return(&model.Task{ID: parentID, UserID: creator}, nil)
+
+		err := taskUC.Restore(9, creator)
+
+		assert.ErrorIs(t, err, usecase.ErrParentInTrash)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything)
+	})
+
+	t.Run("RestoreRespectsOwnership", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		// A plain member may not bring back someone else's task
+		mockRepo.On("FindTrashed", uint(9), other).Return(&model.Task{ID: 9, UserID: creator, WorkspaceID: &workspaceID}, nil)
+		mockRepo.On("FindRole", workspaceID, other).Return(workspaceModel.RoleMember, nil)
+
+		err := taskUC.Restore(9, other)
+
+		assert.ErrorIs(t, err, usecase.ErrNotCreator)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything)
+	})
+
+	t.Run("PurgeWithSubtasks", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindTrashed", uint(9), other).Return(&model.Task{ID: 9, UserID: creator, WorkspaceID: &workspaceID}, nil)
+		mockRepo.On("FindRole", workspaceID, other).Return(workspaceModel.RoleAdmin, nil)
+		mockRepo.On("FindTrashedDescendants", uint(9)).Return(&[]model.Task{{ID: 10}}, nil)
+		mockRepo.On("Purge", []uint{9, 10}).Return(nil).Run(func(mock.Arguments) {
+			assert.Equal(t, 1, mockRepo.depth, "purged outside the transaction")
+		})
+
+		err := taskUC.Purge(9, other)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SweeperPurgesExpired", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		retention := 30 * 24 * time.Hour
+		mockRepo.On("FindExpiredTrash", mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= retention && time.Since(before) < retention+time.Minute
+		}), 100).Return([]uint{4, 5}, nil)
+		mockRepo.On("Purge", []uint{4, 5}).Return(nil)
+
+		purged, err := usecase.NewSweeper(mockRepo, retention, time.Hour).RunOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SweeperNothingExpired", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindExpiredTrash", mock.Anything, 100).Return([]uint{}, nil)
+
+		purged, err := usecase.NewSweeper(mockRepo, time.Hour, time.Hour).RunOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Zero(t, purged)
+		mockRepo.AssertNotCalled(t, "Purge", mock.Anything)
+	})
+}
+
+func TestBulk(t *testing.T) {
+	logger.InitLogger()
+	creator, other := uint(1), uint(2)
+	completed := "completed"
+
+	t.Run("PerTaskResults", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mine := &model.Task{ID: 1, UserID: creator, Status: "in_progress"}
+		mockRepo.On("FindByIDAndUser", uint(1), creator).Return(mine, nil)
+		mockRepo.On("FindDescendants", uint(1), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindBlockers", uint(1), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", mine).Return(nil)
+		mockRepo.On("FindByIDAndUser", uint(2), creator).Return(&model.Task{ID: 2, UserID: other}, nil)
+		mockRepo.On("FindByIDAndUser", uint(3), creator).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+		result, err := taskUC.Bulk(model.BulkRequest{
+			Operation: model.BulkSetStatus,
+			TaskIDs:   []uint{1, 2, 3, 1},
+			Status:    &completed,
+		}, creator)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Committed)
+		assert.Equal(t, []model.BulkItemResult{
+			{TaskID: 1, Status: model.BulkOK},
+			{TaskID: 2, Status: model.BulkForbidden, Error: usecase.ErrNotCreator.Error()},
+			{TaskID: 3, Status: model.BulkNotFound, Error: usecase.ErrTaskNotFound.Error()},
+		}, result.Results)
+		// The duplicate ID is applied once
+		mockRepo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("AtomicRollsBack", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(1), creator).Return(&model.Task{ID: 1, UserID: creator}, nil)
+		mockRepo.On("FindDescendants", uint(1), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+		mockRepo.On("FindByIDAndUser", uint(2), creator).Return((*model.Task)(nil), gorm.ErrRecordNotFound)
+
+		result, err := taskUC.Bulk(model.BulkRequest{Operation: model.BulkDelete, TaskIDs: []uint{1, 2}, Atomic: true}, creator)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Committed)
+		assert.Equal(t, model.BulkRolledBack, result.Results[0].Status)
+		assert.Equal(t, model.BulkNotFound, result.Results[1].Status)
+	})
+
+	t.Run("UnexpectedErrorFailsRequest", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", uint(1), creator).Return((*model.Task)(nil), errors.New("connection reset"))
+
+		result, err := taskUC.Bulk(model.BulkRequest{Operation: model.BulkDelete, TaskIDs: []uint{1}}, creator)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("AddLabel", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		labelID := uint(5)
+		task := &model.Task{ID: 1, UserID: creator}
+		mockRepo.On("FindByIDAndUser", uint(1), creator).Return(task, nil)
+		mockRepo.On("FindLabels", model.Owner{UserID: creator}, []uint{5}).Return(&[]labelModel.Label{{ID: 5}}, nil)
+		mockRepo.On("Update", task).Return(nil)
+		mockRepo.On("ReplaceLabels", task, []labelModel.Label{{ID: 5}}).Return(nil)
+
+		result, err := taskUC.Bulk(model.BulkRequest{Operation: model.BulkAddLabel, TaskIDs: []uint{1}, LabelID: &labelID}, creator)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.BulkOK, result.Results[0].Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("IllegalTransitionFailsOnlyItsTask", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		past := time.Now().Add(-24 * time.Hour)
+		started := model.StatusInProgress
+		pending := &model.Task{ID: 1, UserID: creator, Status: model.StatusPending}
+		mockRepo.On("FindByIDAndUser", uint(1), creator).Return(pending, nil)
+		mockRepo.On("FindBlockers", uint(1), creator).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", pending).Return(nil)
+		mockRepo.On("FindByIDAndUser", uint(2), creator).Return(&model.Task{ID: 2, UserID: creator, Status: model.StatusOverdue, DueDate: &past}, nil)
+
+		result, err := taskUC.Bulk(model.BulkRequest{Operation: model.BulkSetStatus, TaskIDs: []uint{1, 2}, Status: &started}, creator)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Committed)
+		assert.Equal(t, model.BulkOK, result.Results[0].Status)
+		assert.Equal(t, model.BulkInvalid, result.Results[1].Status)
+		assert.Equal(t, "a task cannot move from overdue to in_progress", result.Results[1].Error)
+	})
+}
+
+func TestStatusMachine(t *testing.T) {
+	logger.InitLogger()
+	taskID := uint(1)
+	userID := uint(100)
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+
+	t.Run("IllegalTransition", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: model.StatusOverdue, DueDate: &past}, nil)
+
+		next := model.StatusInProgress
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		var refused *usecase.TransitionError
+		assert.ErrorIs(t, err, usecase.ErrIllegalTransition)
+		if assert.True(t, errors.As(err, &refused)) {
+			assert.Equal(t, model.StatusOverdue, refused.From)
+			assert.Equal(t, model.StatusInProgress, refused.To)
+			assert.Equal(t, []string{model.StatusCompleted}, refused.Allowed)
+		}
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("OnlyTheSystemSetsOverdue", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: model.StatusPending}, nil)
+
+		next := model.StatusOverdue
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		assert.ErrorIs(t, err, usecase.ErrIllegalTransition)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("EchoedStatusIsNoOp", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, Status: model.StatusOverdue, DueDate: &past}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		title := "Renamed"
+		same := model.StatusOverdue
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title, Status: &same}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusOverdue, task.Status)
+		assert.Empty(t, mockRepo.entered)
+	})
+
+	t.Run("GuardFailureIsStructured", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: model.StatusPending}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2, Status: model.StatusPending}}, nil)
+
+		next := model.StatusCompleted
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		var refused *usecase.TransitionError
+		assert.ErrorIs(t, err, usecase.ErrOpenSubtasks)
+		if assert.True(t, errors.As(err, &refused)) {
+			assert.Equal(t, model.StatusPending, refused.From)
+			assert.Equal(t, model.StatusCompleted, refused.To)
+		}
+	})
+
+	t.Run("RecordsEnteredStatus", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, Status: model.StatusCompleted}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		reopen := model.StatusInProgress
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &reopen}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{model.StatusInProgress}, mockRepo.entered)
+	})
+
+	t.Run("RescheduledOverdueIsPending", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, Status: model.StatusOverdue, DueDate: &past}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+		mockRepo.On("ShiftReminders", taskID, future).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{DueDate: &future}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusPending, task.Status)
+		assert.Equal(t, []string{model.StatusPending}, mockRepo.entered)
+	})
+
+	t.Run("CompletedStaysCompletedPastDue", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, Status: model.StatusCompleted, DueDate: &past}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		title := "Renamed"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusCompleted, task.Status)
+	})
+
+	t.Run("CreatePastDueStartsOverdue", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
+			return t.Status == model.StatusOverdue
+		})).Return(nil)
+
+		err := taskUC.Create(model.Task{Title: "Late", UserID: userID, DueDate: &past})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{model.StatusOverdue}, mockRepo.entered)
+	})
+}
+
+func TestWorkflows(t *testing.T) {
+	logger.InitLogger()
+	taskID := uint(1)
+	userID := uint(100)
+	projectID := uint(7)
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	board := &projectModel.Workflow{ID: 1, ProjectID: projectID, Statuses: []projectModel.WorkflowStatus{
+		{Key: "backlog", Category: model.CategoryTodo, Position: 1},
+		{Key: "doing", Category: model.CategoryInProgress, Position: 2},
+		{Key: "review", Category: model.CategoryInProgress, Position: 3},
+		{Key: "done", Category: model.CategoryDone, Position: 4},
+	}}
+	newRepo := func() *MockTaskRepository {
+		return &MockTaskRepository{workflows: map[uint]*projectModel.Workflow{projectID: board}}
+	}
+
+	t.Run("CreateStartsInFirstStatus", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
+			return t.Status == "backlog" && t.StatusCategory == model.CategoryTodo
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Card", ProjectID: &projectID}, userID))
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MovesBetweenItsStatuses", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, ProjectID: &projectID, Status: "doing", StatusCategory: model.CategoryInProgress}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		next := "review"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "review", task.Status)
+		assert.Equal(t, model.CategoryInProgress, task.StatusCategory)
+		assert.Equal(t, []string{"review"}, mockRepo.entered)
+	})
+
+	t.Run("RejectsStatusOutsideWorkflow", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, ProjectID: &projectID, Status: "backlog", StatusCategory: model.CategoryTodo}, nil)
+
+		next := model.StatusCompleted
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		assert.ErrorIs(t, err, usecase.ErrUnknownStatus)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("BulkStatusUnknownToOneWorkflow", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		card := &model.Task{ID: 1, UserID: userID, ProjectID: &projectID, Status: "doing", StatusCategory: model.CategoryInProgress}
+		mockRepo.On("FindByIDAndUser", uint(1), userID).Return(card, nil)
+		mockRepo.On("FindBlockers", uint(1), userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", card).Return(nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(&model.Task{ID: 2, UserID: userID, Status: model.StatusPending}, nil)
+
+		next := "review"
+		result, err := taskUC.Bulk(model.BulkRequest{Operation: model.BulkSetStatus, TaskIDs: []uint{1, 2}, Status: &next}, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Committed)
+		assert.Equal(t, model.BulkOK, result.Results[0].Status)
+		assert.Equal(t, model.BulkInvalid, result.Results[1].Status)
+		assert.Contains(t, result.Results[1].Error, usecase.ErrUnknownStatus.Error())
+		assert.Equal(t, "review", card.Status)
+	})
+
+	t.Run("DoneCategoryIsGuarded", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, ProjectID: &projectID, Status: "review", StatusCategory: model.CategoryInProgress}, nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2, Status: "doing", StatusCategory: model.CategoryInProgress}}, nil)
+
+		next := "done"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		assert.ErrorIs(t, err, usecase.ErrOpenSubtasks)
+	})
+
+	t.Run("OverdueLeadsToDoneOnly", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, ProjectID: &projectID, Status: model.StatusOverdue, StatusCategory: model.CategoryTodo, DueDate: &past}, nil)
+
+		next := "doing"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &next}, taskID, userID)
+
+		var refused *usecase.TransitionError
+		if assert.True(t, errors.As(err, &refused)) {
+			assert.Equal(t, []string{"done"}, refused.Allowed)
+		}
+	})
+
+	t.Run("RescheduledOverdueStartsOver", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, ProjectID: &projectID, Status: model.StatusOverdue, StatusCategory: model.CategoryTodo, DueDate: &past}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+		mockRepo.On("ShiftReminders", taskID, future).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{DueDate: &future}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "backlog", task.Status)
+	})
+
+	t.Run("MovedIntoProjectKeepsCategory", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := &model.Task{ID: taskID, UserID: userID, Status: model.StatusInProgress, StatusCategory: model.CategoryInProgress}
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{ProjectID: &projectID}, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "doing", task.Status)
+		assert.Equal(t, []string{"doing"}, mockRepo.entered)
+	})
+}
+
+func TestBoard(t *testing.T) {
+	logger.InitLogger()
+	taskID := uint(1)
+	userID := uint(100)
+	projectID := uint(7)
+	otherProject := uint(8)
+	board := &projectModel.Workflow{ID: 1, ProjectID: projectID, Statuses: []projectModel.WorkflowStatus{
+		{Key: "backlog", Name: "Backlog", Category: model.CategoryTodo, Position: 1},
+		{Key: "doing", Name: "Doing", Category: model.CategoryInProgress, Position: 2},
+		{Key: "done", Name: "Done", Category: model.CategoryDone, Position: 3},
+	}}
+	newRepo := func() *MockTaskRepository {
+		return &MockTaskRepository{workflows: map[uint]*projectModel.Workflow{projectID: board}}
+	}
+	card := func(id uint, status, category, boardRank string) *model.Task {
+		return &model.Task{ID: id, UserID: userID, ProjectID: &projectID, Status: status, StatusCategory: category, BoardRank: boardRank}
+	}
+
+	t.Run("CreateGoesToBottom", func(t *testing.T) {
+		mockRepo := newRepo()
+		mockRepo.lastRanks = map[model.ColumnKey]string{{ProjectID: projectID, Status: "backlog"}: "m"}
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(t *model.Task) bool {
+			return t.BoardRank > "m"
+		})).Return(nil)
+
+		err := taskUC.Create(model.ToTask(model.CreateTaskRequest{Title: "Card", ProjectID: &projectID}, userID))
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MovesBetweenNeighbours", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := card(taskID, "backlog", model.CategoryTodo, "x")
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "backlog", model.CategoryTodo, "a"), nil)
+		mockRepo.On("FindByIDAndUser", uint(3), userID).Return(card(3, "backlog", model.CategoryTodo, "b"), nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		after, before := uint(2), uint(3)
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{AfterID: &after, BeforeID: &before})
+
+		assert.NoError(t, err)
+		assert.True(t, task.BoardRank > "a" && task.BoardRank < "b", task.BoardRank)
+		assert.Empty(t, mockRepo.entered)
+		assert.Empty(t, mockRepo.history)
+	})
+
+	t.Run("SingleNeighbourBoundedByTheNext", func(t *testing.T) {
+		backlog := model.ColumnKey{ProjectID: projectID, Status: "backlog"}
+		column := []string{"a", "b", "c"}
+		for _, c := range []struct {
+			name string
+			req  func(first, last uint) model.BoardMoveRequest
+			want int // position of the moved card among a, b and c
+		}{
+			{"AfterFirst", func(first, last uint) model.BoardMoveRequest { return model.BoardMoveRequest{AfterID: &first} }, 1},
+			{"BeforeLast", func(first, last uint) model.BoardMoveRequest { return model.BoardMoveRequest{BeforeID: &last} }, 2},
+		} {
+			t.Run(c.name, func(t *testing.T) {
+				mockRepo := newRepo()
+				mockRepo.ranks = map[model.ColumnKey][]string{backlog: column}
+				taskUC := usecase.NewTaskUsecase(mockRepo)
+				task := card(taskID, "doing", model.CategoryInProgress, "x")
+				mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+				mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "backlog", model.CategoryTodo, "a"), nil)
+				mockRepo.On("FindByIDAndUser", uint(4), userID).Return(card(4, "backlog", model.CategoryTodo, "c"), nil)
+				mockRepo.On("Update", task).Return(nil)
+
+				status := "backlog"
+				req := c.req(2, 4)
+				req.Status = &status
+				err := taskUC.MoveOnBoard(taskID, userID, req)
+
+				assert.NoError(t, err)
+				order := append([]string{task.BoardRank}, column...)
+				sort.Strings(order)
+				assert.Equal(t, c.want, sort.SearchStrings(order, task.BoardRank), order)
+			})
+		}
+	})
+
+	t.Run("MovesToAnotherColumn", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := card(taskID, "backlog", model.CategoryTodo, "x")
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "doing", model.CategoryInProgress, "c"), nil)
+		mockRepo.On("FindBlockers", taskID, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		doing, before := "doing", uint(2)
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{Status: &doing, BeforeID: &before})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "doing", task.Status)
+		assert.Equal(t, model.CategoryInProgress, task.StatusCategory)
+		assert.True(t, task.BoardRank < "c", task.BoardRank)
+		assert.Equal(t, []string{"doing"}, mockRepo.entered)
+	})
+
+	t.Run("LongRankRebalancesColumn", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := card(taskID, "backlog", model.CategoryTodo, "x")
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "backlog", model.CategoryTodo, "a"), nil)
+		mockRepo.On("FindByIDAndUser", uint(3), userID).Return(card(3, "backlog", model.CategoryTodo, "a00000000001"), nil)
+		mockRepo.On("Update", task).Return(nil)
+		mockRepo.On("RebalanceColumn", model.ColumnKey{ProjectID: projectID, Status: "backlog"}).Return(nil)
+
+		after, before := uint(2), uint(3)
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{AfterID: &after, BeforeID: &before})
+
+		assert.NoError(t, err)
+		assert.Greater(t, len(task.BoardRank), usecase.MaxRankLength)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OverdueOnArrivalGoesToBottom", func(t *testing.T) {
+		mockRepo := newRepo()
+		mockRepo.lastRanks = map[model.ColumnKey]string{{ProjectID: projectID, Status: model.StatusOverdue}: "m"}
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		past := time.Now().Add(-time.Hour)
+		task := card(taskID, "doing", model.CategoryInProgress, "x")
+		task.DueDate = &past
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(task, nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "backlog", model.CategoryTodo, "c"), nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		backlog, before := "backlog", uint(2)
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{Status: &backlog, BeforeID: &before})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusOverdue, task.Status)
+		assert.True(t, task.BoardRank > "m", task.BoardRank)
+	})
+
+	t.Run("MoveFollowsStatusMachine", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(card(taskID, "doing", model.CategoryInProgress, "x"), nil)
+		mockRepo.On("FindDescendants", taskID, userID).Return(&[]model.Task{{ID: 2, Status: "doing", StatusCategory: model.CategoryInProgress}}, nil)
+
+		done := "done"
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{Status: &done})
+
+		assert.ErrorIs(t, err, usecase.ErrOpenSubtasks)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("NeighbourInOtherColumn", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(card(taskID, "backlog", model.CategoryTodo, "x"), nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "doing", model.CategoryInProgress, "c"), nil)
+		mockRepo.On("FindByIDAndUser", uint(3), userID).Return(&model.Task{ID: 3, UserID: userID, ProjectID: &otherProject, Status: "backlog", BoardRank: "c"}, nil)
+
+		for _, neighbour := range []uint{2, 3, taskID} {
+			after := neighbour
+			err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{AfterID: &after})
+			assert.ErrorIs(t, err, usecase.ErrInvalidNeighbour)
+		}
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("NeighboursOutOfOrder", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(card(taskID, "backlog", model.CategoryTodo, "x"), nil)
+		mockRepo.On("FindByIDAndUser", uint(2), userID).Return(card(2, "backlog", model.CategoryTodo, "a"), nil)
+		mockRepo.On("FindByIDAndUser", uint(3), userID).Return(card(3, "backlog", model.CategoryTodo, "b"), nil)
+
+		after, before := uint(3), uint(2)
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{AfterID: &after, BeforeID: &before})
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidNeighbour)
+	})
+
+	t.Run("TaskOutsideProject", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, userID).Return(&model.Task{ID: taskID, UserID: userID, Status: model.StatusPending}, nil)
+
+		err := taskUC.MoveOnBoard(taskID, userID, model.BoardMoveRequest{})
+
+		assert.ErrorIs(t, err, usecase.ErrNotOnBoard)
+	})
+
+	t.Run("GroupsByColumn", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindBoard", projectID, userID).Return(&[]model.Task{
+			*card(1, "doing", model.CategoryInProgress, "a"),
+			*card(2, "backlog", model.CategoryTodo, "b"),
+			*card(3, model.StatusOverdue, model.CategoryTodo, "c"),
+			*card(4, "doing", model.CategoryInProgress, "d"),
+		}, nil)
+
+		result, err := taskUC.GetBoard(projectID, userID, nil)
+
+		assert.NoError(t, err)
+		columns := map[string][]uint{}
+		var order []string
+		for _, column := range result.Columns {
+			order = append(order, column.Status)
+			for _, task := range column.Tasks {
+				columns[column.Status] = append(columns[column.Status], task.ID)
+			}
+		}
+		assert.Equal(t, []string{"backlog", "doing", "done", model.StatusOverdue}, order)
+		assert.Equal(t, map[string][]uint{"backlog": {2}, "doing": {1, 4}, model.StatusOverdue: {3}}, columns)
+	})
+
+	t.Run("EmptyBoardOfUnknownProject", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindBoard", otherProject, userID).Return(&[]model.Task{}, nil)
+		mockRepo.On("FindProject", otherProject, model.Owner{UserID: userID}).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := taskUC.GetBoard(otherProject, userID, nil)
+
+		assert.ErrorIs(t, err, usecase.ErrProjectNotFound)
+	})
+
+	t.Run("RebalancerRewritesCrowdedColumns", func(t *testing.T) {
+		mockRepo := newRepo()
+		crowded := []model.ColumnKey{{ProjectID: projectID, Status: "backlog"}, {ProjectID: otherProject, Status: "doing"}}
+		mockRepo.On("FindCrowdedColumns", usecase.MaxRankLength, mock.Anything).Return(crowded, nil)
+		mockRepo.On("RebalanceColumn", mock.Anything).Return(nil)
+
+		rebalanced, err := usecase.NewRebalancer(mockRepo, time.Hour).RunOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, rebalanced)
+		mockRepo.AssertCalled(t, "RebalanceColumn", crowded[0])
+		mockRepo.AssertCalled(t, "RebalanceColumn", crowded[1])
+	})
+}
+
+func TestWipLimits(t *testing.T) {
+	logger.InitLogger()
+	taskID := uint(1)
+	memberID := uint(100)
+	adminID := uint(101)
+	workspaceID := uint(5)
+	projectID := uint(7)
+	perUser := model.WipLimit{ID: 1, WorkspaceID: &workspaceID, Status: model.StatusInProgress, Scope: model.WipPerUser, Max: 2}
+	perProject := model.WipLimit{ID: 2, ProjectID: &projectID, Status: model.StatusInProgress, Scope: model.WipPerProject, Max: 5}
+	newRepo := func() *MockTaskRepository {
+		mockRepo := &MockTaskRepository{wipLimits: []model.WipLimit{perUser, perProject}}
+		mockRepo.On("FindRole", workspaceID, memberID).Return(workspaceModel.RoleMember, nil)
+		mockRepo.On("FindRole", workspaceID, adminID).Return(workspaceModel.RoleAdmin, nil)
+		mockRepo.On("FindBlockers", taskID, mock.Anything).Return(&[]model.Task{}, nil)
+		return mockRepo
+	}
+	pending := func() *model.Task {
+		return &model.Task{ID: taskID, UserID: memberID, WorkspaceID: &workspaceID, ProjectID: &projectID, Status: model.StatusPending, StatusCategory: model.CategoryTodo}
+	}
+	worker := memberID
+	userBucket := model.WipQuery{Status: model.StatusInProgress, WorkspaceID: &workspaceID, WorkerID: &worker, ExcludeID: taskID}
+	projectBucket := model.WipQuery{Status: model.StatusInProgress, ProjectID: &projectID, ExcludeID: taskID}
+	started := model.StatusInProgress
+
+	t.Run("FullColumnRefused", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(pending(), nil)
+		mockRepo.On("CountWip", userBucket).Return(int64(2), nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &started}, taskID, memberID)
+
+		var full *usecase.WipLimitError
+		if assert.True(t, errors.As(err, &full)) {
+			assert.Equal(t, perUser.ID, full.Limit.ID)
+			assert.Equal(t, int64(2), full.Load)
+		}
+		assert.ErrorIs(t, err, usecase.ErrWipLimit)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("RoomLeft", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := pending()
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(task, nil)
+		mockRepo.On("CountWip", userBucket).Return(int64(1), nil)
+		mockRepo.On("CountWip", projectBucket).Return(int64(4), nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &started}, taskID, memberID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "CountWip", userBucket)
+		mockRepo.AssertCalled(t, "CountWip", projectBucket)
+		assert.Equal(t, []uint{perUser.ID, perProject.ID}, mockRepo.locked)
+		assert.Zero(t, mockRepo.unlocked, "limits must be locked in the transaction of the update")
+	})
+
+	t.Run("AssigneeCountsAsWorker", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := pending()
+		task.Status, task.StatusCategory = model.StatusInProgress, model.CategoryInProgress
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(task, nil)
+		assignee := adminID
+		mockRepo.On("CountWip", model.WipQuery{Status: model.StatusInProgress, WorkspaceID: &workspaceID, WorkerID: &assignee, ExcludeID: taskID}).Return(int64(2), nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{AssigneeID: &assignee}, taskID, memberID)
+
+		assert.ErrorIs(t, err, usecase.ErrWipLimit)
+	})
+
+	t.Run("UnrelatedEditNotCounted", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := pending()
+		task.Status, task.StatusCategory = model.StatusInProgress, model.CategoryInProgress
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		title := "Renamed"
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Title: &title}, taskID, memberID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "CountWip", mock.Anything)
+	})
+
+	t.Run("AdminOverrides", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		task := pending()
+		mockRepo.On("FindByIDAndUser", taskID, adminID).Return(task, nil)
+		mockRepo.On("CountWip", userBucket).Return(int64(2), nil)
+		mockRepo.On("CountWip", projectBucket).Return(int64(0), nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &started, OverrideWip: true}, taskID, adminID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusInProgress, task.Status)
+	})
+
+	t.Run("MemberCannotOverride", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(pending(), nil)
+		mockRepo.On("CountWip", userBucket).Return(int64(2), nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{Status: &started, OverrideWip: true}, taskID, memberID)
+
+		assert.ErrorIs(t, err, usecase.ErrNotWipManager)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("BoardMoveRefused", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindByIDAndUser", taskID, memberID).Return(pending(), nil)
+		mockRepo.On("CountWip", userBucket).Return(int64(1), nil)
+		mockRepo.On("CountWip", projectBucket).Return(int64(5), nil)
+
+		err := taskUC.MoveOnBoard(taskID, memberID, model.BoardMoveRequest{Status: &started})
+
+		var full *usecase.WipLimitError
+		if assert.True(t, errors.As(err, &full)) {
+			assert.Equal(t, perProject.ID, full.Limit.ID)
+		}
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("BoardShowsLoad", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindBoard", projectID, memberID).Return(&[]model.Task{*pending()}, nil)
+		viewerBucket := userBucket
+		viewerBucket.ExcludeID = 0
+		mockRepo.On("CountWip", viewerBucket).Return(int64(1), nil)
+		mockRepo.On("CountWip", model.WipQuery{Status: model.StatusInProgress, ProjectID: &projectID}).Return(int64(3), nil)
+
+		board, err := taskUC.GetBoard(projectID, memberID, &workspaceID)
+
+		assert.NoError(t, err)
+		for _, column := range board.Columns {
+			if column.Status != model.StatusInProgress {
+				assert.Empty(t, column.Limits, column.Status)
+				continue
+			}
+			if assert.Len(t, column.Limits, 2) {
+				assert.Equal(t, int64(1), column.Limits[0].Load)
+				assert.Equal(t, int64(3), column.Limits[1].Load)
+			}
+		}
+	})
+
+	t.Run("WorkspaceLimitsAreForAdmins", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		limit := model.WipLimit{WorkspaceID: &workspaceID, Status: "review", Scope: model.WipPerProject, Max: 3}
+		mockRepo.On("SaveWipLimit", &limit).Return(nil)
+
+		_, err := taskUC.SetWipLimit(limit, memberID, &workspaceID)
+		assert.ErrorIs(t, err, usecase.ErrNotWipManager)
+
+		saved, err := taskUC.SetWipLimit(limit, adminID, &workspaceID)
+		assert.NoError(t, err)
+		assert.Equal(t, "review", saved.Status)
+	})
+
+	t.Run("ProjectLimitNeedsWorkflowStatus", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: memberID}).Return(&projectModel.Project{ID: projectID, UserID: memberID}, nil)
+
+		_, err := taskUC.SetWipLimit(model.WipLimit{ProjectID: &projectID, Status: "review", Scope: model.WipPerUser, Max: 1}, memberID, nil)
+
+		assert.ErrorIs(t, err, usecase.ErrUnknownStatus)
+		mockRepo.AssertNotCalled(t, "SaveWipLimit", mock.Anything)
+	})
+
+	t.Run("DeleteNeedsManager", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindWipLimit", perProject.ID).Return(&perProject, nil)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: adminID}).Return(nil, gorm.ErrRecordNotFound)
+
+		err := taskUC.DeleteWipLimit(perProject.ID, adminID, nil)
+
+		assert.ErrorIs(t, err, usecase.ErrNotWipManager)
+		mockRepo.AssertNotCalled(t, "DeleteWipLimit", mock.Anything)
+	})
+
+	t.Run("WorkspaceProjectLimitsAreForAdmins", func(t *testing.T) {
+		mockRepo := newRepo()
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		limit := model.WipLimit{ProjectID: &projectID, Status: model.StatusInProgress, Scope: model.WipPerProject, Max: 3}
+		for _, userID := range []uint{memberID, adminID} {
+			mockRepo.On("FindProject", projectID, model.Owner{WorkspaceID: &workspaceID, UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: 300}, nil)
+		}
+		mockRepo.On("SaveWipLimit", &limit).Return(nil)
+		mockRepo.On("FindWipLimit", perProject.ID).Return(&perProject, nil)
+		mockRepo.On("DeleteWipLimit", perProject.ID).Return(nil)
+
+		limits, err := taskUC.GetWipLimits(memberID, &workspaceID, &projectID)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.WipLimit{perProject}, limits)
+
+		_, err = taskUC.SetWipLimit(limit, memberID, &workspaceID)
+		assert.ErrorIs(t, err, usecase.ErrNotWipManager)
+		assert.ErrorIs(t, taskUC.DeleteWipLimit(perProject.ID, memberID, &workspaceID), usecase.ErrNotWipManager)
+
+		_, err = taskUC.SetWipLimit(limit, adminID, &workspaceID)
+		assert.NoError(t, err)
+		assert.NoError(t, taskUC.DeleteWipLimit(perProject.ID, adminID, &workspaceID))
+		mockRepo.AssertNumberOfCalls(t, "SaveWipLimit", 1)
+		mockRepo.AssertNumberOfCalls(t, "DeleteWipLimit", 1)
+	})
+}
+
+func TestEstimates(t *testing.T) {
+	logger.InitLogger()
+	userID := uint(100)
+	otherID := uint(101)
+	at := func(s string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", s, err)
+		}
+		return parsed
+	}
+	minutes := func(n int) *int { return &n }
+	points := func(n float64) *float64 { return &n }
+	done := func(id, worker uint, completed string, labels ...labelModel.Label) model.Task {
+		return model.Task{
+			ID: id, Title: "Task", UserID: worker, Status: model.StatusCompleted, StatusCategory: model.CategoryDone, Labels: labels,
+			StatusEntries: []model.TaskStatusEntry{{TaskID: id, Status: model.StatusCompleted, EnteredAt: at(completed)}},
+		}
+	}
+	work := labelModel.Label{ID: 1, Name: "work"}
+
+	t.Run("Report", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		logged := done(1, userID, "2025-03-05T12:00:00Z", work)
+		logged.EstimateMinutes = minutes(60)
+		derived := done(2, userID, "2025-03-04T10:00:00Z")
+		derived.EstimateMinutes = minutes(120)
+		derived.StatusEntries = append(derived.StatusEntries, model.TaskStatusEntry{TaskID: 2, Status: model.StatusInProgress, EnteredAt: at("2025-03-04T09:00:00Z")})
+		small := done(3, otherID, "2025-03-11T12:00:00Z", work)
+		small.EstimatePoints = points(2)
+		large := done(4, otherID, "2025-03-12T12:00:00Z")
+		large.EstimatePoints = points(1)
+		unmeasured := done(5, otherID, "2025-03-12T12:00:00Z")
+		unmeasured.EstimatePoints = points(3)
+
+		mockRepo.On("FindCompletedEstimates", userID, (*uint)(nil), (*uint)(nil), at("2025-03-03T00:00:00Z"), at("2025-03-17T00:00:00Z")).
+			Return(&[]model.Task{logged, derived, small, large, unmeasured}, nil)
+		mockRepo.On("FindLoggedTime", []uint{1, 2, 3, 4, 5}).
+			Return(map[uint]time.Duration{1: 70 * time.Minute, 3: 2 * time.Hour, 4: 3 * time.Hour}, nil)
+
+		report, err := taskUC.GetEstimateReport(userID, model.EstimateQuery{From: "2025-03-03", To: "2025-03-16"})
+		assert.NoError(t, err)
+
+		// Point tasks took 300 minutes for 3 points
+		assert.InDelta(t, 100, report.MinutesPerPoint, 0.001)
+		assert.Equal(t, 1, report.Unmeasured)
+		assert.Equal(t, 4, report.Overall.Tasks)
+		assert.InDelta(t, 480, report.Overall.EstimatedMinutes, 0.001)
+		assert.InDelta(t, 430, report.Overall.ActualMinutes, 0.001)
+		assert.InDelta(t, 430.0/480, report.Overall.Ratio, 0.001)
+		assert.InDelta(t, 0.25, report.Overall.OnTarget, 0.001)
+
+		if assert.Len(t, report.ByWeek, 2) {
+			assert.Equal(t, "2025-W10", report.ByWeek[0].Key)
+			assert.Equal(t, "2025-03-03", report.ByWeek[0].Name)
+			assert.Equal(t, 2, report.ByWeek[0].Tasks)
+			assert.Equal(t, "2025-W11", report.ByWeek[1].Key)
+		}
+		if assert.Len(t, report.ByUser, 2) {
+			assert.Equal(t, "100", report.ByUser[0].Key)
+			assert.InDelta(t, 130.0/180, report.ByUser[0].Ratio, 0.001)
+			assert.Equal(t, "101", report.ByUser[1].Key)
+			assert.InDelta(t, 1, report.ByUser[1].Ratio, 0.001)
+		}
+		if assert.Len(t, report.ByLabel, 2) {
+			assert.Equal(t, model.EstimateGroup{Key: "1", Name: "work", EstimateStats: model.EstimateStats{Tasks: 2, EstimatedMinutes: 260, ActualMinutes: 190, Ratio: 190.0 / 260, OnTarget: 0.5}}, report.ByLabel[0])
+			assert.Equal(t, "none", report.ByLabel[1].Key)
+		}
+
+		// The derived task took half its estimate
+		if assert.Len(t, report.Outliers, 1) {
+			assert.Equal(t, uint(2), report.Outliers[0].TaskID)
+			assert.Equal(t, model.ActualDerived, report.Outliers[0].Source)
+			assert.InDelta(t, 0.5, report.Outliers[0].Ratio, 0.001)
+		}
+	})
+
+	t.Run("InTimeZone", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		projectID := uint(7)
+		workspaceID := uint(5)
+		mockRepo.On("FindProject", projectID, model.Owner{WorkspaceID: &workspaceID, UserID: userID}).Return(&projectModel.Project{ID: projectID, UserID: userID + 1}, nil)
+		mockRepo.On("FindCompletedEstimates", userID, &workspaceID, &projectID, at("2025-03-02T23:00:00Z"), at("2025-03-09T23:00:00Z")).
+			Return(&[]model.Task{}, nil)
+		mockRepo.On("FindLoggedTime", []uint{}).Return(map[uint]time.Duration{}, nil)
+
+		report, err := taskUC.GetEstimateReport(userID, model.EstimateQuery{From: "2025-03-03", To: "2025-03-09", TZ: "Europe/Berlin", ProjectID: &projectID, WorkspaceID: &workspaceID})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Overall.Tasks)
+		assert.Empty(t, report.Outliers)
+	})
+
+	t.Run("ProjectNotReached", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		projectID := uint(8)
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := taskUC.GetEstimateReport(userID, model.EstimateQuery{ProjectID: &projectID})
+		assert.ErrorIs(t, err, usecase.ErrProjectNotFound)
+		mockRepo.AssertNotCalled(t, "FindCompletedEstimates", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidTimezone", func(t *testing.T) {
+		taskUC := usecase.NewTaskUsecase(new(MockTaskRepository))
+
+		_, err := taskUC.GetEstimateReport(userID, model.EstimateQuery{TZ: "Nowhere/Special"})
+		assert.ErrorIs(t, err, usecase.ErrInvalidTimezone)
+	})
+
+	t.Run("UpdateSwitchesUnit", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		task := &model.Task{ID: 1, UserID: userID, Status: model.StatusPending, StatusCategory: model.CategoryTodo, EstimateMinutes: minutes(90)}
+		mockRepo.On("FindByIDAndUser", uint(1), userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		err := taskUC.UpdateTask(&model.UpdateTaskInput{EstimatePoints: points(5)}, 1, userID)
+		assert.NoError(t, err)
+		assert.Nil(t, task.EstimateMinutes)
+		assert.Equal(t, 5.0, *task.EstimatePoints)
+		if assert.Len(t, mockRepo.history, 1) && assert.Len(t, mockRepo.history[0].Changes, 1) {
+			change := mockRepo.history[0].Changes[0]
+			assert.Equal(t, "estimate", change.Field)
+			assert.Equal(t, "90m", *change.OldValue)
+			assert.Equal(t, "5pt", *change.NewValue)
+		}
+
+		err = taskUC.UpdateTask(&model.UpdateTaskInput{EstimateMinutes: minutes(0)}, 1, userID)
+		assert.NoError(t, err)
+		assert.Nil(t, task.EstimateMinutes)
+		assert.Nil(t, task.EstimatePoints)
+	})
+	t.Run("PointsInTenths", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+
+		err := taskUC.Create(model.Task{Title: "Task", UserID: userID, EstimatePoints: points(2.25)})
+		assert.ErrorIs(t, err, usecase.ErrEstimatePrecision)
+
+		task := &model.Task{ID: 1, UserID: userID, Status: model.StatusPending, StatusCategory: model.CategoryTodo}
+		mockRepo.On("FindByIDAndUser", uint(1), userID).Return(task, nil)
+		mockRepo.On("Update", task).Return(nil)
+
+		err = taskUC.UpdateTask(&model.UpdateTaskInput{EstimatePoints: points(0.3)}, 1, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.3, *task.EstimatePoints)
+
+		err = taskUC.UpdateTask(&model.UpdateTaskInput{EstimatePoints: points(1.05)}, 1, userID)
+		assert.ErrorIs(t, err, usecase.ErrEstimatePrecision)
+		assert.Equal(t, 0.3, *task.EstimatePoints)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestCSV(t *testing.T) {
+	logger.InitLogger()
+	userID := uint(1)
+	row := func(line int, title string) model.ImportRow {
+		return model.ImportRow{Line: line, Request: model.CreateTaskRequest{Title: title}}
+	}
+
+	t.Run("ExportPagesByID", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		full := make([]model.Task, 500)
+		for i := range full {
+			full[i] = model.Task{ID: uint(i + 1)}
+		}
+		mockRepo.On("FindForExport", userID, (*uint)(nil), uint(0), 500).Return(&full, nil)
+		mockRepo.On("FindForExport", userID, (*uint)(nil), uint(500), 500).Return(&[]model.Task{{ID: 501}}, nil)
+
+		var seen int
+		err := taskUC.Export(userID, nil, func(task model.Task) error {
+			seen++
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 501, seen)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExportStopsOnWriteError", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindForExport", userID, (*uint)(nil), uint(0), 500).Return(&[]model.Task{{ID: 1}, {ID: 2}}, nil)
+
+		err := taskUC.Export(userID, nil, func(task model.Task) error {
+			return errors.New("broken pipe")
+		})
+
+		assert.EqualError(t, err, "broken pipe")
+	})
+
+	t.Run("DryRunWritesNothingForGood", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		result, err := taskUC.Import([]model.ImportRow{row(2, "One"), row(3, "Two")}, userID, nil, nil, false)
+
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 2, result.Valid)
+		assert.Equal(t, 0, result.Imported)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindLabelsByName", model.Owner{UserID: userID}, []string{"work"}).Return(&[]labelModel.Label{{ID: 4, Name: "Work"}}, nil)
+		mockRepo.On("FindLabels", model.Owner{UserID: userID}, []uint{4}).Return(&[]labelModel.Label{{ID: 4, Name: "Work"}}, nil)
+		mockRepo.On("Save", mock.MatchedBy(func(task *model.Task) bool {
+			return task.Title == "One" && task.Status == "in_progress" && len(task.Labels) == 1 && task.Labels[0].ID == 4
+		})).Return(nil)
+
+		one := row(2, "One")
+		one.Status, one.Labels = "in_progress", []string{"WORK"}
+		result, err := taskUC.Import([]model.ImportRow{one}, userID, nil, nil, true)
+
+		assert.NoError(t, err)
+		assert.False(t, result.DryRun)
+		assert.Equal(t, 1, result.Imported)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RowErrorsRollBackTheCommit", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("FindLabelsByName", model.Owner{UserID: userID}, []string{"nope"}).Return(&[]labelModel.Label{}, nil)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		unknownLabel := row(3, "Labelled")
+		unknownLabel.Labels = []string{"nope"}
+		unknownStatus := row(4, "Odd status")
+		unknownStatus.Status = "limbo"
+		overdue := row(5, "Late")
+		overdue.Status = model.StatusOverdue
+		invalid := row(6, "")
+		invalid.Errors = []model.ImportError{{Line: 6, Field: "title", Message: "required"}}
+
+		result, err := taskUC.Import([]model.ImportRow{row(2, "Fine"), unknownLabel, unknownStatus, overdue, invalid}, userID, nil, nil, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.Rows)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 0, result.Imported)
+		assert.Equal(t, []model.ImportError{
+			{Line: 3, Field: "labels", Message: `label "nope" not found`},
+			{Line: 4, Field: "status", Message: usecase.ErrUnknownStatus.Error()},
+			{Line: 5, Field: "status", Message: usecase.ErrUnknownStatus.Error()},
+			{Line: 6, Field: "title", Message: "required"},
+		}, result.Errors)
+	})
+
+	t.Run("DoneStatusRefused", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(nil)
+
+		done := row(2, "Done already")
+		done.Status = model.StatusCompleted
+		result, err := taskUC.Import([]model.ImportRow{done}, userID, nil, nil, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result.Imported)
+		assert.Equal(t, []model.ImportError{{Line: 2, Field: "status", Message: usecase.ErrImportedDone.Error()}}, result.Errors)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("UnexpectedErrorFailsImport", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		mockRepo.On("Save", mock.AnythingOfType("*model.Task")).Return(errors.New("connection reset"))
+
+		result, err := taskUC.Import([]model.ImportRow{row(2, "One")}, userID, nil, nil, true)
+
+		assert.EqualError(t, err, "connection reset")
+		assert.Nil(t, result)
+	})
+
+	t.Run("ArchivedProject", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		projectID := uint(7)
+		archived := time.Now()
+		mockRepo.On("FindProject", projectID, model.Owner{UserID: userID}).Return(&projectModel.Project{ID: projectID, ArchivedAt: &archived}, nil)
+
+		_, err := taskUC.Import([]model.ImportRow{row(2, "One")}, userID, nil, &projectID, false)
+
+		assert.ErrorIs(t, err, usecase.ErrProjectArchived)
+	})
+
+	t.Run("ViewerRefused", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		taskUC := usecase.NewTaskUsecase(mockRepo)
+		workspaceID := uint(5)
+		mockRepo.On("FindRole", workspaceID, userID).Return(workspaceModel.RoleViewer, nil)
+
+		result, err := taskUC.Import([]model.ImportRow{row(2, "One")}, userID, &workspaceID, nil, true)
+
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
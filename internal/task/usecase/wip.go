@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/internal/task/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// GetWipLimits lists the WIP limits of a project userID sees in the active
+// workspace, or else those of the workspace
+func (uc *TaskusecaseImpl) GetWipLimits(userID uint, workspaceID, projectID *uint) ([]model.WipLimit, error) {
+	if projectID != nil {
+		if err := uc.reachProject(*projectID, userID, workspaceID); err != nil {
+			return nil, err
+		}
+		return uc.repo.FindWipLimits(nil, projectID)
+	}
+	if workspaceID == nil {
+		return nil, ErrWipNoOwner
+	}
+	return uc.repo.FindWipLimits(workspaceID, nil)
+}
+
+// SetWipLimit sets a limit on a status of a project or workspace userID manages,
+// replacing the one it had. A project's limit has to be on a status of its workflow.
+func (uc *TaskusecaseImpl) SetWipLimit(limit model.WipLimit, userID uint, workspaceID *uint) (*model.WipLimit, error) {
+	switch {
+	case limit.ProjectID != nil:
+		if err := uc.reachProject(*limit.ProjectID, userID, workspaceID); err != nil {
+			if errors.Is(err, ErrProjectNotFound) {
+				logger.Log.WithField("projectID", *limit.ProjectID).Warn("WIP limit refused: project not found")
+			}
+			return nil, err
+		}
+		if workspaceID != nil {
+			if err := uc.wipAdmin(*workspaceID, userID); err != nil {
+				return nil, err
+			}
+		}
+		wf, err := uc.workflowOf(limit.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := wf.Category(limit.Status); !ok {
+			return nil, ErrUnknownStatus
+		}
+	case limit.WorkspaceID != nil:
+		if err := uc.manageWip(limit, userID, workspaceID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrWipNoOwner
+	}
+
+	if err := uc.repo.SaveWipLimit(&limit); err != nil {
+		return nil, err
+	}
+	logger.Log.WithField("userID", userID).Info("WIP limit set on ", limit.Status)
+	return &limit, nil
+}
+
+// DeleteWipLimit lifts a WIP limit userID manages in the active workspace
+func (uc *TaskusecaseImpl) DeleteWipLimit(limitID, userID uint, workspaceID *uint) error {
+	limit, err := uc.repo.FindWipLimit(limitID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWipLimitNotFound
+		}
+		return err
+	}
+	if err := uc.manageWip(*limit, userID, workspaceID); err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteWipLimit(limitID); err != nil {
+		return err
+	}
+	logger.Log.WithField("userID", userID).Info("WIP limit lifted on ", limit.Status)
+	return nil
+}
+
+// checkWip refuses task in the place it is about to be saved in when a WIP limit
+// there is already reached. With override whoever manages the limit takes the
+// task in all the same. It runs in the transaction that saves task and holds
+// each limit it counts against until then, so two tasks cannot both take the
+// last place under a limit.
+func (uc *TaskusecaseImpl) checkWip(task *model.Task, actorID uint, override bool) error {
+	limits, err := uc.repo.FindWipLimits(task.WorkspaceID, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, limit := range limits {
+		query, ok := limit.Bucket(*task)
+		if !ok {
+			continue
+		}
+		if err := uc.repo.LockWipLimit(limit.ID); err != nil {
+			return err
+		}
+		load, err := uc.repo.CountWip(query)
+		if err != nil {
+			return err
+		}
+		if load < int64(limit.Max) {
+			continue
+		}
+		if override {
+			if err := uc.manageWip(limit, actorID, task.WorkspaceID); err != nil {
+				return err
+			}
+			logger.Log.WithFields(logger.LogFields(task.ID, actorID)).Info("WIP limit overridden on ", limit.Status)
+			continue
+		}
+		logger.Log.WithFields(logger.LogFields(task.ID, actorID)).Warn("Update failed: WIP limit reached on ", limit.Status)
+		return &WipLimitError{Limit: limit, Load: load}
+	}
+	return nil
+}
+
+// manageWip fails unless userID manages limit: a workspace's limits are up to its
+// admins, and so are those of its projects. A project outside any workspace is up
+// to its owner. workspaceID is the workspace the project is reached through.
+func (uc *TaskusecaseImpl) manageWip(limit model.WipLimit, userID uint, workspaceID *uint) error {
+	if limit.WorkspaceID != nil {
+		return uc.wipAdmin(*limit.WorkspaceID, userID)
+	}
+	if err := uc.reachProject(*limit.ProjectID, userID, workspaceID); err != nil {
+		if errors.Is(err, ErrProjectNotFound) {
+			logger.Log.WithField("userID", userID).Warn("WIP limit of a project the user cannot reach")
+			return ErrNotWipManager
+		}
+		return err
+	}
+	if workspaceID != nil {
+		return uc.wipAdmin(*workspaceID, userID)
+	}
+	return nil
+}
+
+// reachProject finds a project of userID's, or of the workspace when there is one,
+// the way its board does
+func (uc *TaskusecaseImpl) reachProject(projectID, userID uint, workspaceID *uint) error {
+	if _, err := uc.repo.FindProject(projectID, model.Owner{WorkspaceID: workspaceID, UserID: userID}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// wipAdmin fails unless userID is at least an admin of the workspace
+func (uc *TaskusecaseImpl) wipAdmin(workspaceID, userID uint) error {
+	role, err := uc.repo.FindRole(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleAdmin) {
+		logger.Log.WithField("userID", userID).Warn("Only workspace admins manage its WIP limits")
+		return ErrNotWipManager
+	}
+	return nil
+}
+
+// boardLoads is the load against each WIP limit on the board of a project as
+// userID sees it, in the active workspace
+func (uc *TaskusecaseImpl) boardLoads(board *model.Board, userID uint, workspaceID *uint) error {
+	limits, err := uc.repo.FindWipLimits(workspaceID, &board.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, limit := range limits {
+		for i := range board.Columns {
+			column := &board.Columns[i]
+			if column.Status != limit.Status {
+				continue
+			}
+			viewer := model.Task{Status: column.Status, UserID: userID, ProjectID: &board.ProjectID, WorkspaceID: workspaceID}
+			query, ok := limit.Bucket(viewer)
+			if !ok {
+				continue
+			}
+			load, err := uc.repo.CountWip(query)
+			if err != nil {
+				return err
+			}
+			column.Limits = append(column.Limits, model.WipLoad{Limit: limit, Load: load})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/timeentry/model"
+	"mymodule/internal/timeentry/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpTimeEntryhandler struct {
+	usecase usecase.TimeEntryUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewTimeEntryHandler(app *fiber.App, usecase usecase.TimeEntryUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpTimeEntryhandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	entry := app.Group("/time-entries", middleware.Middleware(token))
+	entry.Get("/timer", handler.GetTimer)
+	entry.Post("/timer", handler.StartTimer)
+	entry.Post("/timer/stop", handler.StopTimer)
+	entry.Get("/report", handler.GetReport)
+	entry.Post("/", handler.AddEntry)
+	entry.Get("/", handler.GetEntries)
+	entry.Put("/:entryId", handler.UpdateEntry)
+	entry.Delete("/:entryId", handler.DeleteEntry)
+}
+
+func (h *HttpTimeEntryhandler) GetTimer(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	entry, err := h.usecase.GetRunning(userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToTimeEntryResponse(*entry))
+}
+
+func (h *HttpTimeEntryhandler) StartTimer(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.StartTimerRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid timer request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entry, err := h.usecase.StartTimer(input.TaskID, userID, input.Note)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(model.ToTimeEntryResponse(*entry))
+}
+
+func (h *HttpTimeEntryhandler) StopTimer(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	entry, err := h.usecase.StopTimer(userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToTimeEntryResponse(*entry))
+}
+
+func (h *HttpTimeEntryhandler) AddEntry(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.TimeEntryRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid time entry request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entry, err := h.usecase.AddEntry(model.ToTimeEntry(input, userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(model.ToTimeEntryResponse(*entry))
+}
+
+func (h *HttpTimeEntryhandler) GetEntries(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	filter := model.TimeEntryFilter{From: c.Query("from"), To: c.Query("to"), TZ: c.Query("tz")}
+	if raw := c.Query("task_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid task ID"})
+		}
+		filter.TaskID = uint(id)
+	}
+	if err := h.valid.Struct(filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entries, err := h.usecase.GetEntries(userID, filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToTimeEntryResponseList(*entries))
+}
+
+func (h *HttpTimeEntryhandler) UpdateEntry(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	entryID, err := strconv.Atoi(c.Params("entryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid time entry ID"})
+	}
+
+	var input model.UpdateTimeEntryRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid time entry request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entry, err := h.usecase.UpdateEntry(uint(entryID), userID, input)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToTimeEntryResponse(*entry))
+}
+
+func (h *HttpTimeEntryhandler) DeleteEntry(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	entryID, err := strconv.Atoi(c.Params("entryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid time entry ID"})
+	}
+
+	if err := h.usecase.DeleteEntry(uint(entryID), userID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "time entry deleted"})
+}
+
+// GetReport totals the time of the user by task, project, label or day
+func (h *HttpTimeEntryhandler) GetReport(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	query := model.ReportQuery{From: c.Query("from"), To: c.Query("to"), TZ: c.Query("tz"), GroupBy: c.Query("group_by")}
+	if err := h.valid.Struct(query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.usecase.GetReport(userID, query)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToReportResponse(*report))
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound), errors.Is(err, usecase.ErrEntryNotFound), errors.Is(err, usecase.ErrNoRunningTimer):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrTimerRunning), errors.Is(err, usecase.ErrOverlappingEntry):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrInvalidRange), errors.Is(err, usecase.ErrInvalidTimezone), errors.Is(err, usecase.ErrInvalidPeriod):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrReadOnly):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+func ToTimeEntry(req TimeEntryRequest, userID uint) TimeEntry {
+	ended := req.EndedAt
+	return TimeEntry{
+		TaskID:    req.TaskID,
+		UserID:    userID,
+		StartedAt: req.StartedAt,
+		EndedAt:   &ended,
+		Note:      req.Note,
+	}
+}
+
+// ApplyTimeEntryUpdate copies the fields given in req onto entry
+func ApplyTimeEntryUpdate(entry *TimeEntry, req UpdateTimeEntryRequest) {
+	if req.TaskID != nil {
+		entry.TaskID = *req.TaskID
+	}
+	if req.StartedAt != nil {
+		entry.StartedAt = *req.StartedAt
+	}
+	if req.EndedAt != nil {
+		ended := *req.EndedAt
+		entry.EndedAt = &ended
+	}
+	if req.Note != nil {
+		entry.Note = *req.Note
+	}
+}
+
+func ToTimeEntryResponse(e TimeEntry) TimeEntryResponse {
+	return TimeEntryResponse{
+		ID:        e.ID,
+		TaskID:    e.TaskID,
+		StartedAt: e.StartedAt,
+		EndedAt:   e.EndedAt,
+		Running:   e.Running(),
+		Seconds:   int64(e.End(time.Now()).Sub(e.StartedAt) / time.Second),
+		Note:      e.Note,
+	}
+}
+
+func ToTimeEntryResponseList(entries []TimeEntry) []TimeEntryResponse {
+	res := make([]TimeEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, ToTimeEntryResponse(e))
+	}
+	return res
+}
+
+func ToReportResponse(r Report) ReportResponse {
+	totals := make([]TotalResponse, 0, len(r.Totals))
+	for _, t := range r.Totals {
+		totals = append(totals, TotalResponse{Key: t.Key, Name: t.Name, Seconds: t.Seconds})
+	}
+	loc := r.From.Location()
+	return ReportResponse{
+		From:    r.From.Format(DayLayout),
+		To:      r.To.In(loc).AddDate(0, 0, -1).Format(DayLayout),
+		TZ:      r.TZ,
+		GroupBy: r.GroupBy,
+		Seconds: r.Seconds,
+		Totals:  totals,
+	}
+}
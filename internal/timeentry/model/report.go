@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Groupings of a time report
+const (
+	GroupByTask    = "task"
+	GroupByProject = "project"
+	GroupByLabel   = "label"
+	GroupByDay     = "day"
+)
+
+// DayLayout is how days are written in reports and report queries
+const DayLayout = "2006-01-02"
+
+// ReportQuery is the request model for a time report: the days From to To, both
+// included, as they fall in the time zone TZ
+type ReportQuery struct {
+	From    string `validate:"omitempty,datetime=2006-01-02"`
+	To      string `validate:"omitempty,datetime=2006-01-02"`
+	TZ      string `validate:"omitempty,max=64"`
+	GroupBy string `validate:"omitempty,oneof=task project label day"`
+}
+
+// Report is the time a user tracked in a period, totalled per group
+type Report struct {
+	From    time.Time
+	To      time.Time // exclusive, the start of the day after the last one
+	TZ      string
+	GroupBy string
+	Seconds int64 // every entry counted once, however it is grouped
+	Totals  []Total
+}
+
+// Total is the time of one group of a report. Key is the ID of the task, project
+// or label, or the day; entries on tasks without a project or label go under "none".
+// An entry counts towards every label of its task.
+type Total struct {
+	Key     string
+	Name    string
+	Seconds int64
+}
+
+// ReportResponse is the response model for a time report
+type ReportResponse struct {
+	From    string          `json:"from" example:"2025-08-01"`
+	To      string          `json:"to" example:"2025-08-31"`
+	TZ      string          `json:"tz" example:"Europe/Berlin"`
+	GroupBy string          `json:"group_by" example:"day"`
+	Seconds int64           `json:"seconds" example:"27000"`
+	Totals  []TotalResponse `json:"totals"`
+}
+
+// TotalResponse is the response model for one group of a report
+type TotalResponse struct {
+	Key     string `json:"key" example:"2025-08-10"`
+	Name    string `json:"name,omitempty" example:"Write blog post"`
+	Seconds int64  `json:"seconds" example:"5400"`
+}
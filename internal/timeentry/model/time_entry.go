@@ -0,0 +1,79 @@
+package model
+
+import (
+	taskModel "mymodule/internal/task/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimeEntry is the DB model for time a user spent on a task, either tracked with
+// a timer or entered by hand. The entry stays when its task goes to the trash.
+type TimeEntry struct {
+	ID        uint            `gorm:"primaryKey" json:"id" example:"1"`
+	TaskID    uint            `gorm:"not null;index" json:"task_id" example:"1"`
+	Task      *taskModel.Task `gorm:"foreignKey:TaskID" json:"-"`
+	UserID    uint            `gorm:"not null;index" json:"user_id" example:"1"`
+	StartedAt time.Time       `gorm:"not null" json:"started_at" example:"2025-08-10T09:00:00Z"`
+	EndedAt   *time.Time      `gorm:"default:null" json:"ended_at,omitempty" example:"2025-08-10T10:30:00Z"` // empty while the timer runs
+	Note      string          `gorm:"type:text;not null;default:''" json:"note" example:"Drafted the outline"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	DeletedAt gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// Running reports whether the entry is a timer that has not been stopped
+func (e TimeEntry) Running() bool {
+	return e.EndedAt == nil
+}
+
+// End is when the entry ends, now for a running timer
+func (e TimeEntry) End(now time.Time) time.Time {
+	if e.EndedAt != nil {
+		return *e.EndedAt
+	}
+	return now
+}
+
+// StartTimerRequest is the request model for starting a timer on a task
+type StartTimerRequest struct {
+	TaskID uint   `json:"task_id" example:"1" validate:"required"`
+	Note   string `json:"note,omitempty" example:"Drafted the outline" validate:"max=1000"`
+}
+
+// TimeEntryRequest is the request model for entering time by hand
+type TimeEntryRequest struct {
+	TaskID    uint      `json:"task_id" example:"1" validate:"required"`
+	StartedAt time.Time `json:"started_at" example:"2025-08-10T09:00:00Z" validate:"required"`
+	EndedAt   time.Time `json:"ended_at" example:"2025-08-10T10:30:00Z" validate:"required"`
+	Note      string    `json:"note,omitempty" example:"Drafted the outline" validate:"max=1000"`
+}
+
+// UpdateTimeEntryRequest is the request model for editing an entry, fields left
+// out stay as they are. Giving a running timer an end stops it.
+type UpdateTimeEntryRequest struct {
+	TaskID    *uint      `json:"task_id,omitempty" example:"1"`
+	StartedAt *time.Time `json:"started_at,omitempty" example:"2025-08-10T09:00:00Z"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" example:"2025-08-10T10:30:00Z"`
+	Note      *string    `json:"note,omitempty" example:"Drafted the outline" validate:"omitempty,max=1000"`
+}
+
+// TimeEntryFilter narrows the entries listed to those touching the days From to
+// To, as they fall in the time zone TZ, and with TaskID to one task
+type TimeEntryFilter struct {
+	From   string `validate:"omitempty,datetime=2006-01-02"`
+	To     string `validate:"omitempty,datetime=2006-01-02"`
+	TZ     string `validate:"omitempty,max=64"`
+	TaskID uint
+}
+
+// TimeEntryResponse is the response model for a time entry
+type TimeEntryResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	TaskID    uint       `json:"task_id" example:"1"`
+	StartedAt time.Time  `json:"started_at" example:"2025-08-10T09:00:00Z"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" example:"2025-08-10T10:30:00Z"`
+	Running   bool       `json:"running" example:"false"`
+	Seconds   int64      `json:"seconds" example:"5400"` // so far for a running timer
+	Note      string     `json:"note" example:"Drafted the outline"`
+}
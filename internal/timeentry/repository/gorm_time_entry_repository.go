@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"math"
+	projectModel "mymodule/internal/project/model"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/internal/timeentry/model"
+	"mymodule/internal/timeentry/usecase"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormTimeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTimeEntryRepository(db *gorm.DB) usecase.TimeEntryRepository {
+	return &GormTimeEntryRepository{db: db}
+}
+
+func (r *GormTimeEntryRepository) Create(entry *model.TimeEntry) error {
+	if err := r.db.Omit(clause.Associations).Create(entry).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(entry.TaskID, entry.UserID)).Error("Failed to save time entry")
+		return err
+	}
+	logger.Log.WithField("entryID", entry.ID).Info("Time entry saved successfully")
+	return nil
+}
+
+func (r *GormTimeEntryRepository) Update(entry *model.TimeEntry) error {
+	if err := r.db.Omit(clause.Associations).Save(entry).Error; err != nil {
+		logger.Log.WithField("entryID", entry.ID).Error("Failed to update time entry")
+		return err
+	}
+	return nil
+}
+
+func (r *GormTimeEntryRepository) Delete(entryID uint) error {
+	if err := r.db.Delete(&model.TimeEntry{}, entryID).Error; err != nil {
+		logger.Log.WithField("entryID", entryID).Error("Failed to delete time entry")
+		return err
+	}
+	return nil
+}
+
+func (r *GormTimeEntryRepository) FindByIDAndUser(entryID, userID uint) (*model.TimeEntry, error) {
+	var entry model.TimeEntry
+	if err := r.db.Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		logger.Log.WithField("entryID", entryID).Error("Failed to find time entry")
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindRunning returns the timer userID has not stopped yet
+func (r *GormTimeEntryRepository) FindRunning(userID uint) (*model.TimeEntry, error) {
+	var entry model.TimeEntry
+	if err := r.db.Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindOverlapping returns the entries of userID sharing time with [start, end),
+// a nil end standing for a timer that still runs. excludeID is the entry being
+// edited.
+func (r *GormTimeEntryRepository) FindOverlapping(userID uint, start time.Time, end *time.Time, excludeID uint) (*[]model.TimeEntry, error) {
+	var entries []model.TimeEntry
+	query := r.db.Where("user_id = ? AND id <> ? AND (ended_at IS NULL OR ended_at > ?)", userID, excludeID, start)
+	if end != nil {
+		query = query.Where("started_at < ?", *end)
+	}
+	if err := query.Order("started_at, id").Find(&entries).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find overlapping time entries")
+		return nil, err
+	}
+	return &entries, nil
+}
+
+// FindInRange returns the entries of userID sharing time with [from, to), on the
+// task taskID unless it is zero, with their tasks and labels. Tasks in the trash
+// are loaded too, the time spent on them still counts.
+func (r *GormTimeEntryRepository) FindInRange(userID uint, from, to time.Time, taskID uint) (*[]model.TimeEntry, error) {
+	var entries []model.TimeEntry
+	query := r.db.
+		Preload("Task", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Task.Labels").
+		Where("user_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", userID, to, from)
+	if taskID != 0 {
+		query = query.Where("task_id = ?", taskID)
+	}
+	if err := query.Order("started_at, id").Find(&entries).Error; err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find time entries in range")
+		return nil, err
+	}
+	return &entries, nil
+}
+
+// FindTask returns the task with taskID if userID can see it
+func (r *GormTimeEntryRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	var task taskModel.Task
+	if err := r.db.Scopes(taskModel.VisibleTo(userID)).Where("id = ?", taskID).First(&task).Error; err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to find task for time entry")
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindRole returns the role of userID in a workspace, empty when not a member
+func (r *GormTimeEntryRepository) FindRole(workspaceID, userID uint) (string, error) {
+	var member workspaceModel.Member
+	result := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Limit(1).Find(&member)
+	if result.Error != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace role")
+		return "", result.Error
+	}
+	return member.Role, nil
+}
+
+// FindProjectNames maps the IDs of projects to their names, trashed ones included
+func (r *GormTimeEntryRepository) FindProjectNames(projectIDs []uint) (map[uint]string, error) {
+	var projects []projectModel.Project
+	if err := r.db.Unscoped().Select("id", "name").Where("id IN ?", projectIDs).Find(&projects).Error; err != nil {
+		logger.Log.Error("Failed to find project names")
+		return nil, err
+	}
+	names := make(map[uint]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+	return names, nil
+}
+
+// FindLoggedTime sums the stopped time entries of each task by anyone
+func (r *GormTimeEntryRepository) FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error) {
+	logged := make(map[uint]time.Duration, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return logged, nil
+	}
+	elapsed := "(julianday(ended_at) - julianday(started_at)) * 86400"
+	if r.db.Dialector.Name() == "postgres" {
+		elapsed = "EXTRACT(EPOCH FROM ended_at - started_at)"
+	}
+	var sums []struct {
+		TaskID  uint
+		Seconds float64
+	}
+	if err := r.db.Table("time_entries").Select("task_id, SUM("+elapsed+") AS seconds").
+		Where("task_id IN ? AND ended_at IS NOT NULL", taskIDs).Group("task_id").Scan(&sums).Error; err != nil {
+		logger.Log.Error("Failed to find logged time")
+		return nil, err
+	}
+	for _, sum := range sums {
+		logged[sum.TaskID] = time.Duration(math.Round(sum.Seconds)) * time.Second
+	}
+	return logged, nil
+}
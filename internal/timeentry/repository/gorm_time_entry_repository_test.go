@@ -0,0 +1,153 @@
+package repository_test
+
+import (
+	"log"
+	labelModel "mymodule/internal/label/model"
+	projectModel "mymodule/internal/project/model"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/internal/timeentry/model"
+	"mymodule/internal/timeentry/repository"
+	userModel "mymodule/internal/user/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	err = db.AutoMigrate(&userModel.User{}, &labelModel.Label{}, &projectModel.Project{}, &taskModel.Task{}, &model.TimeEntry{})
+	if err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2025, 3, 10, hour, minute, 0, 0, time.UTC)
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestTimeEntries(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormTimeEntryRepository(db)
+
+	task := taskModel.Task{Title: "Write", UserID: 1}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	morning := model.TimeEntry{TaskID: task.ID, UserID: 1, StartedAt: at(9, 0), EndedAt: ptr(at(10, 0))}
+	others := model.TimeEntry{TaskID: task.ID, UserID: 2, StartedAt: at(9, 0), EndedAt: ptr(at(12, 0))}
+	for _, e := range []*model.TimeEntry{&morning, &others} {
+		if err := repo.Create(e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	overlapping := []struct {
+		name  string
+		start time.Time
+		end   *time.Time
+		want  int
+	}{
+		{"Inside", at(9, 15), ptr(at(9, 45)), 1},
+		{"Around", at(8, 0), ptr(at(11, 0)), 1},
+		{"Tail", at(9, 59), ptr(at(11, 0)), 1},
+		{"TouchesEnd", at(10, 0), ptr(at(11, 0)), 0},
+		{"TouchesStart", at(8, 0), ptr(at(9, 0)), 0},
+		{"RunningFromBefore", at(8, 0), nil, 1},
+		{"RunningFromAfter", at(10, 0), nil, 0},
+	}
+	for _, tc := range overlapping {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := repo.FindOverlapping(1, tc.start, tc.end, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(*got) != tc.want {
+				t.Fatalf("expected %d overlapping entries, got: %d", tc.want, len(*got))
+			}
+		})
+	}
+
+	t.Run("ExcludesEditedEntry", func(t *testing.T) {
+		got, err := repo.FindOverlapping(1, at(9, 30), ptr(at(10, 30)), morning.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*got) != 0 {
+			t.Fatalf("expected the entry not to overlap itself, got: %v", *got)
+		}
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		if _, err := repo.FindRunning(1); err != gorm.ErrRecordNotFound {
+			t.Fatalf("expected no running timer, got: %v", err)
+		}
+		timer := model.TimeEntry{TaskID: task.ID, UserID: 1, StartedAt: at(13, 0)}
+		if err := repo.Create(&timer); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		running, err := repo.FindRunning(1)
+		if err != nil || running.ID != timer.ID {
+			t.Fatalf("expected timer %d running, got: %v %v", timer.ID, running, err)
+		}
+		got, err := repo.FindOverlapping(1, at(14, 0), ptr(at(15, 0)), 0)
+		if err != nil || len(*got) != 1 {
+			t.Fatalf("expected the running timer to overlap later entries, got: %v %v", got, err)
+		}
+		if err := repo.Delete(timer.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("LoggedTimeOfEveryone", func(t *testing.T) {
+		logged, err := repo.FindLoggedTime([]uint{task.ID, task.ID + 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(logged) != 1 || logged[task.ID] != 4*time.Hour {
+			t.Fatalf("expected the stopped entries of both users to add up to 4h, got: %v", logged)
+		}
+	})
+
+	t.Run("SurvivesTaskSoftDelete", func(t *testing.T) {
+		if err := db.Delete(&task).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries, err := repo.FindInRange(1, at(0, 0), at(24, 0), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*entries) != 1 || (*entries)[0].ID != morning.ID {
+			t.Fatalf("expected the morning entry only, got: %v", *entries)
+		}
+		if (*entries)[0].Task == nil || (*entries)[0].Task.Title != "Write" {
+			t.Fatalf("expected the trashed task loaded, got: %v", (*entries)[0].Task)
+		}
+	})
+
+	t.Run("InRangeClipsByOverlap", func(t *testing.T) {
+		entries, err := repo.FindInRange(1, at(10, 0), at(24, 0), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(*entries) != 0 {
+			t.Fatalf("expected no entries after 10:00, got: %v", *entries)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"errors"
+	"mymodule/pkg/period"
+)
+
+var (
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrEntryNotFound    = errors.New("time entry not found")
+	ErrTimerRunning     = errors.New("a timer is already running")
+	ErrNoRunningTimer   = errors.New("no timer is running")
+	ErrInvalidRange     = errors.New("an entry has to end after it starts and not in the future")
+	ErrOverlappingEntry = errors.New("the entry overlaps another one")
+	ErrReadOnly         = errors.New("your role in this workspace only allows viewing tasks")
+	ErrInvalidTimezone  = period.ErrInvalidTimezone
+	ErrInvalidPeriod    = period.ErrInvalidPeriod
+)
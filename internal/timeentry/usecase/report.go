@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"mymodule/internal/timeentry/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/period"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// noneKey groups the time of tasks without a project or label
+const noneKey = "none"
+
+// defaultPeriodDays is the span of listings and reports naming no first day
+const defaultPeriodDays = 7
+
+// GetReport totals the time userID tracked in the days of query, clipping entries
+// to the period and counting running timers up to now. Days start at midnight in
+// the time zone of the query, UTC unless it names one.
+func (uc *TimeEntryusecaseImpl) GetReport(userID uint, query model.ReportQuery) (*model.Report, error) {
+	days, err := period.Days(query.From, query.To, query.TZ, uc.now(), defaultPeriodDays)
+	if err != nil {
+		return nil, err
+	}
+	from, to := days.From, days.To
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = model.GroupByTask
+	}
+	entries, err := uc.repo.FindInRange(userID, from.UTC(), to.UTC(), 0)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get time entries for report")
+		return nil, err
+	}
+
+	totals := make(map[string]*model.Total)
+	spent := make(map[string]time.Duration)
+	add := func(key, name string, d time.Duration) {
+		if _, ok := totals[key]; !ok {
+			totals[key] = &model.Total{Key: key, Name: name}
+		}
+		spent[key] += d
+	}
+	now := uc.now()
+	var sum time.Duration
+	for _, e := range *entries {
+		start, end := e.StartedAt, e.End(now)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		sum += end.Sub(start)
+
+		switch groupBy {
+		case model.GroupByDay:
+			days.Split(start, end, func(day string, d time.Duration) { add(day, "", d) })
+		case model.GroupByProject:
+			if e.Task == nil || e.Task.ProjectID == nil {
+				add(noneKey, "", end.Sub(start))
+			} else {
+				add(strconv.FormatUint(uint64(*e.Task.ProjectID), 10), "", end.Sub(start))
+			}
+		case model.GroupByLabel:
+			if e.Task == nil || len(e.Task.Labels) == 0 {
+				add(noneKey, "", end.Sub(start))
+			}
+			if e.Task != nil {
+				for _, l := range e.Task.Labels {
+					add(strconv.FormatUint(uint64(l.ID), 10), l.Name, end.Sub(start))
+				}
+			}
+		default:
+			name := ""
+			if e.Task != nil {
+				name = e.Task.Title
+			}
+			add(strconv.FormatUint(uint64(e.TaskID), 10), name, end.Sub(start))
+		}
+	}
+	if groupBy == model.GroupByProject {
+		if err := uc.nameProjects(totals); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &model.Report{From: from, To: to, TZ: days.Loc.String(), GroupBy: groupBy, Seconds: seconds(sum), Totals: []model.Total{}}
+	for key, t := range totals {
+		t.Seconds = seconds(spent[key])
+		report.Totals = append(report.Totals, *t)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		a, b := report.Totals[i], report.Totals[j]
+		if groupBy == model.GroupByDay || a.Seconds == b.Seconds {
+			return a.Key < b.Key
+		}
+		return a.Seconds > b.Seconds
+	})
+	return report, nil
+}
+
+// nameProjects fills in the names of the projects in totals, trashed ones included
+func (uc *TimeEntryusecaseImpl) nameProjects(totals map[string]*model.Total) error {
+	var ids []uint
+	for key := range totals {
+		if id, err := strconv.ParseUint(key, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	names, err := uc.repo.FindProjectNames(ids)
+	if err != nil {
+		logger.Log.Error("Failed to find project names for report")
+		return err
+	}
+	for _, id := range ids {
+		totals[strconv.FormatUint(uint64(id), 10)].Name = names[id]
+	}
+	return nil
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"errors"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/internal/timeentry/model"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/period"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TimeEntryRepository interface {
+	Create(entry *model.TimeEntry) error
+	Update(entry *model.TimeEntry) error
+	Delete(entryID uint) error
+	FindByIDAndUser(entryID, userID uint) (*model.TimeEntry, error)
+	FindRunning(userID uint) (*model.TimeEntry, error)
+	FindOverlapping(userID uint, start time.Time, end *time.Time, excludeID uint) (*[]model.TimeEntry, error)
+	FindInRange(userID uint, from, to time.Time, taskID uint) (*[]model.TimeEntry, error)
+	FindTask(taskID, userID uint) (*taskModel.Task, error)
+	FindRole(workspaceID, userID uint) (string, error)
+	FindProjectNames(projectIDs []uint) (map[uint]string, error)
+	FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error)
+}
+
+type TimeEntryUsecase interface {
+	StartTimer(taskID, userID uint, note string) (*model.TimeEntry, error)
+	StopTimer(userID uint) (*model.TimeEntry, error)
+	GetRunning(userID uint) (*model.TimeEntry, error)
+	AddEntry(entry model.TimeEntry) (*model.TimeEntry, error)
+	UpdateEntry(entryID, userID uint, req model.UpdateTimeEntryRequest) (*model.TimeEntry, error)
+	DeleteEntry(entryID, userID uint) error
+	GetEntries(userID uint, filter model.TimeEntryFilter) (*[]model.TimeEntry, error)
+	GetReport(userID uint, query model.ReportQuery) (*model.Report, error)
+}
+
+type TimeEntryusecaseImpl struct {
+	repo TimeEntryRepository
+	now  func() time.Time
+}
+
+func NewTimeEntryUsecase(repo TimeEntryRepository) TimeEntryUsecase {
+	return &TimeEntryusecaseImpl{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// StartTimer starts tracking time on a task. A user runs one timer at a time.
+func (uc *TimeEntryusecaseImpl) StartTimer(taskID, userID uint, note string) (*model.TimeEntry, error) {
+	if err := uc.checkTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := uc.repo.FindRunning(userID); err == nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Timer not started: another one is running")
+		return nil, ErrTimerRunning
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	entry := model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: uc.now().UTC(), Note: note}
+	if err := uc.checkOverlap(&entry); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Create(&entry); err != nil {
+		logger.Log.WithFields(logger.LogFields(taskID, userID)).Error("Failed to start timer")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(taskID, userID)).Info("Timer started")
+	return &entry, nil
+}
+
+// StopTimer ends the running timer of userID now
+func (uc *TimeEntryusecaseImpl) StopTimer(userID uint) (*model.TimeEntry, error) {
+	entry, err := uc.GetRunning(userID)
+	if err != nil {
+		return nil, err
+	}
+	ended := uc.now().UTC()
+	if !ended.After(entry.StartedAt) {
+		ended = entry.StartedAt.Add(time.Second)
+	}
+	entry.EndedAt = &ended
+	if err := uc.repo.Update(entry); err != nil {
+		logger.Log.WithFields(logger.LogFields(entry.TaskID, userID)).Error("Failed to stop timer")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(entry.TaskID, userID)).Info("Timer stopped")
+	return entry, nil
+}
+
+func (uc *TimeEntryusecaseImpl) GetRunning(userID uint) (*model.TimeEntry, error) {
+	entry, err := uc.repo.FindRunning(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		logger.Log.WithField("userID", userID).Error("Failed to find running timer")
+		return nil, err
+	}
+	return entry, nil
+}
+
+// AddEntry records time worked on a task after the fact
+func (uc *TimeEntryusecaseImpl) AddEntry(entry model.TimeEntry) (*model.TimeEntry, error) {
+	if err := uc.checkTask(entry.TaskID, entry.UserID); err != nil {
+		return nil, err
+	}
+	if err := uc.checkRange(&entry); err != nil {
+		return nil, err
+	}
+	if err := uc.checkOverlap(&entry); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Create(&entry); err != nil {
+		logger.Log.WithFields(logger.LogFields(entry.TaskID, entry.UserID)).Error("Failed to add time entry")
+		return nil, err
+	}
+
+	logger.Log.WithFields(logger.LogFields(entry.TaskID, entry.UserID)).Info("Time entry added")
+	return &entry, nil
+}
+
+// UpdateEntry edits an entry of userID. Moving it to another task takes a task
+// they can see, the entry itself may stay on a task since trashed.
+func (uc *TimeEntryusecaseImpl) UpdateEntry(entryID, userID uint, req model.UpdateTimeEntryRequest) (*model.TimeEntry, error) {
+	entry, err := uc.findEntry(entryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkEntryTask(entry, userID); err != nil {
+		return nil, err
+	}
+	if req.TaskID != nil && *req.TaskID != entry.TaskID {
+		if err := uc.checkTask(*req.TaskID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	model.ApplyTimeEntryUpdate(entry, req)
+	if err := uc.checkRange(entry); err != nil {
+		return nil, err
+	}
+	if err := uc.checkOverlap(entry); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Update(entry); err != nil {
+		logger.Log.WithField("entryID", entryID).Error("Failed to update time entry")
+		return nil, err
+	}
+
+	logger.Log.WithField("entryID", entryID).Info("Time entry updated")
+	return entry, nil
+}
+
+func (uc *TimeEntryusecaseImpl) DeleteEntry(entryID, userID uint) error {
+	entry, err := uc.findEntry(entryID, userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkEntryTask(entry, userID); err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(entryID); err != nil {
+		logger.Log.WithField("entryID", entryID).Error("Failed to delete time entry")
+		return err
+	}
+
+	logger.Log.WithField("entryID", entryID).Info("Time entry deleted")
+	return nil
+}
+
+// GetEntries lists the entries of userID touching the days of filter, the last
+// week when it names none
+func (uc *TimeEntryusecaseImpl) GetEntries(userID uint, filter model.TimeEntryFilter) (*[]model.TimeEntry, error) {
+	days, err := period.Days(filter.From, filter.To, filter.TZ, uc.now(), defaultPeriodDays)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := uc.repo.FindInRange(userID, days.From.UTC(), days.To.UTC(), filter.TaskID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get time entries")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// checkRange makes sure an entry ends after it starts and neither lies ahead.
+// A running timer has no end yet.
+func (uc *TimeEntryusecaseImpl) checkRange(entry *model.TimeEntry) error {
+	now := uc.now()
+	entry.StartedAt = entry.StartedAt.UTC()
+	if entry.StartedAt.After(now) {
+		return ErrInvalidRange
+	}
+	if entry.EndedAt != nil {
+		ended := entry.EndedAt.UTC()
+		if !ended.After(entry.StartedAt) || ended.After(now) {
+			return ErrInvalidRange
+		}
+		entry.EndedAt = &ended
+	}
+	return nil
+}
+
+// checkOverlap refuses an entry sharing time with another entry of its user, one
+// cannot work on two tasks at once. Entries may touch end to start.
+func (uc *TimeEntryusecaseImpl) checkOverlap(entry *model.TimeEntry) error {
+	others, err := uc.repo.FindOverlapping(entry.UserID, entry.StartedAt, entry.EndedAt, entry.ID)
+	if err != nil {
+		logger.Log.WithFields(logger.LogFields(entry.TaskID, entry.UserID)).Error("Failed to look for overlapping time entries")
+		return err
+	}
+	if len(*others) > 0 {
+		logger.Log.WithFields(logger.LogFields(entry.TaskID, entry.UserID)).Warn("Time entry overlaps another one")
+		return ErrOverlappingEntry
+	}
+	return nil
+}
+
+// checkTask makes sure userID can see the task and log time on it
+func (uc *TimeEntryusecaseImpl) checkTask(taskID, userID uint) error {
+	task, err := uc.repo.FindTask(taskID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithFields(logger.LogFields(taskID, userID)).Warn("Task not found for time entry")
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	return uc.checkWritable(task, userID)
+}
+
+// checkEntryTask refuses changes to an entry on a task userID may only view. An
+// entry on a task since trashed stays theirs to change.
+func (uc *TimeEntryusecaseImpl) checkEntryTask(entry *model.TimeEntry, userID uint) error {
+	task, err := uc.repo.FindTask(entry.TaskID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.checkWritable(task, userID)
+}
+
+// checkWritable refuses changes by userID on a task of a workspace where they
+// may only view, as the task module does
+func (uc *TimeEntryusecaseImpl) checkWritable(task *taskModel.Task, userID uint) error {
+	if task.WorkspaceID == nil {
+		return nil
+	}
+	role, err := uc.repo.FindRole(*task.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaceModel.AtLeast(role, workspaceModel.RoleMember) {
+		logger.Log.WithFields(logger.LogFields(task.ID, userID)).Warn("Viewer tried to change a task")
+		return ErrReadOnly
+	}
+	return nil
+}
+
+func (uc *TimeEntryusecaseImpl) findEntry(entryID, userID uint) (*model.TimeEntry, error) {
+	entry, err := uc.repo.FindByIDAndUser(entryID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.WithField("entryID", entryID).Warn("Time entry not found for this user")
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
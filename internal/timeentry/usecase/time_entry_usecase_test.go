@@ -0,0 +1,423 @@
+package usecase_test
+
+import (
+	labelModel "mymodule/internal/label/model"
+	taskModel "mymodule/internal/task/model"
+	"mymodule/internal/timeentry/model"
+	"mymodule/internal/timeentry/usecase"
+	workspaceModel "mymodule/internal/workspace/model"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockTimeEntryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTimeEntryRepository) Create(entry *model.TimeEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockTimeEntryRepository) Update(entry *model.TimeEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockTimeEntryRepository) Delete(entryID uint) error {
+	args := m.Called(entryID)
+	return args.Error(0)
+}
+
+func (m *MockTimeEntryRepository) FindByIDAndUser(entryID, userID uint) (*model.TimeEntry, error) {
+	args := m.Called(entryID, userID)
+	return args.Get(0).(*model.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindRunning(userID uint) (*model.TimeEntry, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*model.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindOverlapping(userID uint, start time.Time, end *time.Time, excludeID uint) (*[]model.TimeEntry, error) {
+	args := m.Called(userID, start, end, excludeID)
+	return args.Get(0).(*[]model.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindInRange(userID uint, from, to time.Time, taskID uint) (*[]model.TimeEntry, error) {
+	args := m.Called(userID, from, to, taskID)
+	return args.Get(0).(*[]model.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindTask(taskID, userID uint) (*taskModel.Task, error) {
+	args := m.Called(taskID, userID)
+	return args.Get(0).(*taskModel.Task), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindRole(workspaceID, userID uint) (string, error) {
+	args := m.Called(workspaceID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindProjectNames(projectIDs []uint) (map[uint]string, error) {
+	args := m.Called(projectIDs)
+	return args.Get(0).(map[uint]string), args.Error(1)
+}
+
+func (m *MockTimeEntryRepository) FindLoggedTime(taskIDs []uint) (map[uint]time.Duration, error) {
+	args := m.Called(taskIDs)
+	return args.Get(0).(map[uint]time.Duration), args.Error(1)
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestTimer(t *testing.T) {
+	userID := uint(100)
+	taskID := uint(1)
+
+	t.Run("Start", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindRunning", userID).Return((*model.TimeEntry)(nil), gorm.ErrRecordNotFound)
+		mockRepo.On("FindOverlapping", userID, mock.Anything, (*time.Time)(nil), uint(0)).Return(&[]model.TimeEntry{}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.TimeEntry")).Return(nil)
+
+		entry, err := uc.StartTimer(taskID, userID, "outline")
+		assert.NoError(t, err)
+		assert.True(t, entry.Running())
+		assert.Equal(t, "outline", entry.Note)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OneRunningTimerPerUser", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindRunning", userID).Return(&model.TimeEntry{ID: 7, TaskID: 2, UserID: userID}, nil)
+
+		_, err := uc.StartTimer(taskID, userID, "")
+		assert.ErrorIs(t, err, usecase.ErrTimerRunning)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		running := &model.TimeEntry{ID: 7, TaskID: taskID, UserID: userID, StartedAt: time.Now().Add(-time.Hour).UTC()}
+		mockRepo.On("FindRunning", userID).Return(running, nil)
+		mockRepo.On("Update", running).Return(nil)
+
+		entry, err := uc.StopTimer(userID)
+		assert.NoError(t, err)
+		assert.False(t, entry.Running())
+		assert.True(t, entry.EndedAt.After(entry.StartedAt))
+	})
+
+	t.Run("StopWithoutTimer", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindRunning", userID).Return((*model.TimeEntry)(nil), gorm.ErrRecordNotFound)
+
+		_, err := uc.StopTimer(userID)
+		assert.ErrorIs(t, err, usecase.ErrNoRunningTimer)
+	})
+
+	t.Run("TaskNotVisible", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return((*taskModel.Task)(nil), gorm.ErrRecordNotFound)
+
+		_, err := uc.StartTimer(taskID, userID, "")
+		assert.ErrorIs(t, err, usecase.ErrTaskNotFound)
+	})
+}
+
+func TestEntryValidation(t *testing.T) {
+	userID := uint(100)
+	taskID := uint(1)
+	start := utc("2025-03-10T09:00:00Z")
+	end := utc("2025-03-10T10:30:00Z")
+
+	t.Run("AddsEntry", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindOverlapping", userID, start, ptr(end), uint(0)).Return(&[]model.TimeEntry{}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.TimeEntry")).Return(nil)
+
+		entry, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)})
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Minute, entry.EndedAt.Sub(entry.StartedAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("StoresUTC", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		berlin, _ := time.LoadLocation("Europe/Berlin")
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindOverlapping", userID, start, ptr(end), uint(0)).Return(&[]model.TimeEntry{}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.TimeEntry")).Return(nil)
+
+		entry, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start.In(berlin), EndedAt: ptr(end.In(berlin))})
+		assert.NoError(t, err)
+		assert.Equal(t, time.UTC, entry.StartedAt.Location())
+		assert.Equal(t, time.UTC, entry.EndedAt.Location())
+	})
+
+	t.Run("Overlapping", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindOverlapping", userID, start, ptr(end), uint(0)).
+			Return(&[]model.TimeEntry{{ID: 3, UserID: userID, StartedAt: start.Add(time.Hour), EndedAt: ptr(end.Add(time.Hour))}}, nil)
+
+		_, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)})
+		assert.ErrorIs(t, err, usecase.ErrOverlappingEntry)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("EndsBeforeStart", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+
+		_, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: end, EndedAt: ptr(start)})
+		assert.ErrorIs(t, err, usecase.ErrInvalidRange)
+		_, err = uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(start)})
+		assert.ErrorIs(t, err, usecase.ErrInvalidRange)
+	})
+
+	t.Run("InTheFuture", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		later := time.Now().Add(time.Hour)
+
+		_, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(later)})
+		assert.ErrorIs(t, err, usecase.ErrInvalidRange)
+	})
+
+	t.Run("UpdateIgnoresItself", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		entry := &model.TimeEntry{ID: 5, TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)}
+		later := end.Add(30 * time.Minute)
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return(entry, nil)
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindOverlapping", userID, start, ptr(later), uint(5)).Return(&[]model.TimeEntry{}, nil)
+		mockRepo.On("Update", entry).Return(nil)
+
+		updated, err := uc.UpdateEntry(5, userID, model.UpdateTimeEntryRequest{EndedAt: &later})
+		assert.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, updated.EndedAt.Sub(updated.StartedAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UpdateOverlapping", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		entry := &model.TimeEntry{ID: 5, TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)}
+		earlier := start.Add(-time.Hour)
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return(entry, nil)
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID}, nil)
+		mockRepo.On("FindOverlapping", userID, earlier, ptr(end), uint(5)).
+			Return(&[]model.TimeEntry{{ID: 4, UserID: userID, StartedAt: earlier.Add(-time.Hour), EndedAt: ptr(earlier.Add(time.Minute))}}, nil)
+
+		_, err := uc.UpdateEntry(5, userID, model.UpdateTimeEntryRequest{StartedAt: &earlier})
+		assert.ErrorIs(t, err, usecase.ErrOverlappingEntry)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("ViewerRefused", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+		workspaceID := uint(5)
+
+		mockRepo.On("FindTask", taskID, userID).Return(&taskModel.Task{ID: taskID, UserID: 200, WorkspaceID: &workspaceID}, nil)
+		mockRepo.On("FindRole", workspaceID, userID).Return(workspaceModel.RoleViewer, nil)
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return(&model.TimeEntry{ID: 5, TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)}, nil)
+
+		_, err := uc.AddEntry(model.TimeEntry{TaskID: taskID, UserID: userID, StartedAt: start, EndedAt: ptr(end)})
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		_, err = uc.StartTimer(taskID, userID, "")
+		assert.ErrorIs(t, err, usecase.ErrReadOnly)
+		assert.ErrorIs(t, uc.DeleteEntry(5, userID), usecase.ErrReadOnly)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("SomeoneElsesEntry", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindByIDAndUser", uint(5), userID).Return((*model.TimeEntry)(nil), gorm.ErrRecordNotFound)
+
+		err := uc.DeleteEntry(5, userID)
+		assert.ErrorIs(t, err, usecase.ErrEntryNotFound)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
+
+func TestReport(t *testing.T) {
+	userID := uint(100)
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	// 22:30 on 8 March to 01:30 on 9 March in New York, all on 9 March in UTC
+	lateNight := model.TimeEntry{ID: 1, TaskID: 1, UserID: userID, StartedAt: utc("2025-03-09T03:30:00Z"), EndedAt: ptr(utc("2025-03-09T06:30:00Z"))}
+
+	t.Run("DaysInUTC", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindInRange", userID, utc("2025-03-08T00:00:00Z"), utc("2025-03-10T00:00:00Z"), uint(0)).
+			Return(&[]model.TimeEntry{lateNight}, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-08", To: "2025-03-09", GroupBy: model.GroupByDay})
+		assert.NoError(t, err)
+		assert.Equal(t, "UTC", report.TZ)
+		assert.Equal(t, int64(3*3600), report.Seconds)
+		assert.Equal(t, []model.Total{{Key: "2025-03-09", Seconds: 3 * 3600}}, report.Totals)
+	})
+
+	t.Run("DaysInTimeZone", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		// Midnight in New York is 05:00 UTC while standard time lasts
+		mockRepo.On("FindInRange", userID, utc("2025-03-08T05:00:00Z"), utc("2025-03-10T04:00:00Z"), uint(0)).
+			Return(&[]model.TimeEntry{lateNight}, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-08", To: "2025-03-09", TZ: "America/New_York", GroupBy: model.GroupByDay})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3*3600), report.Seconds)
+		assert.Equal(t, []model.Total{
+			{Key: "2025-03-08", Seconds: 90 * 60},
+			{Key: "2025-03-09", Seconds: 90 * 60},
+		}, report.Totals)
+		resp := model.ToReportResponse(*report)
+		assert.Equal(t, "2025-03-08", resp.From)
+		assert.Equal(t, "2025-03-09", resp.To)
+	})
+
+	t.Run("ShortDayWhenClocksChange", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		// 9 March 2025 lasts 23 hours in New York, from 05:00 to 04:00 UTC
+		wholeDay := model.TimeEntry{ID: 2, TaskID: 1, UserID: userID, StartedAt: utc("2025-03-09T05:00:00Z"), EndedAt: ptr(utc("2025-03-10T04:00:00Z"))}
+		mockRepo.On("FindInRange", userID, utc("2025-03-09T05:00:00Z"), utc("2025-03-11T04:00:00Z"), uint(0)).
+			Return(&[]model.TimeEntry{wholeDay}, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-09", To: "2025-03-10", TZ: "America/New_York", GroupBy: model.GroupByDay})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Total{{Key: "2025-03-09", Seconds: 23 * 3600}}, report.Totals)
+	})
+
+	t.Run("ClipsToPeriod", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		mockRepo.On("FindInRange", userID, utc("2025-03-09T05:00:00Z"), utc("2025-03-10T04:00:00Z"), uint(0)).
+			Return(&[]model.TimeEntry{lateNight}, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-09", To: "2025-03-09", TZ: "America/New_York"})
+		assert.NoError(t, err)
+		assert.Equal(t, model.GroupByTask, report.GroupBy)
+		assert.Equal(t, int64(90*60), report.Seconds)
+	})
+
+	t.Run("ByLabel", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		labelled := &taskModel.Task{ID: 1, Title: "Write", Labels: []labelModel.Label{{ID: 1, Name: "work"}, {ID: 2, Name: "blog"}}}
+		bare := &taskModel.Task{ID: 2, Title: "Read"}
+		entries := []model.TimeEntry{
+			{ID: 1, TaskID: 1, Task: labelled, UserID: userID, StartedAt: utc("2025-03-10T09:00:00Z"), EndedAt: ptr(utc("2025-03-10T11:00:00Z"))},
+			{ID: 2, TaskID: 2, Task: bare, UserID: userID, StartedAt: utc("2025-03-10T11:00:00Z"), EndedAt: ptr(utc("2025-03-10T11:30:00Z"))},
+		}
+		mockRepo.On("FindInRange", userID, utc("2025-03-10T00:00:00Z"), utc("2025-03-11T00:00:00Z"), uint(0)).Return(&entries, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-10", To: "2025-03-10", GroupBy: model.GroupByLabel})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(150*60), report.Seconds)
+		assert.Equal(t, []model.Total{
+			{Key: "1", Name: "work", Seconds: 2 * 3600},
+			{Key: "2", Name: "blog", Seconds: 2 * 3600},
+			{Key: "none", Seconds: 30 * 60},
+		}, report.Totals)
+	})
+
+	t.Run("ByProject", func(t *testing.T) {
+		mockRepo := new(MockTimeEntryRepository)
+		uc := usecase.NewTimeEntryUsecase(mockRepo)
+
+		projectID := uint(4)
+		entries := []model.TimeEntry{
+			{ID: 1, TaskID: 1, Task: &taskModel.Task{ID: 1, ProjectID: &projectID}, UserID: userID, StartedAt: utc("2025-03-10T09:00:00Z"), EndedAt: ptr(utc("2025-03-10T10:00:00Z"))},
+			{ID: 2, TaskID: 2, Task: &taskModel.Task{ID: 2}, UserID: userID, StartedAt: utc("2025-03-10T10:00:00Z"), EndedAt: ptr(utc("2025-03-10T12:00:00Z"))},
+		}
+		mockRepo.On("FindInRange", userID, utc("2025-03-10T00:00:00Z"), utc("2025-03-11T00:00:00Z"), uint(0)).Return(&entries, nil)
+		mockRepo.On("FindProjectNames", []uint{4}).Return(map[uint]string{4: "Website"}, nil)
+
+		report, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-10", To: "2025-03-10", GroupBy: model.GroupByProject})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Total{
+			{Key: "none", Seconds: 2 * 3600},
+			{Key: "4", Name: "Website", Seconds: 3600},
+		}, report.Totals)
+	})
+
+	t.Run("InvalidTimezone", func(t *testing.T) {
+		uc := usecase.NewTimeEntryUsecase(new(MockTimeEntryRepository))
+
+		_, err := uc.GetReport(userID, model.ReportQuery{TZ: "Mars/Olympus_Mons"})
+		assert.ErrorIs(t, err, usecase.ErrInvalidTimezone)
+	})
+
+	t.Run("InvalidPeriod", func(t *testing.T) {
+		uc := usecase.NewTimeEntryUsecase(new(MockTimeEntryRepository))
+
+		_, err := uc.GetReport(userID, model.ReportQuery{From: "2025-03-10", To: "2025-03-09"})
+		assert.ErrorIs(t, err, usecase.ErrInvalidPeriod)
+		_, err = uc.GetReport(userID, model.ReportQuery{From: "2024-01-01", To: "2025-01-01"})
+		assert.ErrorIs(t, err, usecase.ErrInvalidPeriod)
+	})
+}
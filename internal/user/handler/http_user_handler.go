@@ -33,6 +33,17 @@ func NewUserHandler(app *fiber.App, usecase usecase.UserUsecase, token auth.Toke
 	user.Delete("/", handler.DeleteUser)
 }
 
+// CurrentRole looks up the role a user holds now, for middleware.RequireRole
+func CurrentRole(uc usecase.UserUsecase) middleware.RoleLookup {
+	return func(userID uint) (string, error) {
+		user, err := uc.Profile(userID)
+		if err != nil {
+			return "", err
+		}
+		return user.Role, nil
+	}
+}
+
 func (h *HttpUserhandler) Register(c *fiber.Ctx) error {
 	var input model.RegisterRequest
 	if err := c.BodyParser(&input); err != nil {
@@ -100,9 +111,10 @@ func (h *HttpUserhandler) Updateuser(c *fiber.Ctx) error {
 	}
 
 	user := model.User{
-		ID:    userID,
-		Name:  input.Name,
-		Email: input.Email,
+		ID:     userID,
+		Name:   input.Name,
+		Email:  input.Email,
+		Handle: model.ToHandle(input.Handle),
 	}
 
 	if err := h.usecase.UpdateUser(user); err != nil {
@@ -1,26 +1,41 @@
 package model
 
+import "strings"
+
 // Map request register
 func ToUserModel(r RegisterRequest) User {
 	return User{
 		Name:     r.Name,
 		Email:    r.Email,
 		Password: r.Password, 
+		Handle:   ToHandle(r.Handle),
+	}
+}
+
+// ToHandle normalises a requested handle, handles compare case-insensitively
+func ToHandle(handle string) *string {
+	if handle == "" {
+		return nil
 	}
+	h := strings.ToLower(handle)
+	return &h
 }
 
 func ToUserResponse(u User) UserResponse {
 	return UserResponse{
-		ID:    u.ID,
-		Name:  u.Name,
-		Email: u.Email,
+		ID:     u.ID,
+		Name:   u.Name,
+		Email:  u.Email,
+		Handle: u.Handle,
 	}
 }
 
 func ToUserProfileResponse(u User) UserProfileResponse {
 	return UserProfileResponse{
-		Name:  u.Name,
-		Email: u.Email,
+		Name:   u.Name,
+		Email:  u.Email,
+		Handle: u.Handle,
+		Role:   u.Role,
 	}
 }
 
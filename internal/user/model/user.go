@@ -11,7 +11,9 @@ type User struct {
 	ID        uint       `gorm:"primaryKey"`
 	Name      string     `gorm:"not null" validate:"required"`
 	Email     string     `gorm:"unique;not null " validate:"required,email"`
+	Handle    *string    `gorm:"uniqueIndex;default:null"` // used to @mention the user
 	Password  string     `gorm:"not null" validate:"required,main=6"`
+	Role      string     `gorm:"type:varchar(10);not null;default:'user'"` // auth.RoleUser or auth.RoleAdmin
 	CreatedAt time.Time  
 	UpdatedAt time.Time  
 	DeletedAt gorm.DeletedAt `gorm:"index"`  
@@ -21,6 +23,7 @@ type User struct {
 type RegisterRequest struct {
 	Name     string `json:"name" example:"John Doe" validate:"required"`
 	Email    string `json:"email" example:"john@example.com" validate:"required,email"`
+	Handle   string `json:"handle,omitempty" example:"john" validate:"omitempty,alphanum,min=3,max=30"`
 	Password string `json:"password" example:"12345678" validate:"required,min=6"`
 }
 
@@ -32,20 +35,24 @@ type LoginRequest struct {
 
 // Response model
 type UserResponse struct {
-	ID    uint   `json:"id" example:"1"`
-	Name  string `json:"name" example:"John Doe"`
-	Email string `json:"email" example:"john@example.com"`
+	ID     uint    `json:"id" example:"1"`
+	Name   string  `json:"name" example:"John Doe"`
+	Email  string  `json:"email" example:"john@example.com"`
+	Handle *string `json:"handle,omitempty" example:"john"`
 }
 
 type UserProfileResponse struct {
-	Name  string `json:"name" example:"John Doe"`
-	Email string `json:"email" example:"john@example.com"`
+	Name   string  `json:"name" example:"John Doe"`
+	Email  string  `json:"email" example:"john@example.com"`
+	Handle *string `json:"handle,omitempty" example:"john"`
+	Role   string  `json:"role" example:"user"`
 }
 
 // Update model 
 type UpdateUserRequest struct {
-	Name  string `json:"name,omitempty"`  
-	Email string `json:"email,omitempty"`
+	Name   string `json:"name,omitempty"`  
+	Email  string `json:"email,omitempty"`
+	Handle string `json:"handle,omitempty" validate:"omitempty,alphanum,min=3,max=30"`
 }
 
 
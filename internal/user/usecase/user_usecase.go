@@ -96,7 +96,7 @@ func (uc *UserusecaseImpl) Login(email, password string) (string, error) {
 	}
 
 	// Generate Token
-	token, err := uc.token.GenerateToken(user.ID)
+	token, err := uc.token.GenerateToken(user.ID, user.Role)
 
 	if err != nil {
 		logger.Log.Error("Token generation failed : ", err)
@@ -155,6 +155,9 @@ func (uc *UserusecaseImpl) UpdateUser(user model.User) error {
 
 	exitUser.Name = user.Name
 	exitUser.Email = user.Email
+	if user.Handle != nil {
+		exitUser.Handle = user.Handle
+	}
 
 	if err := uc.repo.Update(*exitUser); err != nil {
 		logger.Log.Error("Update failed : ", err)
@@ -100,13 +100,17 @@ type MockTokenService struct {
     ErrToReturn   error
 }
 
-func (m *MockTokenService) GenerateToken(userID uint) (string, error) {
+func (m *MockTokenService) GenerateToken(userID uint, role string) (string, error) {
     if m.ErrToReturn != nil {
         return "", m.ErrToReturn
     }
     return m.TokenToReturn, nil
 }
 
+func (m *MockTokenService) GenerateWorkspaceToken(userID uint, role string, workspaceID uint) (string, error) {
+    return m.GenerateToken(userID, role)
+}
+
 func (m *MockTokenService) VerifyToken(token string) (*jwt.Token, error) {
     if token == "validtoken" {
         return nil, nil
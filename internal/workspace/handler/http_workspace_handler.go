@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"errors"
+	"mymodule/internal/workspace/model"
+	"mymodule/internal/workspace/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/helper"
+	"mymodule/pkg/logger"
+	"mymodule/pkg/middleware"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpWorkspacehandler struct {
+	usecase usecase.WorkspaceUsecase
+	token   auth.TokenService
+	valid   *validator.Validate
+}
+
+func NewWorkspaceHandler(app *fiber.App, usecase usecase.WorkspaceUsecase, token auth.TokenService, valid *validator.Validate) {
+	handler := &HttpWorkspacehandler{
+		usecase: usecase,
+		token:   token,
+		valid:   valid,
+	}
+	workspace := app.Group("/workspace", middleware.Middleware(token))
+	workspace.Post("/", handler.Create)
+	workspace.Get("/", handler.GetWorkspacesByUser)
+	workspace.Post("/invitations/:token/accept", handler.AcceptInvitation)
+	workspace.Post("/:id/switch", handler.Switch)
+	workspace.Get("/:id/members", handler.GetMembers)
+	workspace.Put("/:id/members/:userId", handler.UpdateMember)
+	workspace.Delete("/:id/members/:userId", handler.RemoveMember)
+	workspace.Post("/:id/invitations", handler.Invite)
+	workspace.Get("/:id/invitations", handler.GetInvitations)
+	workspace.Delete("/:id/invitations/:invitationId", handler.RevokeInvitation)
+}
+
+// ActiveWorkspace resolves the workspace a request works in, from the token or the
+// X-Workspace-ID header and else the user's personal one, and stores it with the
+// user's role in the context. It must run after middleware.Middleware.
+func ActiveWorkspace(uc usecase.WorkspaceUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, err := helper.GetUserIDFromContext(c)
+		if err != nil {
+			logger.Log.Error("Unauthorized access: ", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		requested, err := helper.GetWorkspaceIDFromContext(c)
+		if err != nil {
+			requested = 0
+		}
+
+		member, err := uc.Resolve(requested, userID)
+		if err != nil {
+			return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Locals("workspaceID", member.WorkspaceID)
+		c.Locals("workspaceRole", member.Role)
+		return c.Next()
+	}
+}
+
+func (h *HttpWorkspacehandler) Create(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var input model.CreateWorkspaceRequest
+	if err := c.BodyParser(&input); err != nil {
+		logger.Log.Error("Invalid workspace request : ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	workspace, err := h.usecase.Create(model.ToWorkspace(input, userID))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.WorkspaceResponse{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+		Role: model.RoleOwner,
+	})
+}
+
+// GetWorkspacesByUser lists the workspaces the user belongs to with their role in each
+func (h *HttpWorkspacehandler) GetWorkspacesByUser(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	memberships, err := h.usecase.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch workspaces"})
+	}
+	return c.JSON(model.ToWorkspaceResponseList(*memberships))
+}
+
+// Switch makes the workspace the active one by issuing a new token, set as the
+// cookie like on login
+func (h *HttpWorkspacehandler) Switch(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+
+	token, err := h.usecase.Switch(uint(workspaceID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    token,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/",
+		MaxAge:   3600, // 1 Hours
+	})
+	return c.JSON(fiber.Map{"token": token})
+}
+
+func (h *HttpWorkspacehandler) GetMembers(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+
+	members, err := h.usecase.GetMembers(uint(workspaceID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToMemberResponseList(*members))
+}
+
+func (h *HttpWorkspacehandler) UpdateMember(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+	memberID, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid user ID"})
+	}
+
+	var input model.UpdateMemberRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.usecase.UpdateMemberRole(uint(workspaceID), userID, uint(memberID), input.Role); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "member updated"})
+}
+
+// RemoveMember removes a member, or lets the user leave when it is their own ID
+func (h *HttpWorkspacehandler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+	memberID, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid user ID"})
+	}
+
+	if err := h.usecase.RemoveMember(uint(workspaceID), userID, uint(memberID)); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "member removed"})
+}
+
+func (h *HttpWorkspacehandler) Invite(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+
+	var input model.InviteRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.valid.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	invitation, token, err := h.usecase.Invite(uint(workspaceID), userID, input.Email, input.Role)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(model.ToInvitationResponse(*invitation, token))
+}
+
+func (h *HttpWorkspacehandler) GetInvitations(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+
+	invitations, err := h.usecase.GetInvitations(uint(workspaceID), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model.ToInvitationResponseList(*invitations))
+}
+
+func (h *HttpWorkspacehandler) RevokeInvitation(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid workspace ID"})
+	}
+	invitationID, err := strconv.Atoi(c.Params("invitationId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invitation ID"})
+	}
+
+	if err := h.usecase.RevokeInvitation(uint(workspaceID), userID, uint(invitationID)); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "invitation revoked"})
+}
+
+func (h *HttpWorkspacehandler) AcceptInvitation(c *fiber.Ctx) error {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		logger.Log.Error("Unauthorized access: ", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	member, err := h.usecase.AcceptInvitation(c.Params("token"), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"workspace_id": member.WorkspaceID, "role": member.Role})
+}
+
+// errorStatus maps the usecase's known errors to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrMemberNotFound), errors.Is(err, usecase.ErrInvitationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrNotMember), errors.Is(err, usecase.ErrForbidden), errors.Is(err, usecase.ErrInvitationEmail):
+		return fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrOwnerProtected), errors.Is(err, usecase.ErrAlreadyMember), errors.Is(err, usecase.ErrPersonalWorkspace):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrInvitationExpired):
+		return fiber.StatusGone
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+func ToWorkspace(req CreateWorkspaceRequest, userID uint) Workspace {
+	return Workspace{
+		Name:    req.Name,
+		OwnerID: userID,
+	}
+}
+
+// ToWorkspaceResponse needs the member's Workspace preloaded
+func ToWorkspaceResponse(m Member) WorkspaceResponse {
+	res := WorkspaceResponse{Role: m.Role}
+	if m.Workspace != nil {
+		res.ID = m.Workspace.ID
+		res.Name = m.Workspace.Name
+		res.Personal = m.Workspace.Personal
+	}
+	return res
+}
+
+func ToWorkspaceResponseList(members []Member) []WorkspaceResponse {
+	res := make([]WorkspaceResponse, 0, len(members))
+	for _, m := range members {
+		res = append(res, ToWorkspaceResponse(m))
+	}
+	return res
+}
+
+// ToMemberResponse needs the member's User preloaded
+func ToMemberResponse(m Member) MemberResponse {
+	res := MemberResponse{
+		UserID:   m.UserID,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+	if m.User != nil {
+		res.Name = m.User.Name
+		res.Email = m.User.Email
+	}
+	return res
+}
+
+func ToMemberResponseList(members []Member) []MemberResponse {
+	res := make([]MemberResponse, 0, len(members))
+	for _, m := range members {
+		res = append(res, ToMemberResponse(m))
+	}
+	return res
+}
+
+func ToInvitationResponse(inv Invitation, token string) InvitationResponse {
+	return InvitationResponse{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		ExpiresAt: inv.ExpiresAt,
+		Token:     token,
+	}
+}
+
+func ToInvitationResponseList(invitations []Invitation) []InvitationResponse {
+	res := make([]InvitationResponse, 0, len(invitations))
+	for _, inv := range invitations {
+		res = append(res, ToInvitationResponse(inv, ""))
+	}
+	return res
+}
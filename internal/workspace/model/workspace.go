@@ -0,0 +1,121 @@
+package model
+
+import (
+	userModel "mymodule/internal/user/model"
+	"time"
+)
+
+// PersonalName is the workspace every user gets for the tasks they keep to themselves
+const PersonalName = "Personal"
+
+// Workspace is the DB model for a team sharing its tasks
+type Workspace struct {
+	ID        uint      `gorm:"primaryKey" json:"id" example:"1"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name" example:"Marketing"`
+	OwnerID   uint      `gorm:"not null;index" json:"owner_id" example:"1"`
+	Personal  bool      `gorm:"not null;default:false" json:"personal" example:"false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Roles of a workspace member, see RoleRanks for what each may do
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// RoleRanks orders the roles, each one can do everything the ones below it can:
+// viewers only read, members work on tasks, admins manage the members below them
+// and every task, the owner also manages admins
+var RoleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// AtLeast reports whether role ranks as high as min, unknown roles rank lowest
+func AtLeast(role, min string) bool {
+	return RoleRanks[role] > 0 && RoleRanks[role] >= RoleRanks[min]
+}
+
+// Member is the DB model for a user's role in a workspace
+type Member struct {
+	WorkspaceID uint            `gorm:"primaryKey" json:"workspace_id" example:"1"`
+	UserID      uint            `gorm:"primaryKey;index" json:"user_id" example:"2"`
+	Role        string          `gorm:"type:varchar(10);not null" json:"role" example:"member"`
+	Workspace   *Workspace      `gorm:"foreignKey:WorkspaceID" json:"-"`
+	User        *userModel.User `gorm:"foreignKey:UserID" json:"-"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func (Member) TableName() string {
+	return "workspace_members"
+}
+
+// InvitationTTL is how long an invitation can be accepted
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Invitation is the DB model for an offer to join a workspace, only the hash of
+// its token is stored
+type Invitation struct {
+	ID          uint       `gorm:"primaryKey" json:"id" example:"1"`
+	WorkspaceID uint       `gorm:"not null;index" json:"workspace_id" example:"1"`
+	Email       string     `gorm:"type:varchar(255);not null" json:"email" example:"jane@example.com"`
+	Role        string     `gorm:"type:varchar(10);not null" json:"role" example:"member"`
+	TokenHash   string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	InvitedBy   uint       `gorm:"not null" json:"invited_by" example:"1"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `gorm:"default:null" json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Invitation) TableName() string {
+	return "workspace_invitations"
+}
+
+// CreateWorkspaceRequest is the request model for creating a workspace
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" example:"Marketing" validate:"required,max=100"`
+}
+
+// InviteRequest is the request model for inviting someone to a workspace
+type InviteRequest struct {
+	Email string `json:"email" example:"jane@example.com" validate:"required,email"`
+	Role  string `json:"role" example:"member" validate:"required,oneof=admin member viewer"`
+}
+
+// UpdateMemberRequest is the request model for changing a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" example:"viewer" validate:"required,oneof=admin member viewer"`
+}
+
+// WorkspaceResponse is the response model for a workspace the user belongs to
+type WorkspaceResponse struct {
+	ID       uint   `json:"id" example:"1"`
+	Name     string `json:"name" example:"Marketing"`
+	Personal bool   `json:"personal" example:"false"`
+	Role     string `json:"role" example:"owner"`
+}
+
+// MemberResponse is the response model for a workspace member
+type MemberResponse struct {
+	UserID   uint      `json:"user_id" example:"2"`
+	Name     string    `json:"name" example:"Jane Doe"`
+	Email    string    `json:"email" example:"jane@example.com"`
+	Role     string    `json:"role" example:"member"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// InvitationResponse is the response model for an invitation, the token is only
+// shown once when the invitation is created
+type InvitationResponse struct {
+	ID        uint      `json:"id" example:"1"`
+	Email     string    `json:"email" example:"jane@example.com"`
+	Role      string    `json:"role" example:"member"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token,omitempty" example:"3f5c0e..."`
+}
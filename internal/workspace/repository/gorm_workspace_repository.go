@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"errors"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	"mymodule/internal/workspace/model"
+	"mymodule/internal/workspace/usecase"
+	"mymodule/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormWorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWorkspaceRepository(db *gorm.DB) usecase.WorkspaceRepository {
+	return &GormWorkspaceRepository{db: db}
+}
+
+// Create saves the workspace together with its owner's membership
+func (r *GormWorkspaceRepository) Create(workspace *model.Workspace) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		owner := model.Member{WorkspaceID: workspace.ID, UserID: workspace.OwnerID, Role: model.RoleOwner}
+		return tx.Create(&owner).Error
+	})
+	if err != nil {
+		logger.Log.WithField("userID", workspace.OwnerID).Error("Failed to save workspace")
+		return err
+	}
+	logger.Log.WithField("workspaceID", workspace.ID).Info("Workspace saved successfully")
+	return nil
+}
+
+// FindPersonal returns nil without an error when the user has no personal workspace yet
+func (r *GormWorkspaceRepository) FindPersonal(userID uint) (*model.Workspace, error) {
+	var workspace model.Workspace
+	result := r.db.Where("owner_id = ? AND personal = ?", userID, true).First(&workspace)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		logger.Log.WithField("userID", userID).Error("Database error finding personal workspace")
+		return nil, result.Error
+	}
+	return &workspace, nil
+}
+
+// FindMemberships lists the user's workspaces, personal one first
+func (r *GormWorkspaceRepository) FindMemberships(userID uint) (*[]model.Member, error) {
+	var members []model.Member
+	err := r.db.Joins("Workspace").
+		Where("workspace_members.user_id = ?", userID).
+		Order(`"Workspace".personal DESC, "Workspace".name`).
+		Find(&members).Error
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to find workspaces by user ID")
+		return nil, err
+	}
+	return &members, nil
+}
+
+func (r *GormWorkspaceRepository) FindMember(workspaceID, userID uint) (*model.Member, error) {
+	var member model.Member
+	err := r.db.Joins("Workspace").
+		Where("workspace_members.workspace_id = ? AND workspace_members.user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMembers lists the members by rank, then by when they joined
+func (r *GormWorkspaceRepository) FindMembers(workspaceID uint) (*[]model.Member, error) {
+	var members []model.Member
+	err := r.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name", "email")
+	}).
+		Where("workspace_id = ?", workspaceID).
+		Order(`CASE role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 WHEN 'member' THEN 2 ELSE 3 END, created_at`).
+		Find(&members).Error
+	if err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find workspace members")
+		return nil, err
+	}
+	return &members, nil
+}
+
+func (r *GormWorkspaceRepository) UpdateMember(member *model.Member) error {
+	err := r.db.Model(&model.Member{}).
+		Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+		Updates(map[string]interface{}{"role": member.Role, "updated_at": time.Now()}).Error
+	if err != nil {
+		logger.Log.WithField("workspaceID", member.WorkspaceID).Error("Failed to update member")
+		return err
+	}
+	return nil
+}
+
+// DeleteMember also unassigns the workspace's tasks from the member
+func (r *GormWorkspaceRepository) DeleteMember(workspaceID, userID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&taskModel.Task{}).
+			Where("workspace_id = ? AND assignee_id = ?", workspaceID, userID).
+			Update("assignee_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&model.Member{}).Error
+	})
+	if err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to delete member")
+		return err
+	}
+	return nil
+}
+
+func (r *GormWorkspaceRepository) FindUser(userID uint) (*userModel.User, error) {
+	var user userModel.User
+	if err := r.db.Select("id", "email", "role").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *GormWorkspaceRepository) CreateInvitation(invitation *model.Invitation) error {
+	if err := r.db.Create(invitation).Error; err != nil {
+		logger.Log.WithField("workspaceID", invitation.WorkspaceID).Error("Failed to save invitation")
+		return err
+	}
+	return nil
+}
+
+func (r *GormWorkspaceRepository) FindPendingInvitations(workspaceID uint, now time.Time) (*[]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, now).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to find invitations")
+		return nil, err
+	}
+	return &invitations, nil
+}
+
+func (r *GormWorkspaceRepository) FindInvitation(invitationID, workspaceID uint) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := r.db.Where("id = ? AND workspace_id = ?", invitationID, workspaceID).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *GormWorkspaceRepository) FindInvitationByToken(tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *GormWorkspaceRepository) DeleteInvitation(invitationID uint) error {
+	if err := r.db.Delete(&model.Invitation{}, invitationID).Error; err != nil {
+		logger.Log.WithField("invitationID", invitationID).Error("Failed to delete invitation")
+		return err
+	}
+	return nil
+}
+
+// AcceptInvitation marks the invitation used and adds the member at once, an
+// invitation accepted concurrently is only used once
+func (r *GormWorkspaceRepository) AcceptInvitation(invitation *model.Invitation, member *model.Member) error {
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return usecase.ErrInvitationNotFound
+		}
+		return tx.Create(member).Error
+	})
+	if err != nil {
+		logger.Log.WithField("invitationID", invitation.ID).Error("Failed to accept invitation")
+		return err
+	}
+	invitation.AcceptedAt = &now
+	return nil
+}
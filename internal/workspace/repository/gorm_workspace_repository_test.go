@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"log"
+	taskModel "mymodule/internal/task/model"
+	userModel "mymodule/internal/user/model"
+	"mymodule/internal/workspace/model"
+	"mymodule/internal/workspace/repository"
+	"mymodule/internal/workspace/usecase"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&userModel.User{}, &model.Workspace{}, &model.Member{}, &model.Invitation{}, &taskModel.Task{}); err != nil {
+		log.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestMemberships(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewGormWorkspaceRepository(db)
+
+	for _, u := range []userModel.User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Password: "x"},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Password: "x"},
+	} {
+		if err := db.Create(&u).Error; err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+
+	team := model.Workspace{Name: "Team", OwnerID: 1}
+	personal := model.Workspace{Name: model.PersonalName, OwnerID: 1, Personal: true}
+	for _, w := range []*model.Workspace{&team, &personal} {
+		if err := repo.Create(w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	owner, err := repo.FindMember(team.ID, 1)
+	if err != nil || owner.Role != model.RoleOwner || owner.Workspace == nil || owner.Workspace.Name != "Team" {
+		t.Fatalf("expected the creator to own the workspace, got %+v %v", owner, err)
+	}
+
+	memberships, err := repo.FindMemberships(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*memberships) != 2 || !(*memberships)[0].Workspace.Personal {
+		t.Fatalf("expected the personal workspace first, got %+v", *memberships)
+	}
+
+	found, err := repo.FindPersonal(1)
+	if err != nil || found == nil || found.ID != personal.ID {
+		t.Fatalf("expected the personal workspace, got %+v %v", found, err)
+	}
+	if found, err := repo.FindPersonal(2); err != nil || found != nil {
+		t.Fatalf("expected no personal workspace yet, got %+v %v", found, err)
+	}
+
+	invitation := model.Invitation{WorkspaceID: team.ID, Email: "bob@example.com", Role: model.RoleMember, TokenHash: "hash", InvitedBy: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.CreateInvitation(&invitation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.AcceptInvitation(&invitation, &model.Member{WorkspaceID: team.ID, UserID: 2, Role: model.RoleMember}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.AcceptInvitation(&invitation, &model.Member{WorkspaceID: team.ID, UserID: 2, Role: model.RoleMember}); err != usecase.ErrInvitationNotFound {
+		t.Fatalf("expected an invitation to be used once, got %v", err)
+	}
+	pending, _ := repo.FindPendingInvitations(team.ID, time.Now())
+	if len(*pending) != 0 {
+		t.Fatalf("expected no pending invitations, got %+v", *pending)
+	}
+
+	members, err := repo.FindMembers(team.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*members) != 2 || (*members)[0].Role != model.RoleOwner || (*members)[1].User == nil || (*members)[1].User.Name != "Bob" {
+		t.Fatalf("expected owner then Bob, got %+v", *members)
+	}
+
+	// Removing a member hands their tasks in the workspace back
+	bob := uint(2)
+	task := taskModel.Task{Title: "Assigned", UserID: 1, AssigneeID: &bob, WorkspaceID: &team.ID, Status: "pending", Priority: taskModel.PriorityNone}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("failed to seed task: %v", err)
+	}
+	if err := repo.DeleteMember(team.ID, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindMember(team.ID, 2); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected the member to be gone, got %v", err)
+	}
+	var reloaded taskModel.Task
+	db.First(&reloaded, task.ID)
+	if reloaded.AssigneeID != nil {
+		t.Fatalf("expected the task to be unassigned, got %v", *reloaded.AssigneeID)
+	}
+}
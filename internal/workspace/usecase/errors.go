@@ -0,0 +1,17 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrNotMember         = errors.New("you are not a member of this workspace")
+	ErrForbidden         = errors.New("your role in this workspace does not allow this")
+	ErrPersonalWorkspace = errors.New("a personal workspace can not be shared")
+
+	ErrMemberNotFound = errors.New("member not found")
+	ErrOwnerProtected = errors.New("the workspace owner can not be changed or removed")
+	ErrAlreadyMember  = errors.New("already a member of this workspace")
+
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrInvitationEmail    = errors.New("invitation was sent to another email address")
+)
@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	userModel "mymodule/internal/user/model"
+	"mymodule/internal/workspace/model"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/logger"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository interface {
+	Create(workspace *model.Workspace) error // also makes the owner a member
+	FindPersonal(userID uint) (*model.Workspace, error)
+	FindMemberships(userID uint) (*[]model.Member, error)
+	FindMember(workspaceID, userID uint) (*model.Member, error)
+	FindMembers(workspaceID uint) (*[]model.Member, error)
+	UpdateMember(member *model.Member) error
+	DeleteMember(workspaceID, userID uint) error
+	FindUser(userID uint) (*userModel.User, error)
+	CreateInvitation(invitation *model.Invitation) error
+	FindPendingInvitations(workspaceID uint, now time.Time) (*[]model.Invitation, error)
+	FindInvitation(invitationID, workspaceID uint) (*model.Invitation, error)
+	FindInvitationByToken(tokenHash string) (*model.Invitation, error)
+	DeleteInvitation(invitationID uint) error
+	AcceptInvitation(invitation *model.Invitation, member *model.Member) error
+}
+
+type WorkspaceUsecase interface {
+	Create(workspace model.Workspace) (*model.Workspace, error)
+	GetByUser(userID uint) (*[]model.Member, error)
+	Resolve(workspaceID, userID uint) (*model.Member, error)
+	Switch(workspaceID, userID uint) (string, error)
+	GetMembers(workspaceID, userID uint) (*[]model.Member, error)
+	UpdateMemberRole(workspaceID, userID, memberID uint, role string) error
+	RemoveMember(workspaceID, userID, memberID uint) error
+	Invite(workspaceID, userID uint, email, role string) (*model.Invitation, string, error)
+	GetInvitations(workspaceID, userID uint) (*[]model.Invitation, error)
+	RevokeInvitation(workspaceID, userID, invitationID uint) error
+	AcceptInvitation(token string, userID uint) (*model.Member, error)
+}
+
+type WorkspaceusecaseImpl struct {
+	repo  WorkspaceRepository
+	token auth.TokenService
+}
+
+func NewWorkspaceUsecase(repo WorkspaceRepository, token auth.TokenService) WorkspaceUsecase {
+	return &WorkspaceusecaseImpl{
+		repo:  repo,
+		token: token,
+	}
+}
+
+func (uc *WorkspaceusecaseImpl) Create(workspace model.Workspace) (*model.Workspace, error) {
+	workspace.Personal = false
+	if err := uc.repo.Create(&workspace); err != nil {
+		logger.Log.WithField("userID", workspace.OwnerID).Error("Failed to create workspace")
+		return nil, err
+	}
+
+	logger.Log.WithField("workspaceID", workspace.ID).Info("Workspace created successfully")
+	return &workspace, nil
+}
+
+func (uc *WorkspaceusecaseImpl) GetByUser(userID uint) (*[]model.Member, error) {
+	if _, err := uc.Resolve(0, userID); err != nil {
+		return nil, err
+	}
+	memberships, err := uc.repo.FindMemberships(userID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to get workspaces by user")
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// Resolve returns the user's membership of the workspace, 0 stands for their
+// personal workspace which is created on first use
+func (uc *WorkspaceusecaseImpl) Resolve(workspaceID, userID uint) (*model.Member, error) {
+	if workspaceID == 0 {
+		personal, err := uc.personal(userID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Member{WorkspaceID: personal.ID, UserID: userID, Role: model.RoleOwner, Workspace: personal}, nil
+	}
+
+	member, err := uc.repo.FindMember(workspaceID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// Switch issues a token that makes the workspace the active one
+func (uc *WorkspaceusecaseImpl) Switch(workspaceID, userID uint) (string, error) {
+	member, err := uc.Resolve(workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := uc.repo.FindUser(userID)
+	if err != nil {
+		return "", err
+	}
+	token, err := uc.token.GenerateWorkspaceToken(userID, user.Role, member.WorkspaceID)
+	if err != nil {
+		logger.Log.WithField("userID", userID).Error("Failed to generate workspace token")
+		return "", err
+	}
+	return token, nil
+}
+
+func (uc *WorkspaceusecaseImpl) GetMembers(workspaceID, userID uint) (*[]model.Member, error) {
+	if _, err := uc.Resolve(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	members, err := uc.repo.FindMembers(workspaceID)
+	if err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to get workspace members")
+		return nil, err
+	}
+	return members, nil
+}
+
+func (uc *WorkspaceusecaseImpl) UpdateMemberRole(workspaceID, userID, memberID uint, role string) error {
+	actor, member, err := uc.manage(workspaceID, userID, memberID)
+	if err != nil {
+		return err
+	}
+	if !canManage(actor.Role, role) {
+		return ErrForbidden
+	}
+
+	member.Role = role
+	if err := uc.repo.UpdateMember(member); err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to update member role")
+		return err
+	}
+
+	logger.Log.WithFields(map[string]interface{}{"workspaceID": workspaceID, "memberID": memberID}).Info("Member role updated")
+	return nil
+}
+
+// RemoveMember takes a member out of the workspace, anyone but the owner may
+// also remove themselves to leave it
+func (uc *WorkspaceusecaseImpl) RemoveMember(workspaceID, userID, memberID uint) error {
+	if memberID == userID {
+		member, err := uc.Resolve(workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == model.RoleOwner {
+			return ErrOwnerProtected
+		}
+	} else if _, _, err := uc.manage(workspaceID, userID, memberID); err != nil {
+		return err
+	}
+
+	if err := uc.repo.DeleteMember(workspaceID, memberID); err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to remove member")
+		return err
+	}
+
+	logger.Log.WithFields(map[string]interface{}{"workspaceID": workspaceID, "memberID": memberID}).Info("Member removed")
+	return nil
+}
+
+// Invite returns the invitation with its token, which is not stored and can not
+// be shown again
+func (uc *WorkspaceusecaseImpl) Invite(workspaceID, userID uint, email, role string) (*model.Invitation, string, error) {
+	actor, err := uc.requireAdmin(workspaceID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if actor.Workspace != nil && actor.Workspace.Personal {
+		return nil, "", ErrPersonalWorkspace
+	}
+	if !canManage(actor.Role, role) {
+		return nil, "", ErrForbidden
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+	invitation := model.Invitation{
+		WorkspaceID: workspaceID,
+		Email:       strings.ToLower(strings.TrimSpace(email)),
+		Role:        role,
+		TokenHash:   hashToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().Add(model.InvitationTTL),
+	}
+	if err := uc.repo.CreateInvitation(&invitation); err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to create invitation")
+		return nil, "", err
+	}
+
+	logger.Log.WithField("workspaceID", workspaceID).Info("Invitation created")
+	return &invitation, token, nil
+}
+
+// GetInvitations lists the invitations that can still be accepted
+func (uc *WorkspaceusecaseImpl) GetInvitations(workspaceID, userID uint) (*[]model.Invitation, error) {
+	if _, err := uc.requireAdmin(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	invitations, err := uc.repo.FindPendingInvitations(workspaceID, time.Now())
+	if err != nil {
+		logger.Log.WithField("workspaceID", workspaceID).Error("Failed to get invitations")
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (uc *WorkspaceusecaseImpl) RevokeInvitation(workspaceID, userID, invitationID uint) error {
+	actor, err := uc.requireAdmin(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	invitation, err := uc.repo.FindInvitation(invitationID, workspaceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvitationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !canManage(actor.Role, invitation.Role) {
+		return ErrForbidden
+	}
+
+	if err := uc.repo.DeleteInvitation(invitation.ID); err != nil {
+		logger.Log.WithField("invitationID", invitationID).Error("Failed to revoke invitation")
+		return err
+	}
+
+	logger.Log.WithField("invitationID", invitationID).Info("Invitation revoked")
+	return nil
+}
+
+// AcceptInvitation makes the user a member, the invitation must have been sent
+// to their email address
+func (uc *WorkspaceusecaseImpl) AcceptInvitation(token string, userID uint) (*model.Member, error) {
+	invitation, err := uc.repo.FindInvitationByToken(hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, ErrInvitationNotFound
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+
+	user, err := uc.repo.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmail
+	}
+
+	if _, err := uc.repo.FindMember(invitation.WorkspaceID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	member := model.Member{WorkspaceID: invitation.WorkspaceID, UserID: userID, Role: invitation.Role}
+	if err := uc.repo.AcceptInvitation(invitation, &member); err != nil {
+		logger.Log.WithField("invitationID", invitation.ID).Error("Failed to accept invitation")
+		return nil, err
+	}
+
+	logger.Log.WithFields(map[string]interface{}{"workspaceID": invitation.WorkspaceID, "userID": userID}).Info("Invitation accepted")
+	return &member, nil
+}
+
+// personal finds or creates the user's personal workspace, a concurrent request
+// creating it first is picked up from the database
+func (uc *WorkspaceusecaseImpl) personal(userID uint) (*model.Workspace, error) {
+	workspace, err := uc.repo.FindPersonal(userID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace != nil {
+		return workspace, nil
+	}
+
+	workspace = &model.Workspace{Name: model.PersonalName, OwnerID: userID, Personal: true}
+	if err := uc.repo.Create(workspace); err != nil {
+		existing, findErr := uc.repo.FindPersonal(userID)
+		if findErr == nil && existing != nil {
+			return existing, nil
+		}
+		logger.Log.WithField("userID", userID).Error("Failed to create personal workspace")
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (uc *WorkspaceusecaseImpl) requireAdmin(workspaceID, userID uint) (*model.Member, error) {
+	actor, err := uc.Resolve(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.AtLeast(actor.Role, model.RoleAdmin) {
+		return nil, ErrForbidden
+	}
+	return actor, nil
+}
+
+// manage returns the acting user's membership and the member they are about to
+// change, whom they must outrank
+func (uc *WorkspaceusecaseImpl) manage(workspaceID, userID, memberID uint) (*model.Member, *model.Member, error) {
+	actor, err := uc.requireAdmin(workspaceID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	member, err := uc.repo.FindMember(workspaceID, memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if member.Role == model.RoleOwner {
+		return nil, nil, ErrOwnerProtected
+	}
+	if !canManage(actor.Role, member.Role) {
+		return nil, nil, ErrForbidden
+	}
+	return actor, member, nil
+}
+
+// canManage reports whether an actor may hand out or take away role: admins
+// manage the roles below their own, the owner also manages admins
+func canManage(actor, role string) bool {
+	return model.AtLeast(actor, model.RoleAdmin) && model.RoleRanks[actor] > model.RoleRanks[role]
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
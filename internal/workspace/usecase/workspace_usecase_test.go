@@ -0,0 +1,304 @@
+package usecase_test
+
+import (
+	userModel "mymodule/internal/user/model"
+	"mymodule/internal/workspace/model"
+	"mymodule/internal/workspace/usecase"
+	"mymodule/pkg/auth"
+	"mymodule/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) Create(workspace *model.Workspace) error {
+	args := m.Called(workspace)
+	if workspace.ID == 0 {
+		workspace.ID = 99
+	}
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) FindPersonal(userID uint) (*model.Workspace, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*model.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindMemberships(userID uint) (*[]model.Member, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*[]model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindMember(workspaceID, userID uint) (*model.Member, error) {
+	args := m.Called(workspaceID, userID)
+	return args.Get(0).(*model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindMembers(workspaceID uint) (*[]model.Member, error) {
+	args := m.Called(workspaceID)
+	return args.Get(0).(*[]model.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateMember(member *model.Member) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) DeleteMember(workspaceID, userID uint) error {
+	args := m.Called(workspaceID, userID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) FindUser(userID uint) (*userModel.User, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*userModel.User), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) CreateInvitation(invitation *model.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) FindPendingInvitations(workspaceID uint, now time.Time) (*[]model.Invitation, error) {
+	args := m.Called(workspaceID, now)
+	return args.Get(0).(*[]model.Invitation), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindInvitation(invitationID, workspaceID uint) (*model.Invitation, error) {
+	args := m.Called(invitationID, workspaceID)
+	return args.Get(0).(*model.Invitation), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindInvitationByToken(tokenHash string) (*model.Invitation, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*model.Invitation), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) DeleteInvitation(invitationID uint) error {
+	args := m.Called(invitationID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) AcceptInvitation(invitation *model.Invitation, member *model.Member) error {
+	args := m.Called(invitation, member)
+	return args.Error(0)
+}
+
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) GenerateToken(userID uint, role string) (string, error) {
+	args := m.Called(userID, role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) GenerateWorkspaceToken(userID uint, role string, workspaceID uint) (string, error) {
+	args := m.Called(userID, role, workspaceID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) VerifyToken(tokenStr string) (*jwt.Token, error) {
+	return nil, nil
+}
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+const teamID = uint(5)
+
+func member(userID uint, role string) *model.Member {
+	return &model.Member{
+		WorkspaceID: teamID,
+		UserID:      userID,
+		Role:        role,
+		Workspace:   &model.Workspace{ID: teamID, Name: "Team", OwnerID: 1},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("CreatesPersonalWorkspace", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindPersonal", uint(1)).Return((*model.Workspace)(nil), nil)
+		mockRepo.On("Create", mock.MatchedBy(func(w *model.Workspace) bool {
+			return w.Personal && w.OwnerID == 1 && w.Name == model.PersonalName
+		})).Return(nil)
+
+		m, err := workspaceUC.Resolve(0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(99), m.WorkspaceID)
+		assert.Equal(t, model.RoleOwner, m.Role)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotMember", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(2)).Return((*model.Member)(nil), gorm.ErrRecordNotFound)
+
+		_, err := workspaceUC.Resolve(teamID, 2)
+		assert.ErrorIs(t, err, usecase.ErrNotMember)
+	})
+
+	t.Run("SwitchIssuesWorkspaceToken", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		token := new(MockTokenService)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, token)
+
+		mockRepo.On("FindMember", teamID, uint(2)).Return(member(2, model.RoleViewer), nil)
+		mockRepo.On("FindUser", uint(2)).Return(&userModel.User{ID: 2, Role: auth.RoleAdmin}, nil)
+		token.On("GenerateWorkspaceToken", uint(2), auth.RoleAdmin, teamID).Return("signed", nil)
+
+		got, err := workspaceUC.Switch(teamID, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "signed", got)
+	})
+}
+
+func TestMembers(t *testing.T) {
+	t.Run("AdminChangesMember", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(2)).Return(member(2, model.RoleAdmin), nil)
+		mockRepo.On("FindMember", teamID, uint(3)).Return(member(3, model.RoleMember), nil)
+		mockRepo.On("UpdateMember", mock.MatchedBy(func(m *model.Member) bool {
+			return m.UserID == 3 && m.Role == model.RoleViewer
+		})).Return(nil)
+
+		err := workspaceUC.UpdateMemberRole(teamID, 2, 3, model.RoleViewer)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminCannotPromoteToAdmin", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(2)).Return(member(2, model.RoleAdmin), nil)
+		mockRepo.On("FindMember", teamID, uint(3)).Return(member(3, model.RoleMember), nil)
+
+		err := workspaceUC.UpdateMemberRole(teamID, 2, 3, model.RoleAdmin)
+		assert.ErrorIs(t, err, usecase.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "UpdateMember", mock.Anything)
+	})
+
+	t.Run("AdminCannotRemoveAdmin", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(2)).Return(member(2, model.RoleAdmin), nil)
+		mockRepo.On("FindMember", teamID, uint(3)).Return(member(3, model.RoleAdmin), nil)
+
+		err := workspaceUC.RemoveMember(teamID, 2, 3)
+		assert.ErrorIs(t, err, usecase.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "DeleteMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OwnerIsProtected", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(1)).Return(member(1, model.RoleOwner), nil)
+		mockRepo.On("FindMember", teamID, uint(2)).Return(member(2, model.RoleAdmin), nil)
+
+		assert.ErrorIs(t, workspaceUC.RemoveMember(teamID, 2, 1), usecase.ErrOwnerProtected)
+		assert.ErrorIs(t, workspaceUC.RemoveMember(teamID, 1, 1), usecase.ErrOwnerProtected)
+	})
+
+	t.Run("ViewerLeaves", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(4)).Return(member(4, model.RoleViewer), nil)
+		mockRepo.On("DeleteMember", teamID, uint(4)).Return(nil)
+
+		assert.NoError(t, workspaceUC.RemoveMember(teamID, 4, 4))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestInvitations(t *testing.T) {
+	t.Run("InviteAndAccept", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		var stored *model.Invitation
+		mockRepo.On("FindMember", teamID, uint(1)).Return(member(1, model.RoleOwner), nil)
+		mockRepo.On("CreateInvitation", mock.AnythingOfType("*model.Invitation")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*model.Invitation) }).
+			Return(nil)
+
+		invitation, token, err := workspaceUC.Invite(teamID, 1, " Jane@Example.com", model.RoleMember)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, "jane@example.com", invitation.Email)
+		assert.NotEqual(t, token, stored.TokenHash, "only the token's hash is stored")
+
+		mockRepo.On("FindInvitationByToken", stored.TokenHash).Return(stored, nil)
+		mockRepo.On("FindUser", uint(2)).Return(&userModel.User{ID: 2, Email: "jane@example.com"}, nil)
+		mockRepo.On("FindMember", teamID, uint(2)).Return((*model.Member)(nil), gorm.ErrRecordNotFound)
+		mockRepo.On("AcceptInvitation", stored, mock.MatchedBy(func(m *model.Member) bool {
+			return m.UserID == 2 && m.Role == model.RoleMember
+		})).Return(nil)
+
+		joined, err := workspaceUC.AcceptInvitation(token, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, teamID, joined.WorkspaceID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MemberCannotInvite", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		mockRepo.On("FindMember", teamID, uint(3)).Return(member(3, model.RoleMember), nil)
+
+		_, _, err := workspaceUC.Invite(teamID, 3, "jane@example.com", model.RoleViewer)
+		assert.ErrorIs(t, err, usecase.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything)
+	})
+
+	t.Run("PersonalWorkspaceNotShared", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		owner := member(1, model.RoleOwner)
+		owner.Workspace.Personal = true
+		mockRepo.On("FindMember", teamID, uint(1)).Return(owner, nil)
+
+		_, _, err := workspaceUC.Invite(teamID, 1, "jane@example.com", model.RoleMember)
+		assert.ErrorIs(t, err, usecase.ErrPersonalWorkspace)
+	})
+
+	t.Run("ExpiredOrOtherEmail", func(t *testing.T) {
+		mockRepo := new(MockWorkspaceRepository)
+		workspaceUC := usecase.NewWorkspaceUsecase(mockRepo, new(MockTokenService))
+
+		expired := &model.Invitation{ID: 1, WorkspaceID: teamID, Email: "jane@example.com", Role: model.RoleMember, ExpiresAt: time.Now().Add(-time.Minute)}
+		current := &model.Invitation{ID: 2, WorkspaceID: teamID, Email: "jane@example.com", Role: model.RoleMember, ExpiresAt: time.Now().Add(time.Hour)}
+		mockRepo.On("FindInvitationByToken", mock.Anything).Return(expired, nil).Once()
+		mockRepo.On("FindInvitationByToken", mock.Anything).Return(current, nil).Once()
+		mockRepo.On("FindUser", uint(3)).Return(&userModel.User{ID: 3, Email: "bob@example.com"}, nil)
+
+		_, err := workspaceUC.AcceptInvitation("token", 3)
+		assert.ErrorIs(t, err, usecase.ErrInvitationExpired)
+		_, err = workspaceUC.AcceptInvitation("token", 3)
+		assert.ErrorIs(t, err, usecase.ErrInvitationEmail)
+		mockRepo.AssertNotCalled(t, "AcceptInvitation", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,22 @@
+CREATE TABLE task_series (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rrule TEXT NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    last_due_at TIMESTAMP NOT NULL,
+    generated INTEGER NOT NULL DEFAULT 1,
+    title TEXT NOT NULL,
+    description TEXT,
+    priority VARCHAR(10) NOT NULL DEFAULT 'none',
+    project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_series_user_id ON task_series(user_id);
+
+ALTER TABLE tasks ADD COLUMN series_id INTEGER REFERENCES task_series(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_tasks_series_id ON tasks(series_id);
@@ -0,0 +1,5 @@
+DROP TABLE comment_revisions;
+DROP TABLE comment_mentions;
+DROP TABLE comments;
+DROP INDEX idx_users_handle;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,2 @@
+DROP INDEX idx_tasks_assignee_id;
+ALTER TABLE tasks DROP COLUMN assignee_id;
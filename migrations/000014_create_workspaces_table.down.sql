@@ -0,0 +1,6 @@
+DROP INDEX idx_tasks_workspace_id;
+ALTER TABLE tasks DROP COLUMN workspace_id;
+
+DROP TABLE workspace_invitations;
+DROP TABLE workspace_members;
+DROP TABLE workspaces;
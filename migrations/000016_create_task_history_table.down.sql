@@ -0,0 +1,2 @@
+DROP TABLE task_field_changes;
+DROP TABLE task_history;
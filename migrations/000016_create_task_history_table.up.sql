@@ -0,0 +1,23 @@
+-- History rows are never updated. They stay with soft deleted tasks and only go
+-- when the task row itself is removed. actor_id has no foreign key so entries
+-- outlive the user who made them.
+CREATE TABLE task_history (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_history_task_id ON task_history(task_id);
+CREATE INDEX idx_task_history_actor_id ON task_history(actor_id);
+
+CREATE TABLE task_field_changes (
+    id SERIAL PRIMARY KEY,
+    history_id INTEGER NOT NULL REFERENCES task_history(id) ON DELETE CASCADE,
+    field VARCHAR(30) NOT NULL,
+    old_value TEXT,
+    new_value TEXT
+);
+
+CREATE INDEX idx_task_field_changes_history_id ON task_field_changes(history_id);
@@ -0,0 +1,4 @@
+DELETE FROM task_history WHERE action = 'restored';
+ALTER TABLE task_history DROP CONSTRAINT task_history_action_check;
+ALTER TABLE task_history ADD CONSTRAINT task_history_action_check
+    CHECK (action IN ('created', 'updated', 'deleted'));
@@ -0,0 +1,4 @@
+-- Restoring a task from the trash is recorded in its history
+ALTER TABLE task_history DROP CONSTRAINT task_history_action_check;
+ALTER TABLE task_history ADD CONSTRAINT task_history_action_check
+    CHECK (action IN ('created', 'updated', 'deleted', 'restored'));
@@ -0,0 +1,15 @@
+-- When each task last entered each of its statuses. Existing tasks get their
+-- creation time for pending and their last update for the status they are in,
+-- the best that is known about them.
+CREATE TABLE task_status_entries (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    entered_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (task_id, status)
+);
+
+INSERT INTO task_status_entries (task_id, status, entered_at)
+SELECT id, 'pending', COALESCE(created_at, CURRENT_TIMESTAMP) FROM tasks WHERE COALESCE(NULLIF(status, ''), 'pending') <> 'pending';
+
+INSERT INTO task_status_entries (task_id, status, entered_at)
+SELECT id, COALESCE(NULLIF(status, ''), 'pending'), COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) FROM tasks;
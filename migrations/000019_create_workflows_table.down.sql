@@ -0,0 +1,11 @@
+-- Tasks in a custom status go back to the built-in status of their category
+UPDATE tasks SET status = CASE status_category
+        WHEN 'done' THEN 'completed'
+        WHEN 'in_progress' THEN 'in_progress'
+        ELSE 'pending'
+    END
+WHERE status NOT IN ('pending', 'in_progress', 'completed', 'overdue');
+
+ALTER TABLE tasks DROP COLUMN status_category;
+DROP TABLE workflow_statuses;
+DROP TABLE workflows;
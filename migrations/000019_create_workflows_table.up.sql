@@ -0,0 +1,26 @@
+-- Projects may replace the built-in statuses with a workflow of their own. Every
+-- task keeps the category of its status next to it, so overdue, completion and
+-- reminders work whatever the workflow.
+CREATE TABLE workflows (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL UNIQUE REFERENCES projects(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE workflow_statuses (
+    id SERIAL PRIMARY KEY,
+    workflow_id INTEGER NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    key VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('todo', 'in_progress', 'done')),
+    position INTEGER NOT NULL,
+    UNIQUE (workflow_id, key)
+);
+
+ALTER TABLE tasks ADD COLUMN status_category VARCHAR(20) NOT NULL DEFAULT 'todo'
+    CHECK (status_category IN ('todo', 'in_progress', 'done'));
+
+UPDATE tasks SET status = 'pending' WHERE status IS NULL OR status = '';
+UPDATE tasks SET status_category = 'in_progress' WHERE status = 'in_progress';
+UPDATE tasks SET status_category = 'done' WHERE status = 'completed';
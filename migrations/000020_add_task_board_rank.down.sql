@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_board;
+ALTER TABLE tasks DROP COLUMN board_rank;
@@ -0,0 +1,14 @@
+-- Tasks of a project are ordered by hand within each board column. Ranks are
+-- compared byte by byte, whatever the collation of the database.
+ALTER TABLE tasks ADD COLUMN board_rank VARCHAR(64) COLLATE "C";
+
+CREATE INDEX idx_tasks_board ON tasks (project_id, status, board_rank);
+
+-- Existing tasks keep the order they were created in
+UPDATE tasks SET board_rank = ranked.board_rank
+FROM (
+    SELECT id, LPAD(ROW_NUMBER() OVER (PARTITION BY project_id, status ORDER BY created_at, id)::TEXT, 6, '0') || 'i' AS board_rank
+    FROM tasks
+    WHERE project_id IS NOT NULL
+) AS ranked
+WHERE tasks.id = ranked.id;
@@ -0,0 +1 @@
+DROP TABLE wip_limits;
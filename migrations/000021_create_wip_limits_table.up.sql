@@ -0,0 +1,16 @@
+-- Work-in-progress limits cap how many tasks may be in one status at a time, set
+-- either by a workspace or by a project
+CREATE TABLE wip_limits (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('user', 'project')),
+    max INTEGER NOT NULL CHECK (max > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((workspace_id IS NULL) <> (project_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_wip_limits_workspace_status ON wip_limits (workspace_id, status) WHERE workspace_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_wip_limits_project_status ON wip_limits (project_id, status) WHERE project_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks DROP CONSTRAINT chk_tasks_estimate;
+ALTER TABLE tasks DROP COLUMN estimate_points;
+ALTER TABLE tasks DROP COLUMN estimate_minutes;
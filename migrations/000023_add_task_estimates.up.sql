@@ -0,0 +1,4 @@
+-- A task is estimated in minutes or in story points, never both
+ALTER TABLE tasks ADD COLUMN estimate_minutes INTEGER;
+ALTER TABLE tasks ADD COLUMN estimate_points NUMERIC(6,1);
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_estimate CHECK (estimate_minutes IS NULL OR estimate_points IS NULL);
@@ -6,8 +6,15 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// Roles a user can have across the whole API, carried in the token's role claim
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type TokenService interface {
-	GenerateToken(userID uint) (string, error)
+	GenerateToken(userID uint, role string) (string, error)
+	GenerateWorkspaceToken(userID uint, role string, workspaceID uint) (string, error)
 	VerifyToken(tokenStr string) (*jwt.Token, error)
 }
 
@@ -23,11 +30,21 @@ func NewJwtManager(secretKet string, duration time.Duration) TokenService {
 	}
 }
 
-func (j *JwtManager) GenerateToken(userID uint) (string, error) {
+func (j *JwtManager) GenerateToken(userID uint, role string) (string, error) {
+	return j.GenerateWorkspaceToken(userID, role, 0)
+}
+
+// GenerateWorkspaceToken issues a token that also carries the active workspace,
+// 0 leaves it out so the user's personal workspace applies
+func (j *JwtManager) GenerateWorkspaceToken(userID uint, role string, workspaceID uint) (string, error) {
 	claims := jwt.MapClaims{
 		"userID": userID,
+		"role":   role,
 		"exp":    time.Now().Add(j.tokenDuration).Unix(),
 	}
+	if workspaceID != 0 {
+		claims["workspaceID"] = workspaceID
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secretKey))
 }
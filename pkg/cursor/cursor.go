@@ -0,0 +1,66 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Signer turns pagination state into opaque tokens that clients cannot forge
+type Signer interface {
+	Encode(v interface{}) (string, error)
+	Decode(token string, v interface{}) error
+}
+
+type HmacSigner struct {
+	secretKey []byte
+}
+
+func NewHmacSigner(secretKey string) Signer {
+	return &HmacSigner{secretKey: []byte(secretKey)}
+}
+
+// Encode marshals v to JSON and appends an HMAC-SHA256 of the payload
+func (s *HmacSigner) Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(s.sign(payload)), nil
+}
+
+// Decode verifies the signature of token and unmarshals its payload into v
+func (s *HmacSigner) Decode(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrInvalidCursor
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	mac, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if !hmac.Equal(mac, s.sign(payload)) {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (s *HmacSigner) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secretKey)
+	h.Write(payload)
+	return h.Sum(nil)
+}
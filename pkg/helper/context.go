@@ -20,4 +20,19 @@ func GetUserIDFromContext(c *fiber.Ctx) (uint, error) {
 	}
 
 	return uint(userID64), nil
-}
\ No newline at end of file
+}
+
+func GetWorkspaceIDFromContext(c *fiber.Ctx) (uint, error) {
+	rawWorkspaceID := c.Locals("workspaceID")
+	if rawWorkspaceID == nil {
+		return 0, fmt.Errorf("workspaceID not found in context")
+	}
+
+	workspaceIDStr := fmt.Sprintf("%v", rawWorkspaceID)
+	workspaceID64, err := strconv.ParseUint(workspaceIDStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid workspaceID format: %w", err)
+	}
+
+	return uint(workspaceID64), nil
+}
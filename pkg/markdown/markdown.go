@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// policy allows the usual user generated formatting and strips anything
+	// scriptable, links get rel="nofollow noopener"
+	policy = bluemonday.UGCPolicy().AddTargetBlankToFullyQualifiedLinks(true)
+)
+
+// ToSafeHTML renders Markdown to HTML that is safe to embed in a page.
+// Raw HTML in the source is escaped by the renderer and whatever gets through
+// is sanitised again, so the output never carries scripts or event handlers.
+func ToSafeHTML(src string) string {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(src), &buf); err != nil {
+		return policy.Sanitize(src)
+	}
+	return policy.Sanitize(buf.String())
+}
@@ -3,11 +3,16 @@ package middleware
 import (
 	"mymodule/pkg/auth"
 	"mymodule/pkg/logger"
+	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/gofiber/fiber/v2"
 	
 )
+
+// WorkspaceHeader selects the active workspace for one request, overriding the token
+const WorkspaceHeader = "X-Workspace-ID"
+
 func Middleware(jwtManager auth.TokenService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		tokenStr := c.Cookies("jwt")
@@ -46,8 +51,62 @@ func Middleware(jwtManager auth.TokenService) fiber.Handler {
 		logger.Log.Info("Authorized user ID from token: ", userID)
 
 		c.Locals("userID", userID)
+
+		// Tokens issued before roles existed belong to regular users
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = auth.RoleUser
+		}
+		c.Locals("role", role)
+
+		// 0 means none was asked for, the user's personal workspace then applies
+		var workspaceID uint
+		if raw, ok := claims["workspaceID"].(float64); ok {
+			workspaceID = uint(raw)
+		}
+		if header := c.Get(WorkspaceHeader); header != "" {
+			id, err := strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid workspace ID",
+				})
+			}
+			workspaceID = uint(id)
+		}
+		c.Locals("workspaceID", workspaceID)
 		return c.Next()
 	}
 }
 
+// RoleLookup finds the role a user holds now, which may differ from the one in an
+// older token
+type RoleLookup func(userID uint) (string, error)
 
+// RequireRole only lets requests through whose token carries role and whose user
+// still holds it as lookup finds it, so a role taken away takes effect before the
+// token expires. It must run after Middleware.
+func RequireRole(role string, lookup RoleLookup) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if got, _ := c.Locals("role").(string); got != role {
+			logger.Log.Warn("Forbidden: route requires role ", role)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "forbidden",
+			})
+		}
+		userID, _ := c.Locals("userID").(uint)
+		current, err := lookup(userID)
+		if err != nil {
+			logger.Log.Error("Failed to look up the role of user ", userID, ": ", err)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "forbidden",
+			})
+		}
+		if current != role {
+			logger.Log.Warn("Forbidden: user ", userID, " no longer has role ", role)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "forbidden",
+			})
+		}
+		return c.Next()
+	}
+}
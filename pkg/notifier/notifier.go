@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"mymodule/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Notification tells a user about one of their tasks
+type Notification struct {
+	UserID  uint
+	TaskID  uint
+	Title   string
+	DueDate *time.Time
+	SendAt  time.Time
+}
+
+// Notifier delivers notifications, implementations must be safe for concurrent use
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the application log
+type LogNotifier struct{}
+
+func NewLogNotifier() Notifier {
+	return &LogNotifier{}
+}
+
+func (l *LogNotifier) Notify(ctx context.Context, n Notification) error {
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":   n.UserID,
+		"taskID":   n.TaskID,
+		"title":    n.Title,
+		"due_date": n.DueDate,
+		"send_at":  n.SendAt,
+	}).Info("Reminder")
+	return nil
+}
+
+// MemoryNotifier keeps notifications in memory so tests can inspect them
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	Err  error // returned by Notify instead of recording, when set
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (m *MemoryNotifier) Notify(ctx context.Context, n Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, n)
+	return nil
+}
+
+// Sent returns a copy of the notifications delivered so far
+func (m *MemoryNotifier) Sent() []Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Notification(nil), m.sent...)
+}
@@ -0,0 +1,81 @@
+package period
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("unknown time zone")
+	ErrInvalidPeriod   = errors.New("the period has to end on or after its first day and span at most 366 days")
+)
+
+// DayLayout is how days are written in periods
+const DayLayout = "2006-01-02"
+
+// MaxDays bounds the days a period may span
+const MaxDays = 366
+
+// Period is a run of whole days in a time zone, from the midnight starting the
+// first day up to the one ending the last
+type Period struct {
+	From time.Time
+	To   time.Time // exclusive
+	Loc  *time.Location
+}
+
+// Days reads the days from and to, both included, as they fall in the time zone
+// tz, UTC when empty. Without a last day the period ends today, without a first
+// day it spans defaultDays.
+func Days(from, to, tz string, now time.Time, defaultDays int) (Period, error) {
+	loc := time.UTC
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return Period{}, ErrInvalidTimezone
+		}
+	}
+
+	y, m, d := now.In(loc).Date()
+	last := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if to != "" {
+		day, err := time.ParseInLocation(DayLayout, to, loc)
+		if err != nil {
+			return Period{}, ErrInvalidPeriod
+		}
+		last = day
+	}
+	first := last.AddDate(0, 0, 1-defaultDays)
+	if from != "" {
+		day, err := time.ParseInLocation(DayLayout, from, loc)
+		if err != nil {
+			return Period{}, ErrInvalidPeriod
+		}
+		first = day
+	}
+	if last.Before(first) || first.AddDate(0, 0, MaxDays).Before(last.AddDate(0, 0, 1)) {
+		return Period{}, ErrInvalidPeriod
+	}
+	// Midnight comes by the calendar, a day is 23 or 25 hours long when the clocks change
+	return Period{From: first, To: last.AddDate(0, 0, 1), Loc: loc}, nil
+}
+
+// LastDay is the last day of the period, written as a day
+func (p Period) LastDay() string {
+	return p.To.In(p.Loc).AddDate(0, 0, -1).Format(DayLayout)
+}
+
+// Split hands each day of p.Loc that [start, end) touches to add, with the time
+// falling on it
+func (p Period) Split(start, end time.Time, add func(day string, d time.Duration)) {
+	for start.Before(end) {
+		local := start.In(p.Loc)
+		y, m, d := local.Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, p.Loc)
+		if next.After(end) {
+			next = end
+		}
+		add(local.Format(DayLayout), next.Sub(start))
+		start = next
+	}
+}
@@ -0,0 +1,104 @@
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidRank = errors.New("invalid rank")
+
+// Ranks are base-36 fractions written as their digits after the point, so plain
+// string comparison orders them. A rank never ends in "0": there is always room
+// for another rank between two of them.
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// Between returns a rank that sorts strictly between a and b. An empty a stands
+// for the start of the list, an empty b for its end.
+func Between(a, b string) (string, error) {
+	if !valid(a) || !valid(b) || b != "" && a >= b {
+		return "", ErrInvalidRank
+	}
+	return midpoint(a, b), nil
+}
+
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the digits both share, reading missing digits of a as zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(digits, a[0])
+	}
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+	// Neighbouring digits: a longer b can be cut short, otherwise go one digit deeper
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[lo]) + midpoint(rest, "")
+}
+
+// Spread returns n ranks in ascending order, evenly spaced and as short as the
+// count allows, for rewriting a list whose ranks have grown long
+func Spread(n int) []string {
+	width, space := 1, base
+	for space < 2*(n+1) {
+		width++
+		space *= base
+	}
+	step := space / (n + 1)
+
+	ranks := make([]string, n)
+	buf := make([]byte, width)
+	for i := range ranks {
+		v := (i + 1) * step
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%base]
+			v /= base
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return '0'
+}
+
+func valid(s string) bool {
+	if strings.HasSuffix(s, "0") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(digits, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule is an RFC 5545 RRULE anchored at the due date of a series' first occurrence.
+// COUNT is applied to the occurrences generated so far rather than to the rule's
+// own instances, so that a first due date off the rule's pattern still counts.
+type Rule struct {
+	rule  *rrule.RRule
+	count int
+	text  string
+}
+
+// Parse reads an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10",
+// with or without the "RRULE:" prefix. Only daily and coarser frequencies make
+// sense for tasks, finer ones are rejected.
+func Parse(text string, start time.Time) (*Rule, error) {
+	text = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(text)), "RRULE:")
+	if text == "" || strings.Contains(text, "\n") {
+		return nil, ErrInvalidRule
+	}
+
+	opt, err := rrule.StrToROption(text)
+	if err != nil {
+		return nil, ErrInvalidRule
+	}
+	if opt.Freq > rrule.DAILY || opt.Interval < 0 || opt.Count < 0 {
+		return nil, ErrInvalidRule
+	}
+	normalized := opt.RRuleString()
+
+	count := opt.Count
+	opt.Count = 0
+	opt.Dtstart = start
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, ErrInvalidRule
+	}
+	return &Rule{rule: r, count: count, text: normalized}, nil
+}
+
+// String is the rule in canonical RRULE form, without DTSTART
+func (r *Rule) String() string {
+	return r.text
+}
+
+// Next is the first occurrence strictly after the given one, false once the
+// series has run out by COUNT (against generated) or UNTIL
+func (r *Rule) Next(after time.Time, generated int) (time.Time, bool) {
+	if r.count > 0 && generated >= r.count {
+		return time.Time{}, false
+	}
+	next := r.rule.After(after, false)
+	if next.IsZero() {
+		return time.Time{}, false
+	}
+	return next, true
+}
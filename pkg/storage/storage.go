@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Object describes a stored blob. Key is the hex SHA-256 of the content, so
+// storing the same bytes twice yields the same key and keeps a single copy.
+type Object struct {
+	Key  string
+	Size int64
+}
+
+// Storage is a content-addressed blob store
+type Storage interface {
+	// Put stores everything read from r. An error from r aborts the write and
+	// leaves nothing behind. Once the content is hashed and before it is kept,
+	// claim, when not nil, gets the object so the caller can mark the blob in
+	// use; Put then makes sure the blob is there, even if it was deleted in the
+	// meantime. An error from claim aborts the write.
+	Put(ctx context.Context, r io.Reader, claim func(Object) error) (Object, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob, deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}